@@ -0,0 +1,346 @@
+// Package broker contains a small in-process message broker fake supporting
+// topics with partitions, keyed messages, consumer groups with committed
+// offsets, and redelivery. Publish expectations are set up as mock setup
+// functions composable via `mock.Chain` and `mock.Parallel`, while consume-side
+// failures and duplicate deliveries can be injected. It is part of the public
+// interface, however, we are still experimenting to optimize the interface and
+// the user experience.
+package broker
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	gosync "sync"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/tkrop/go-testing/internal/reflect"
+	"github.com/tkrop/go-testing/mock"
+)
+
+// Publisher is the interface to publish messages to the broker.
+type Publisher interface {
+	// Publish publishes a message with given key and value to the topic.
+	Publish(topic, key string, value []byte) error
+}
+
+// Message is a message stored in a partition of a topic.
+type Message struct {
+	// Topic the name of the topic.
+	Topic string
+	// Partition the partition of the topic.
+	Partition int
+	// Offset the offset of the message in the partition.
+	Offset int64
+	// Key the key of the message used for partitioning.
+	Key string
+	// Value the value of the message.
+	Value []byte
+	// Attempt the delivery attempt of the message to the consumer group,
+	// starting with 1 for the first delivery.
+	Attempt int
+}
+
+// clone returns a copy of the message not sharing the value.
+func (m *Message) clone() *Message {
+	result := *m
+	result.Value = append([]byte{}, m.Value...)
+	return &result
+}
+
+// Fault is a fault injected into the consumption of messages.
+type Fault struct {
+	// Group the consumer group of the failing polls. An empty group matches
+	// all consumer groups.
+	Group string
+	// Topic the topic of the failing polls. An empty topic matches all topics.
+	Topic string
+	// Err the error returned by the failing polls.
+	Err error
+	// Duplicate whether the message delivered by the poll is delivered again
+	// on the next poll instead of failing the poll.
+	Duplicate bool
+	// Times the number of failing polls. Zero fails all matching polls.
+	Times int
+}
+
+// matches returns whether the given consumer group and topic match the fault.
+func (f *Fault) matches(group, topic string) bool {
+	return (f.Group == "" || f.Group == group) &&
+		(f.Topic == "" || f.Topic == topic)
+}
+
+// Broker is an in-process message broker fake validating published messages
+// against the publish expectations.
+type Broker struct {
+	// ctrl the mock controller validating the published messages.
+	ctrl *gomock.Controller
+	// recorder the recorder for publish expectations.
+	recorder *Recorder
+	// mu the mutex to protect the broker state.
+	mu gosync.Mutex
+	// topics the topics by name.
+	topics map[string]*topic
+	// groups the consumer group states by group and topic name.
+	groups map[string]*group
+	// faults the injected consume-side faults.
+	faults []*Fault
+	// notify the channel closed on new messages to wake up waiting polls.
+	notify chan struct{}
+}
+
+// Recorder is the recorder for publish expectations of the broker.
+type Recorder struct {
+	// broker the broker validating the expectations.
+	broker *Broker
+}
+
+// topic is a topic with partitions of messages.
+type topic struct {
+	// partitions the messages of the partitions.
+	partitions [][]*Message
+	// next the next partition for messages without key.
+	next int
+}
+
+// group is the state of a consumer group consuming a topic.
+type group struct {
+	// committed the committed offsets by partition.
+	committed []int64
+	// position the offsets of the next delivered messages by partition.
+	position []int64
+	// attempts the delivery attempts by partition and offset.
+	attempts map[[2]int64]int
+	// next the next partition to poll.
+	next int
+}
+
+// NewBroker creates a new message broker fake for the given mock controller,
+// e.g. to be requested via `mock.Get(mocks, broker.NewBroker)`.
+func NewBroker(ctrl *gomock.Controller) *Broker {
+	b := &Broker{
+		ctrl:   ctrl,
+		topics: map[string]*topic{},
+		groups: map[string]*group{},
+		notify: make(chan struct{}),
+	}
+	b.recorder = &Recorder{broker: b}
+	return b
+}
+
+// EXPECT implements the usual `gomock.EXPECT` call to request the recorder.
+func (b *Broker) EXPECT() *Recorder {
+	return b.recorder
+}
+
+// CreateTopic creates the topic with given name and number of partitions, if
+// it does not exist. Topics created implicitly, e.g. by adding messages or by
+// creating consumers, have a single partition. If the topic already exists
+// with a different number of partitions, the test fails, since the topic must
+// be created before it is used.
+func (b *Broker) CreateTopic(name string, partitions int) *Broker {
+	b.ctrl.T.Helper()
+	if partitions < 1 {
+		partitions = 1
+	}
+
+	b.mu.Lock()
+	actual := len(b.topic(name, partitions).partitions)
+	b.mu.Unlock()
+
+	if actual != partitions {
+		b.ctrl.T.Fatalf("%v", ErrPartitions(name, actual, partitions))
+	}
+	return b
+}
+
+// Publish receives a published message, validates it against the publish
+// expectations, and stores it in the topic, unless the expectation returns an
+// error.
+func (b *Broker) Publish(topic, key string, value []byte) error {
+	b.ctrl.T.Helper()
+	ret := b.ctrl.Call(b, "Publish", topic, key, value)
+	if err, _ := ret[0].(error); err != nil {
+		return err
+	}
+	b.Add(topic, key, value)
+	return nil
+}
+
+// Publish indicates an expected call to `Publish`.
+func (r *Recorder) Publish(topic, key, value any) *gomock.Call {
+	r.broker.ctrl.T.Helper()
+	return r.broker.ctrl.RecordCallWithMethodType(r.broker, "Publish",
+		reflect.TypeOf((*Broker)(nil).Publish), topic, key, value)
+}
+
+// Publish creates a mock setup function expecting a message with given key and
+// value to be published to the topic. Besides values, `gomock` matchers are
+// accepted.
+func Publish(topic string, key, value any) mock.SetupFunc {
+	return func(mocks *mock.Mocks) any {
+		return mock.Get(mocks, NewBroker).EXPECT().
+			Publish(topic, key, value).
+			DoAndReturn(mocks.Return(Publisher.Publish, nil))
+	}
+}
+
+// PublishError creates a mock setup function expecting a message with given
+// key and value to be published to the topic, that fails with given error and
+// is not stored.
+func PublishError(topic string, key, value any, err error) mock.SetupFunc {
+	return func(mocks *mock.Mocks) any {
+		return mock.Get(mocks, NewBroker).EXPECT().
+			Publish(topic, key, value).
+			DoAndReturn(mocks.Return(Publisher.Publish, err))
+	}
+}
+
+// Add adds a message with given key and value to the topic without validation
+// against the publish expectations, e.g. to provide the input messages of a
+// consumer under test.
+func (b *Broker) Add(topic, key string, value []byte) *Message {
+	b.mu.Lock()
+	t := b.topic(topic, 1)
+	partition := t.next
+	if key != "" {
+		hash := fnv.New32a()
+		_, _ = hash.Write([]byte(key))
+		partition = int(hash.Sum32() % uint32(len(t.partitions)))
+	} else {
+		t.next = (t.next + 1) % len(t.partitions)
+	}
+
+	msg := &Message{
+		Topic: topic, Partition: partition, Key: key,
+		Offset: int64(len(t.partitions[partition])),
+		Value:  append([]byte{}, value...),
+	}
+	t.partitions[partition] = append(t.partitions[partition], msg)
+	close(b.notify)
+	b.notify = make(chan struct{})
+	b.mu.Unlock()
+
+	return msg.clone()
+}
+
+// Fail injects the given fault into the consumption of messages.
+func (b *Broker) Fail(fault Fault) *Broker {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.faults = append(b.faults, &fault)
+	return b
+}
+
+// Messages returns the messages of the topic with given name ordered by
+// partition and offset.
+func (b *Broker) Messages(topic string) []*Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	msgs := []*Message{}
+	if t, ok := b.topics[topic]; ok {
+		for _, partition := range t.partitions {
+			for _, msg := range partition {
+				msgs = append(msgs, msg.clone())
+			}
+		}
+	}
+	return msgs
+}
+
+// Committed returns the committed offsets by partition of the consumer group
+// with given name for the topic with given name.
+func (b *Broker) Committed(group, topic string) []int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]int64{}, b.group(group, topic).committed...)
+}
+
+// AssertConsumed asserts that the consumer group with given name has committed
+// all messages of the topic with given name.
+func (b *Broker) AssertConsumed(group, topic string) bool {
+	b.ctrl.T.Helper()
+	b.mu.Lock()
+	g, t := b.group(group, topic), b.topic(topic, 1)
+	pending := []string{}
+	for partition, msgs := range t.partitions {
+		if offset := g.committed[partition]; offset < int64(len(msgs)) {
+			pending = append(pending, fmt.Sprintf("%d:%d-%d",
+				partition, offset, len(msgs)-1))
+		}
+	}
+	b.mu.Unlock()
+
+	if len(pending) != 0 {
+		sort.Strings(pending)
+		b.ctrl.T.Errorf("%v", ErrUnconsumed(group, topic, pending))
+		return false
+	}
+	return true
+}
+
+// topic returns the topic with given name creating it with given number of
+// partitions, if necessary.
+func (b *Broker) topic(name string, partitions int) *topic {
+	t, ok := b.topics[name]
+	if !ok {
+		t = &topic{partitions: make([][]*Message, partitions)}
+		b.topics[name] = t
+	}
+	return t
+}
+
+// group returns the state of the consumer group with given name for the topic
+// with given name, creating both, if necessary.
+func (b *Broker) group(name, topic string) *group {
+	t := b.topic(topic, 1)
+	g, ok := b.groups[name+"/"+topic]
+	if !ok {
+		g = &group{
+			committed: make([]int64, len(t.partitions)),
+			position:  make([]int64, len(t.partitions)),
+			attempts:  map[[2]int64]int{},
+		}
+		b.groups[name+"/"+topic] = g
+	}
+	return g
+}
+
+// fault returns the first fault matching the given consumer group and topic.
+func (b *Broker) fault(group, topic string) *Fault {
+	for _, fault := range b.faults {
+		if fault.matches(group, topic) {
+			return fault
+		}
+	}
+	return nil
+}
+
+// consume consumes the given fault, removing it, if it is exhausted.
+func (b *Broker) consume(fault *Fault) {
+	if fault.Times > 1 {
+		fault.Times--
+	} else if fault.Times == 1 {
+		for index, actual := range b.faults {
+			if actual == fault {
+				b.faults = append(b.faults[:index], b.faults[index+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// ErrUnconsumed creates an error reporting messages of a topic not committed
+// by a consumer group, given as partition with range of offsets.
+func ErrUnconsumed(group, topic string, pending []string) error {
+	return fmt.Errorf("messages not consumed [group=%s, topic=%s]: %v",
+		group, topic, pending)
+}
+
+// ErrPartitions creates an error reporting that the topic with given name
+// already exists with a different number of partitions than requested.
+func ErrPartitions(topic string, actual, partitions int) error {
+	return fmt.Errorf("topic partitions mismatch [topic=%s, actual=%d, "+
+		"partitions=%d]", topic, actual, partitions)
+}
@@ -0,0 +1,370 @@
+package broker_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tkrop/go-testing/broker"
+	"github.com/tkrop/go-testing/mock"
+	"github.com/tkrop/go-testing/test"
+)
+
+var errPublish = errors.New("publish failed")
+
+type publish struct {
+	topic, key, value string
+}
+
+type PublishParams struct {
+	setup          mock.SetupFunc
+	publish        []publish
+	expectErrors   []error
+	expectMessages []*broker.Message
+}
+
+var testPublishParams = map[string]PublishParams{
+	"single message": {
+		setup:          broker.Publish("topic", "key", []byte("a")),
+		publish:        []publish{{"topic", "key", "a"}},
+		expectErrors:   []error{nil},
+		expectMessages: []*broker.Message{msg("topic", 0, 0, "key", "a", 0)},
+	},
+	"chained messages": {
+		setup: mock.Chain(
+			broker.Publish("topic", "key", []byte("a")),
+			broker.Publish("topic", "key", []byte("b")),
+		),
+		publish: []publish{
+			{"topic", "key", "a"}, {"topic", "key", "b"},
+		},
+		expectErrors: []error{nil, nil},
+		expectMessages: []*broker.Message{
+			msg("topic", 0, 0, "key", "a", 0),
+			msg("topic", 0, 1, "key", "b", 0),
+		},
+	},
+	"parallel messages": {
+		setup: mock.Parallel(
+			broker.Publish("topic", "", []byte("a")),
+			broker.Publish("topic", "", []byte("b")),
+		),
+		publish: []publish{
+			{"topic", "", "b"}, {"topic", "", "a"},
+		},
+		expectErrors: []error{nil, nil},
+		expectMessages: []*broker.Message{
+			msg("topic", 0, 0, "", "b", 0),
+			msg("topic", 0, 1, "", "a", 0),
+		},
+	},
+	"matcher message": {
+		setup: broker.Publish("topic", gomock.Any(), gomock.Any()),
+		publish: []publish{
+			{"topic", "any", "value"},
+		},
+		expectErrors:   []error{nil},
+		expectMessages: []*broker.Message{msg("topic", 0, 0, "any", "value", 0)},
+	},
+	"failing message": {
+		setup: mock.Chain(
+			broker.PublishError("topic", "key", []byte("a"), errPublish),
+			broker.Publish("topic", "key", []byte("b")),
+		),
+		publish: []publish{
+			{"topic", "key", "a"}, {"topic", "key", "b"},
+		},
+		expectErrors: []error{errPublish, nil},
+		expectMessages: []*broker.Message{
+			msg("topic", 0, 0, "key", "b", 0),
+		},
+	},
+}
+
+// msg creates a message with given topic, partition, offset, key, value, and
+// delivery attempt.
+func msg(
+	topic string, partition int, offset int64,
+	key, value string, attempt int,
+) *broker.Message {
+	return &broker.Message{
+		Topic: topic, Partition: partition, Offset: offset,
+		Key: key, Value: []byte(value), Attempt: attempt,
+	}
+}
+
+func TestPublish(t *testing.T) {
+	test.Map(t, testPublishParams).
+		Run(func(t test.Test, param PublishParams) {
+			// Given
+			mocks := mock.NewMock(t).Expect(param.setup)
+			b := mock.Get(mocks, broker.NewBroker)
+
+			// When
+			errs := []error{}
+			for _, p := range param.publish {
+				errs = append(errs, b.Publish(p.topic, p.key, []byte(p.value)))
+			}
+			mocks.Wait()
+
+			// Then
+			assert.Equal(t, param.expectErrors, errs)
+			assert.Equal(t, param.expectMessages, b.Messages("topic"))
+		})
+}
+
+func TestPublishConcurrent(t *testing.T) {
+	// Given
+	mocks := mock.NewMock(t).Expect(mock.Parallel(
+		broker.Publish("topic", "a", []byte("a")),
+		broker.Publish("topic", "b", []byte("b")),
+		broker.Publish("topic", "c", []byte("c")),
+	))
+	b := mock.Get(mocks, broker.NewBroker)
+
+	// When
+	for _, key := range []string{"a", "b", "c"} {
+		go func(key string) {
+			assert.NoError(t, b.Publish("topic", key, []byte(key)))
+		}(key)
+	}
+	mocks.Wait()
+
+	// Then
+	assert.Len(t, b.Messages("topic"), 3)
+}
+
+type PartitionParams struct {
+	partitions     int
+	keys           []string
+	expectMessages []*broker.Message
+}
+
+var testPartitionParams = map[string]PartitionParams{
+	"single partition": {
+		partitions: 1,
+		keys:       []string{"a", "b", ""},
+		expectMessages: []*broker.Message{
+			msg("topic", 0, 0, "a", "a", 0),
+			msg("topic", 0, 1, "b", "b", 0),
+			msg("topic", 0, 2, "", "", 0),
+		},
+	},
+	"keyed partitions": {
+		partitions: 2,
+		keys:       []string{"a", "b", "a", "b"},
+		expectMessages: []*broker.Message{
+			msg("topic", 0, 0, "a", "a", 0),
+			msg("topic", 0, 1, "a", "a", 0),
+			msg("topic", 1, 0, "b", "b", 0),
+			msg("topic", 1, 1, "b", "b", 0),
+		},
+	},
+	"round robin partitions": {
+		partitions: 3,
+		keys:       []string{"", "", "", ""},
+		expectMessages: []*broker.Message{
+			msg("topic", 0, 0, "", "", 0),
+			msg("topic", 0, 1, "", "", 0),
+			msg("topic", 1, 0, "", "", 0),
+			msg("topic", 2, 0, "", "", 0),
+		},
+	},
+	"invalid partitions": {
+		partitions: 0,
+		keys:       []string{"a", ""},
+		expectMessages: []*broker.Message{
+			msg("topic", 0, 0, "a", "a", 0),
+			msg("topic", 0, 1, "", "", 0),
+		},
+	},
+}
+
+func TestPartition(t *testing.T) {
+	test.Map(t, testPartitionParams).
+		Run(func(t test.Test, param PartitionParams) {
+			// Given
+			b := broker.NewBroker(gomock.NewController(t)).
+				CreateTopic("topic", param.partitions)
+
+			// When
+			for _, key := range param.keys {
+				b.Add("topic", key, []byte(key))
+			}
+
+			// Then
+			assert.Equal(t, param.expectMessages, b.Messages("topic"))
+		})
+}
+
+func TestAdd(t *testing.T) {
+	// Given
+	b := broker.NewBroker(gomock.NewController(t))
+	value := []byte("a")
+
+	// When
+	result := b.Add("topic", "key", value)
+	result.Value[0], value[0] = 'b', 'c'
+
+	// Then
+	assert.Equal(t, []*broker.Message{msg("topic", 0, 0, "key", "a", 0)},
+		b.Messages("topic"))
+	assert.Equal(t, []*broker.Message{}, b.Messages("missing"))
+}
+
+type ConsumedParams struct {
+	partitions   int
+	keys         []string
+	commits      int
+	expectResult bool
+	expectError  error
+	expect       test.Expect
+}
+
+var testConsumedParams = map[string]ConsumedParams{
+	"empty topic": {
+		partitions:   2,
+		expectResult: true,
+		expect:       test.Success,
+	},
+	"all committed": {
+		partitions:   2,
+		keys:         []string{"a", "b", "a"},
+		commits:      3,
+		expectResult: true,
+		expect:       test.Success,
+	},
+	"none committed": {
+		partitions: 2,
+		keys:       []string{"a", "b", "a"},
+		expectError: broker.ErrUnconsumed("group", "topic",
+			[]string{"0:0-1", "1:0-0"}),
+		expect: test.Failure,
+	},
+	"partly committed": {
+		partitions: 2,
+		keys:       []string{"a", "b", "a"},
+		commits:    2,
+		expectError: broker.ErrUnconsumed("group", "topic",
+			[]string{"0:1-1"}),
+		expect: test.Failure,
+	},
+}
+
+func TestAssertConsumed(t *testing.T) {
+	test.Map(t, testConsumedParams).
+		Run(func(t test.Test, param ConsumedParams) {
+			// Given
+			if param.expectError != nil {
+				mock.NewMock(t).Expect(test.Errorf("%v", param.expectError))
+			}
+			b := broker.NewBroker(gomock.NewController(t)).
+				CreateTopic("topic", param.partitions)
+			for _, key := range param.keys {
+				b.Add("topic", key, []byte(key))
+			}
+			consumer := b.Consumer("group", "topic")
+			for count := 0; count < param.commits; count++ {
+				result, err := consumer.Poll(context.Background())
+				assert.NoError(t, err)
+				consumer.Commit(result)
+			}
+
+			// When
+			result := b.AssertConsumed("group", "topic")
+
+			// Then
+			assert.Equal(t, param.expectResult, result)
+		})
+}
+
+type CreateTopicParams struct {
+	setup        func(b *broker.Broker)
+	partitions   int
+	expectError  error
+	expectLength int
+	expect       test.Expect
+}
+
+var testCreateTopicParams = map[string]CreateTopicParams{
+	"new topic": {
+		setup:        func(*broker.Broker) {},
+		partitions:   3,
+		expectLength: 3,
+		expect:       test.Success,
+	},
+	"new topic without partitions": {
+		setup:        func(*broker.Broker) {},
+		expectLength: 1,
+		expect:       test.Success,
+	},
+	"existing topic": {
+		setup: func(b *broker.Broker) {
+			b.CreateTopic("topic", 3)
+		},
+		partitions:   3,
+		expectLength: 3,
+		expect:       test.Success,
+	},
+	"implicit topic": {
+		setup: func(b *broker.Broker) {
+			b.Add("topic", "", []byte("a"))
+		},
+		partitions:   1,
+		expectLength: 1,
+		expect:       test.Success,
+	},
+	"implicit topic mismatch": {
+		setup: func(b *broker.Broker) {
+			b.Consumer("group", "topic")
+		},
+		partitions:  3,
+		expectError: broker.ErrPartitions("topic", 1, 3),
+		expect:      test.Failure,
+	},
+	"existing topic mismatch": {
+		setup: func(b *broker.Broker) {
+			b.CreateTopic("topic", 2)
+		},
+		partitions:  3,
+		expectError: broker.ErrPartitions("topic", 2, 3),
+		expect:      test.Failure,
+	},
+}
+
+func TestCreateTopic(t *testing.T) {
+	test.Map(t, testCreateTopicParams).
+		Run(func(t test.Test, param CreateTopicParams) {
+			// Given
+			if param.expectError != nil {
+				mock.NewMock(t).Expect(test.Fatalf("%v", param.expectError))
+			}
+			b := broker.NewBroker(gomock.NewController(t))
+			param.setup(b)
+
+			// When
+			b.CreateTopic("topic", param.partitions)
+
+			// Then
+			assert.Len(t, b.Committed("group", "topic"), param.expectLength)
+		})
+}
+
+func TestCommitted(t *testing.T) {
+	// Given
+	b := broker.NewBroker(gomock.NewController(t)).
+		CreateTopic("topic", 2)
+	b.Add("topic", "a", []byte("a"))
+	b.Add("topic", "a", []byte("b"))
+
+	// When
+	committed := b.Committed("group", "topic")
+	committed[1] = 5
+
+	// Then
+	assert.Equal(t, []int64{0, 0}, b.Committed("group", "topic"))
+	assert.Equal(t, []int64{0}, b.Committed("group", "missing"))
+}
@@ -0,0 +1,118 @@
+package broker
+
+import (
+	"context"
+)
+
+// Consumer is a consumer of a topic in a consumer group. Consumers of the same
+// consumer group share the offsets, i.e. each message is delivered to only one
+// of them until it is redelivered.
+type Consumer struct {
+	// broker the broker providing the messages.
+	broker *Broker
+	// group the name of the consumer group.
+	group string
+	// topic the name of the consumed topic.
+	topic string
+}
+
+// Consumer creates a new consumer of the topic with given name in the consumer
+// group with given name.
+func (b *Broker) Consumer(group, topic string) *Consumer {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.group(group, topic)
+	return &Consumer{broker: b, group: group, topic: topic}
+}
+
+// Poll returns the next message of the topic for the consumer group. If no
+// message is available, it waits until a message is published or the given
+// context is done. Injected faults fail the poll with the fault error or
+// deliver the message again on the next poll.
+func (c *Consumer) Poll(ctx context.Context) (*Message, error) {
+	for {
+		c.broker.mu.Lock()
+		fault := c.broker.fault(c.group, c.topic)
+		if fault != nil && !fault.Duplicate {
+			c.broker.consume(fault)
+			c.broker.mu.Unlock()
+			return nil, fault.Err
+		}
+
+		if msg := c.next(fault != nil); msg != nil {
+			if fault != nil {
+				c.broker.consume(fault)
+			}
+			c.broker.mu.Unlock()
+			return msg, nil
+		}
+		notify := c.broker.notify
+		c.broker.mu.Unlock()
+
+		select {
+		case <-notify:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// next returns the next message of the consumer group polling the partitions
+// round robin and advances the position and the polled partition, unless the
+// message is duplicated, so that it is redelivered on the next poll. If no
+// message is available, nil is returned.
+func (c *Consumer) next(duplicate bool) *Message {
+	t, g := c.broker.topic(c.topic, 1), c.broker.group(c.group, c.topic)
+	for count := 0; count < len(t.partitions); count++ {
+		partition := (g.next + count) % len(t.partitions)
+		offset := g.position[partition]
+		if offset >= int64(len(t.partitions[partition])) {
+			continue
+		}
+		if duplicate {
+			g.next = partition
+		} else {
+			g.position[partition]++
+			g.next = (partition + 1) % len(t.partitions)
+		}
+		key := [2]int64{int64(partition), offset}
+		g.attempts[key]++
+
+		msg := t.partitions[partition][offset].clone()
+		msg.Attempt = g.attempts[key]
+		return msg
+	}
+	return nil
+}
+
+// Commit commits the offset of the given message for the consumer group, i.e.
+// the message and all messages before in the same partition are consumed.
+func (c *Consumer) Commit(msg *Message) {
+	c.broker.mu.Lock()
+	defer c.broker.mu.Unlock()
+	g := c.broker.group(c.group, c.topic)
+	if offset := msg.Offset + 1; offset > g.committed[msg.Partition] {
+		g.committed[msg.Partition] = offset
+	}
+}
+
+// Nack rejects the given message, so that it and all following messages in the
+// same partition are redelivered on the next polls.
+func (c *Consumer) Nack(msg *Message) {
+	c.broker.mu.Lock()
+	defer c.broker.mu.Unlock()
+	g := c.broker.group(c.group, c.topic)
+	if msg.Offset < g.position[msg.Partition] {
+		g.position[msg.Partition] = msg.Offset
+	}
+}
+
+// Reset resets the positions of the consumer group to the committed offsets,
+// so that all uncommitted messages are redelivered, e.g. to simulate a restart
+// or rebalance of the consumer group.
+func (c *Consumer) Reset() {
+	c.broker.mu.Lock()
+	defer c.broker.mu.Unlock()
+	g := c.broker.group(c.group, c.topic)
+	copy(g.position, g.committed)
+}
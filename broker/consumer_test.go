@@ -0,0 +1,289 @@
+package broker_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tkrop/go-testing/broker"
+	"github.com/tkrop/go-testing/test"
+)
+
+var errPoll = errors.New("poll failed")
+
+// poll is a consumer action with the expected outcome.
+type poll struct {
+	// action the consumer action to apply before polling.
+	action func(consumer *broker.Consumer, last *broker.Message)
+	// expectMsg the expected message, nil if an error is expected.
+	expectMsg *broker.Message
+	// expectErr the expected error.
+	expectErr error
+}
+
+type ConsumerParams struct {
+	partitions      int
+	keys            []string
+	faults          []broker.Fault
+	polls           []poll
+	expectCommitted []int64
+}
+
+// commit commits the last polled message.
+func commit(consumer *broker.Consumer, last *broker.Message) {
+	consumer.Commit(last)
+}
+
+// nack rejects the last polled message.
+func nack(consumer *broker.Consumer, last *broker.Message) {
+	consumer.Nack(last)
+}
+
+// reset resets the consumer group to the committed offsets.
+func reset(consumer *broker.Consumer, _ *broker.Message) {
+	consumer.Reset()
+}
+
+var testConsumerParams = map[string]ConsumerParams{
+	"poll single partition": {
+		partitions: 1,
+		keys:       []string{"a", "b"},
+		polls: []poll{
+			{expectMsg: msg("topic", 0, 0, "a", "a", 1)},
+			{expectMsg: msg("topic", 0, 1, "b", "b", 1)},
+		},
+		expectCommitted: []int64{0},
+	},
+	"poll round robin partitions": {
+		partitions: 2,
+		keys:       []string{"a", "a", "b"},
+		polls: []poll{
+			{expectMsg: msg("topic", 0, 0, "a", "a", 1)},
+			{expectMsg: msg("topic", 1, 0, "b", "b", 1)},
+			{expectMsg: msg("topic", 0, 1, "a", "a", 1)},
+		},
+		expectCommitted: []int64{0, 0},
+	},
+	"poll and commit": {
+		partitions: 2,
+		keys:       []string{"a", "a", "b"},
+		polls: []poll{
+			{expectMsg: msg("topic", 0, 0, "a", "a", 1)},
+			{action: commit, expectMsg: msg("topic", 1, 0, "b", "b", 1)},
+			{action: commit, expectMsg: msg("topic", 0, 1, "a", "a", 1)},
+			{action: commit},
+		},
+		expectCommitted: []int64{2, 1},
+	},
+	"commit keeps highest offset": {
+		partitions: 1,
+		keys:       []string{"a", "b"},
+		polls: []poll{
+			{expectMsg: msg("topic", 0, 0, "a", "a", 1)},
+			{expectMsg: msg("topic", 0, 1, "b", "b", 1)},
+			{action: commit},
+			{action: func(consumer *broker.Consumer, _ *broker.Message) {
+				consumer.Commit(msg("topic", 0, 0, "a", "a", 1))
+			}},
+		},
+		expectCommitted: []int64{2},
+	},
+	"nack redelivers message": {
+		partitions: 1,
+		keys:       []string{"a", "b"},
+		polls: []poll{
+			{expectMsg: msg("topic", 0, 0, "a", "a", 1)},
+			{action: nack, expectMsg: msg("topic", 0, 0, "a", "a", 2)},
+			{action: commit, expectMsg: msg("topic", 0, 1, "b", "b", 1)},
+		},
+		expectCommitted: []int64{1},
+	},
+	"nack redelivers following messages": {
+		partitions: 1,
+		keys:       []string{"a", "b"},
+		polls: []poll{
+			{expectMsg: msg("topic", 0, 0, "a", "a", 1)},
+			{expectMsg: msg("topic", 0, 1, "b", "b", 1)},
+			{action: func(consumer *broker.Consumer, _ *broker.Message) {
+				consumer.Nack(msg("topic", 0, 0, "a", "a", 1))
+			}, expectMsg: msg("topic", 0, 0, "a", "a", 2)},
+			{expectMsg: msg("topic", 0, 1, "b", "b", 2)},
+		},
+		expectCommitted: []int64{0},
+	},
+	"reset redelivers uncommitted": {
+		partitions: 1,
+		keys:       []string{"a", "b", "c"},
+		polls: []poll{
+			{expectMsg: msg("topic", 0, 0, "a", "a", 1)},
+			{action: commit, expectMsg: msg("topic", 0, 1, "b", "b", 1)},
+			{expectMsg: msg("topic", 0, 2, "c", "c", 1)},
+			{action: reset, expectMsg: msg("topic", 0, 1, "b", "b", 2)},
+		},
+		expectCommitted: []int64{1},
+	},
+	"fault fails polls": {
+		partitions: 1,
+		keys:       []string{"a"},
+		faults:     []broker.Fault{{Err: errPoll, Times: 2}},
+		polls: []poll{
+			{expectErr: errPoll},
+			{expectErr: errPoll},
+			{expectMsg: msg("topic", 0, 0, "a", "a", 1)},
+		},
+		expectCommitted: []int64{0},
+	},
+	"fault of other group": {
+		partitions: 1,
+		keys:       []string{"a"},
+		faults: []broker.Fault{
+			{Group: "other", Err: errPoll},
+			{Topic: "other", Err: errPoll},
+		},
+		polls: []poll{
+			{expectMsg: msg("topic", 0, 0, "a", "a", 1)},
+		},
+		expectCommitted: []int64{0},
+	},
+	"fault duplicates message": {
+		partitions: 1,
+		keys:       []string{"a", "b"},
+		faults: []broker.Fault{
+			{Group: "group", Topic: "topic", Duplicate: true, Times: 1},
+		},
+		polls: []poll{
+			{expectMsg: msg("topic", 0, 0, "a", "a", 1)},
+			{expectMsg: msg("topic", 0, 0, "a", "a", 2)},
+			{expectMsg: msg("topic", 0, 1, "b", "b", 1)},
+		},
+		expectCommitted: []int64{0},
+	},
+	"duplicate message multiple partitions": {
+		partitions: 2,
+		keys:       []string{"a", "b"},
+		faults: []broker.Fault{
+			{Group: "group", Topic: "topic", Duplicate: true, Times: 1},
+		},
+		polls: []poll{
+			{expectMsg: msg("topic", 0, 0, "a", "a", 1)},
+			{expectMsg: msg("topic", 0, 0, "a", "a", 2)},
+			{expectMsg: msg("topic", 1, 0, "b", "b", 1)},
+		},
+		expectCommitted: []int64{0, 0},
+	},
+	"faults in order": {
+		partitions: 1,
+		keys:       []string{"a"},
+		faults: []broker.Fault{
+			{Duplicate: true, Times: 1},
+			{Err: errPoll, Times: 1},
+		},
+		polls: []poll{
+			{expectMsg: msg("topic", 0, 0, "a", "a", 1)},
+			{expectErr: errPoll},
+			{expectMsg: msg("topic", 0, 0, "a", "a", 2)},
+		},
+		expectCommitted: []int64{0},
+	},
+}
+
+func TestConsumer(t *testing.T) {
+	test.Map(t, testConsumerParams).
+		Run(func(t test.Test, param ConsumerParams) {
+			// Given
+			b := broker.NewBroker(gomock.NewController(t)).
+				CreateTopic("topic", param.partitions)
+			for _, key := range param.keys {
+				b.Add("topic", key, []byte(key))
+			}
+			for _, fault := range param.faults {
+				b.Fail(fault)
+			}
+			consumer := b.Consumer("group", "topic")
+
+			// When
+			var last *broker.Message
+			for _, step := range param.polls {
+				if step.action != nil {
+					step.action(consumer, last)
+				}
+				if step.expectMsg == nil && step.expectErr == nil {
+					continue
+				}
+
+				result, err := consumer.Poll(context.Background())
+
+				// Then
+				assert.Equal(t, step.expectErr, err)
+				assert.Equal(t, step.expectMsg, result)
+				last = result
+			}
+			assert.Equal(t, param.expectCommitted,
+				b.Committed("group", "topic"))
+		})
+}
+
+func TestConsumerGroups(t *testing.T) {
+	// Given
+	b := broker.NewBroker(gomock.NewController(t))
+	b.Add("topic", "a", []byte("a"))
+	first := b.Consumer("group", "topic")
+	second := b.Consumer("group", "topic")
+	other := b.Consumer("other", "topic")
+
+	// When
+	msg1, err1 := first.Poll(context.Background())
+	msg2, err2 := other.Poll(context.Background())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	msg3, err3 := second.Poll(ctx)
+
+	// Then
+	assert.NoError(t, err1)
+	assert.Equal(t, msg("topic", 0, 0, "a", "a", 1), msg1)
+	assert.NoError(t, err2)
+	assert.Equal(t, msg("topic", 0, 0, "a", "a", 1), msg2)
+	assert.Equal(t, context.Canceled, err3)
+	assert.Nil(t, msg3)
+}
+
+func TestConsumerWait(t *testing.T) {
+	// Given
+	b := broker.NewBroker(gomock.NewController(t))
+	consumer := b.Consumer("group", "topic")
+	b.Fail(broker.Fault{Duplicate: true, Times: 1})
+
+	// When
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		b.Add("topic", "a", []byte("a"))
+	}()
+	msg1, err1 := consumer.Poll(context.Background())
+	msg2, err2 := consumer.Poll(context.Background())
+
+	// Then
+	assert.NoError(t, err1)
+	assert.Equal(t, msg("topic", 0, 0, "a", "a", 1), msg1)
+	assert.NoError(t, err2)
+	assert.Equal(t, msg("topic", 0, 0, "a", "a", 2), msg2)
+}
+
+func TestConsumerTimeout(t *testing.T) {
+	// Given
+	b := broker.NewBroker(gomock.NewController(t))
+	consumer := b.Consumer("group", "topic")
+	ctx, cancel := context.WithTimeout(context.Background(),
+		10*time.Millisecond)
+	defer cancel()
+
+	// When
+	result, err := consumer.Poll(ctx)
+
+	// Then
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Nil(t, result)
+}
@@ -0,0 +1,60 @@
+// Command coverage runs each test case of a package - including the
+// `test.Map` cases - separately collecting its coverage profile and reports
+// the test cases that add no unique coverage. In verbose mode, it also reports
+// the lines that are covered by only a single test case.
+//
+// Usage:
+//
+//	coverage [-run regexp] [-coverpkg pattern] [-timeout duration] [-v] [package-dir]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/tkrop/go-testing/internal/coverage"
+)
+
+func main() {
+	run := flag.String("run", "", "run only tests matching regexp")
+	coverpkg := flag.String("coverpkg", "", "apply coverage to packages")
+	timeout := flag.String("timeout", "1m", "timeout of each test run")
+	verbose := flag.Bool("v", false, "report lines covered by only one case")
+	flag.Parse()
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+
+	args := []string{"-timeout=" + *timeout}
+	if *coverpkg != "" {
+		args = append(args, "-coverpkg="+*coverpkg)
+	}
+
+	runner := coverage.NewRunner(dir, args...)
+	cases, err := runner.Cases(*run)
+	if err != nil {
+		exit(err)
+	}
+	profiles, err := runner.Run(cases...)
+	if err != nil {
+		exit(err)
+	}
+
+	analysis := coverage.Analyze(profiles)
+	if err := analysis.Report(os.Stdout, *verbose); err != nil {
+		exit(err)
+	}
+
+	if len(analysis.Redundant) != 0 {
+		os.Exit(1)
+	}
+}
+
+// exit reports the given error and exits the command with failure.
+func exit(err error) {
+	fmt.Fprintln(os.Stderr, "coverage:", err)
+	os.Exit(2)
+}
@@ -0,0 +1,62 @@
+// Command mutate applies source mutations to the functions of a package, i.e.
+// flipping operators, negating conditions, removing calls, and changing
+// constants, runs the package tests against each mutant, and reports the
+// surviving mutants per function. In verbose mode, it also reports the test
+// cases - including the `test.Map` cases - that killed each mutant.
+//
+// Usage:
+//
+//	mutate [-run regexp] [-timeout duration] [-v] [package-dir]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/tkrop/go-testing/internal/mutate"
+)
+
+func main() {
+	run := flag.String("run", "", "run only tests matching regexp")
+	timeout := flag.String("timeout", "1m", "timeout of each test run")
+	verbose := flag.Bool("v", false, "report killed mutants with test cases")
+	flag.Parse()
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+
+	args := []string{"-timeout=" + *timeout}
+	if *run != "" {
+		args = append(args, "-run="+*run)
+	}
+
+	runner := mutate.NewRunner(dir, args...)
+	if *verbose {
+		runner.Progress = os.Stderr
+	}
+
+	files, err := runner.Files()
+	if err != nil {
+		exit(err)
+	}
+	results, err := runner.Run(files...)
+	if err != nil {
+		exit(err)
+	}
+	if err := mutate.Report(os.Stdout, results, *verbose); err != nil {
+		exit(err)
+	}
+
+	if mutate.Survivors(results) != 0 {
+		os.Exit(1)
+	}
+}
+
+// exit reports the given error and exits the command with failure.
+func exit(err error) {
+	fmt.Fprintln(os.Stderr, "mutate:", err)
+	os.Exit(2)
+}
@@ -0,0 +1,76 @@
+// Command order detects test order dependencies of a package. It runs each
+// test case - including the `test.Map` cases - in isolation, reruns the
+// package tests repeatedly with shuffled test and test case order using
+// reported seeds, reruns the order dependent test cases in isolation together
+// with each suspected test case, and reports the test cases whose outcome
+// changes depending on the test cases that ran before them.
+//
+// Usage:
+//
+//	order [-run regexp] [-runs count] [-seed seed] [-timeout duration] [-v] [package-dir]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/tkrop/go-testing/internal/order"
+)
+
+func main() {
+	run := flag.String("run", "", "run only tests matching regexp")
+	runs := flag.Int("runs", 10, "number of shuffled test runs")
+	seed := flag.Int64("seed", 0,
+		"seed of the first shuffled test run (default time based)")
+	timeout := flag.String("timeout", "1m", "timeout of each test run")
+	verbose := flag.Bool("v", false, "report progress of the test runs")
+	flag.Parse()
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+	if !isSet("seed") {
+		*seed = time.Now().UnixNano()
+	}
+	fmt.Fprintf(os.Stdout, "order: shuffle seeds %d..%d\n",
+		*seed, *seed+int64(*runs)-1)
+
+	runner := order.NewRunner(dir, "-timeout="+*timeout)
+	if *verbose {
+		runner.Progress = os.Stderr
+	}
+	names, err := runner.Cases(*run)
+	if err != nil {
+		exit(err)
+	}
+	cases, err := runner.Run(*run, *seed, *runs, names...)
+	if err != nil {
+		exit(err)
+	}
+
+	if err := order.Report(os.Stdout, cases); err != nil {
+		exit(err)
+	}
+
+	if order.Dependents(cases) != 0 {
+		os.Exit(1)
+	}
+}
+
+// isSet returns whether the flag with given name was set on the command line.
+func isSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		set = set || f.Name == name
+	})
+	return set
+}
+
+// exit reports the given error and exits the command with failure.
+func exit(err error) {
+	fmt.Fprintln(os.Stderr, "order:", err)
+	os.Exit(2)
+}
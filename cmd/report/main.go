@@ -0,0 +1,96 @@
+// Command report reads the test events provided via `go test -json` from the
+// given files or the standard input and renders a Markdown or HTML test report
+// grouped by package, test table, and test case. The report classifies the
+// test messages into normal logs and mock diagnostics, and highlights test
+// cases with inverted expectations as reported by the isolated `test.Tester`.
+//
+// Usage:
+//
+//	go test -json ./... | report [-format markdown|html] [-o file]
+//	report [-format markdown|html] [-o file] [event-file...]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/tkrop/go-testing/internal/gotest"
+	"github.com/tkrop/go-testing/internal/report"
+)
+
+func main() {
+	format := flag.String("format", "markdown", "report format (markdown|html)")
+	output := flag.String("o", "", "write report to file instead of stdout")
+	flag.Parse()
+
+	events, err := read(flag.Args()...)
+	if err != nil {
+		exit(err)
+	}
+
+	if err := write(*output, *format, report.Build(events)); err != nil {
+		exit(err)
+	}
+}
+
+// read reads the test events from the given files or from the standard input,
+// if no files are given.
+func read(files ...string) ([]gotest.Event, error) {
+	if len(files) == 0 {
+		return gotest.Parse(os.Stdin)
+	}
+
+	events := []gotest.Event{}
+	for _, name := range files {
+		file, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		fevents, err := gotest.Parse(file)
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, fevents...)
+	}
+	return events, nil
+}
+
+// write renders the test report in the given format to the file with given
+// name or to the standard output, if no name is given. The file is closed
+// explicitly to report write errors before exiting.
+func write(name, format string, result *report.Report) error {
+	if name == "" {
+		return render(os.Stdout, format, result)
+	}
+
+	file, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	err = render(file, format, result)
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// render renders the test report in the given format to the given writer.
+func render(writer io.Writer, format string, result *report.Report) error {
+	switch format {
+	case "markdown":
+		return result.Markdown(writer)
+	case "html":
+		return result.HTML(writer)
+	default:
+		return fmt.Errorf("unknown format: %s", format)
+	}
+}
+
+// exit reports the given error and exits the command with failure.
+func exit(err error) {
+	fmt.Fprintln(os.Stderr, "report:", err)
+	os.Exit(2)
+}
@@ -1,11 +1,13 @@
 package gock
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/golang/mock/gomock"
 	gock "gopkg.in/h2non/gock.v1"
 
+	"github.com/tkrop/go-testing/mock"
 	"github.com/tkrop/go-testing/test"
 )
 
@@ -39,6 +41,8 @@ type Controller struct {
 	t test.Test
 	// MockStore the attached HTTP request/response mock storage.
 	MockStore *MockStore
+	// tracers the optional tracers of HTTP request/response interactions.
+	tracers []mock.Tracer
 }
 
 // NewGock creates a new HTTP request/response mock controller from the given
@@ -71,6 +75,14 @@ func (ctrl *Controller) New(uri string) *gock.Request {
 	return ctrl.MockStore.NewMock(uri).Request()
 }
 
+// Tracer adds a tracer receiving the HTTP request/response interactions
+// handled by the controller. If multiple tracers are added, each tracer
+// receives all interactions.
+func (ctrl *Controller) Tracer(tracer mock.Tracer) *Controller {
+	ctrl.tracers = append(ctrl.tracers, tracer)
+	return ctrl
+}
+
 // InterceptClient allows to intercept HTTP traffic of a custom http.Client that
 // uses a non default http.Transport/http.RoundTripper implementation.
 func (ctrl *Controller) InterceptClient(client *http.Client) {
@@ -98,14 +110,39 @@ func (ctrl *Controller) RestoreClient(client *http.Client) {
 func (ctrl *Controller) RoundTrip(req *http.Request) (*http.Response, error) {
 	// find matching mock for the incoming request.
 	mock, err := ctrl.MockStore.Match(req)
+	if err == nil && mock == nil {
+		err = gock.ErrCannotMatch
+	}
 	if err != nil {
+		ctrl.trace(req, "error(%v)", err)
 		return nil, err
-	} else if mock == nil {
-		return nil, gock.ErrCannotMatch
 	}
 	defer ctrl.MockStore.Clean()
 
-	return gock.Responder(req, mock.Response(), nil)
+	resp, err := gock.Responder(req, mock.Response(), nil)
+	if rpc, ok := mock.(*RPC); ok {
+		rpc.release(req)
+	}
+	if err != nil {
+		ctrl.trace(req, "error(%v)", err)
+	} else {
+		ctrl.trace(req, "%d", resp.StatusCode)
+	}
+	return resp, err
+}
+
+// trace traces the given HTTP request with given result, if tracers are set
+// up.
+func (ctrl *Controller) trace(req *http.Request, format string, args ...any) {
+	if len(ctrl.tracers) == 0 {
+		return
+	}
+
+	entry := req.Method + " " + req.URL.String() + " => " +
+		fmt.Sprintf(format, args...)
+	for _, tracer := range ctrl.tracers {
+		tracer.Trace(entry)
+	}
 }
 
 // Finish checks if all the HTTP request/response mocks that were expected to
@@ -15,24 +15,31 @@ type ControllerParams struct {
 	url         string
 	expectMatch test.Expect
 	expectError error
+	expectTrace []string
 }
 
 var testControllerParams = map[string]ControllerParams{
 	"match with bar": {
 		url:         "http://foo.com/bar",
 		expectMatch: test.Success,
+		expectTrace: []string{"GET http://foo.com/bar => 200"},
 	},
 	"match with baz": {
 		url:         "http://foo.com/baz",
 		expectMatch: test.Success,
+		expectTrace: []string{"GET http://foo.com/baz => 200"},
 	},
 	"missing host": {
 		url:         "http://bar.com/baz",
 		expectError: gock.ErrCannotMatch,
+		expectTrace: []string{"GET http://bar.com/baz => " +
+			"error(gock: cannot match any request)"},
 	},
 	"missing path": {
 		url:         "http://foo.com/foo",
 		expectError: gock.ErrCannotMatch,
+		expectTrace: []string{"GET http://foo.com/foo => " +
+			"error(gock: cannot match any request)"},
 	},
 	"missing schema": {
 		url:         "https://foo.com/bar",
@@ -48,6 +55,8 @@ func TestController(t *testing.T) {
 			ctrl.MockStore.Matcher = NewFooMatcher()
 			ctrl.New("http://foo.com").Get("/bar").Times(1).Reply(200)
 			client := &http.Client{}
+			tracer, other := &tracer{}, &tracer{}
+			ctrl.Tracer(tracer).Tracer(other)
 
 			// When
 			ctrl.RestoreClient(client)
@@ -69,10 +78,21 @@ func TestController(t *testing.T) {
 			} else {
 				assert.False(t, ctrl.MockStore.IsDone(), "mock not done")
 			}
+			assert.Equal(t, param.expectTrace, tracer.entries)
+			assert.Equal(t, param.expectTrace, other.entries)
 			ctrl.cleanup()
 		})
 }
 
+// tracer is a simple tracer recording the traced interactions.
+type tracer struct {
+	entries []string
+}
+
+func (t *tracer) Trace(entry string) {
+	t.entries = append(t.entries, entry)
+}
+
 func TestPanic(t *testing.T) {
 	// Given
 	defer func() {
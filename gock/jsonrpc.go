@@ -0,0 +1,397 @@
+package gock
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	gock "gopkg.in/h2non/gock.v1"
+)
+
+// JSON-RPC 2.0 version and standard error codes.
+const (
+	// RPCVersion is the supported JSON-RPC protocol version.
+	RPCVersion = "2.0"
+
+	// CodeParseError is the error code for invalid JSON.
+	CodeParseError = -32700
+	// CodeInvalidRequest is the error code for an invalid request object.
+	CodeInvalidRequest = -32600
+	// CodeMethodNotFound is the error code for a missing method.
+	CodeMethodNotFound = -32601
+	// CodeInvalidParams is the error code for invalid method parameters.
+	CodeInvalidParams = -32602
+	// CodeInternalError is the error code for an internal error.
+	CodeInternalError = -32603
+)
+
+// RPCError is the JSON-RPC 2.0 error object.
+type RPCError struct {
+	// Code the error code.
+	Code int `json:"code"`
+	// Message the short error description.
+	Message string `json:"message"`
+	// Data the optional additional error information.
+	Data any `json:"data,omitempty"`
+}
+
+// Error returns the error description of the JSON-RPC 2.0 error object.
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("rpc error [code=%d]: %s", e.Code, e.Message)
+}
+
+// rpcRequest is a JSON-RPC 2.0 request object.
+type rpcRequest struct {
+	// Version the JSON-RPC protocol version.
+	Version string `json:"jsonrpc"`
+	// Method the name of the called method.
+	Method string `json:"method"`
+	// Params the raw parameters of the called method.
+	Params json.RawMessage `json:"params,omitempty"`
+	// ID the raw request identifier, missing for notifications.
+	ID json.RawMessage `json:"id,omitempty"`
+}
+
+// rpcResponse is a JSON-RPC 2.0 response object.
+type rpcResponse struct {
+	// Version the JSON-RPC protocol version.
+	Version string `json:"jsonrpc"`
+	// Result the raw result of the called method.
+	Result json.RawMessage `json:"result,omitempty"`
+	// Error the error object of the called method.
+	Error *RPCError `json:"error,omitempty"`
+	// ID the raw request identifier echoed from the request.
+	ID json.RawMessage `json:"id"`
+}
+
+// RPC is a JSON-RPC 2.0 request/response mock for a single endpoint, i.e. an
+// URL, where every request is matched against the expected method calls
+// instead of the request path. It supports batch requests, notifications,
+// per-method call expectations, and optionally call ordering.
+type RPC struct {
+	*gock.Mocker
+	// mutex the mutex to protect the expected calls and responses.
+	mutex sync.Mutex
+	// calls the expected method calls in order of registration.
+	calls []*RPCCall
+	// ordered whether the method calls are expected in order of registration.
+	ordered bool
+	// replies the prepared response bodies of the matched requests.
+	replies map[*http.Request][]byte
+}
+
+// RPCCall is an expected JSON-RPC 2.0 method call with its response.
+type RPCCall struct {
+	// method the name of the expected method.
+	method string
+	// params the matcher of the method parameters.
+	params func(params json.RawMessage) bool
+	// describe the description of the expected parameters.
+	describe string
+	// result the raw result of the method call.
+	result json.RawMessage
+	// err the error object of the method call.
+	err *RPCError
+	// times the number of expected method calls.
+	times int
+	// count the number of actual method calls.
+	count int
+}
+
+// RPC creates and registers a new JSON-RPC 2.0 request/response mock for the
+// endpoint with given full qualified URI. It returns the mock for setup of the
+// expected method calls via `Call`.
+func (ctrl *Controller) RPC(uri string) *RPC {
+	mock := ctrl.MockStore.NewMock(uri)
+	ctrl.MockStore.Remove(mock)
+	mock.Request().Method = http.MethodPost
+	mock.Request().Persist()
+	mock.Response().Status(http.StatusOK).
+		SetHeader("Content-Type", "application/json")
+
+	rpc := &RPC{
+		Mocker:  mock.(*gock.Mocker),
+		replies: map[*http.Request][]byte{},
+	}
+	mock.Response().Map(rpc.respond)
+	ctrl.MockStore.Register(rpc)
+	return rpc
+}
+
+// InOrder sets up the mock to expect the method calls in order of their
+// registration, i.e. a method call is only matched, if all method calls
+// registered before are exhausted.
+func (r *RPC) InOrder() *RPC {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.ordered = true
+	return r
+}
+
+// Call registers a new expected method call with given method name. By default
+// the method call is expected once with any parameters and replies a `null`
+// result.
+func (r *RPC) Call(method string) *RPCCall {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	call := &RPCCall{
+		method: method, times: 1,
+		result: json.RawMessage("null"),
+	}
+	r.calls = append(r.calls, call)
+	return call
+}
+
+// Done returns whether the mock is disabled or all expected method calls are
+// exhausted.
+func (r *RPC) Done() bool {
+	if r.Mocker.Done() {
+		return true
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for _, call := range r.calls {
+		if call.count < call.times {
+			return false
+		}
+	}
+	return true
+}
+
+// Match matches the given `http.Request` with the endpoint and all contained
+// method calls with the expected method calls. The request only matches, if
+// all method calls of a batch request match, in which case the method calls
+// are consumed and the response is prepared.
+func (r *RPC) Match(req *http.Request) (bool, error) {
+	if matches, err := r.Mocker.Match(req); !matches || err != nil {
+		return matches, err
+	}
+
+	requests, batch, err := r.read(req)
+	if err != nil || len(requests) == 0 {
+		return false, nil
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	counts := make([]int, len(r.calls))
+	for index, call := range r.calls {
+		counts[index] = call.count
+	}
+
+	responses := []*rpcResponse{}
+	for _, request := range requests {
+		index := r.match(request, counts)
+		if index < 0 {
+			return false, nil
+		}
+		counts[index]++
+
+		if len(request.ID) != 0 {
+			call := r.calls[index]
+			response := &rpcResponse{Version: RPCVersion, ID: request.ID}
+			if call.err != nil {
+				response.Error = call.err
+			} else {
+				response.Result = call.result
+			}
+			responses = append(responses, response)
+		}
+	}
+
+	for index, call := range r.calls {
+		call.count = counts[index]
+	}
+	r.replies[req] = reply(responses, batch)
+	return true, nil
+}
+
+// read reads the method calls of the given request and restores the request
+// body for further use. It returns whether the request is a batch request.
+func (*RPC) read(req *http.Request) ([]*rpcRequest, bool, error) {
+	if req.Body == nil {
+		return nil, false, io.ErrUnexpectedEOF
+	}
+	data, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, false, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(data))
+
+	data = bytes.TrimSpace(data)
+	if len(data) != 0 && data[0] == '[' {
+		requests := []*rpcRequest{}
+		err := json.Unmarshal(data, &requests)
+		return requests, true, err
+	}
+	request := &rpcRequest{}
+	err = json.Unmarshal(data, request)
+	return []*rpcRequest{request}, false, err
+}
+
+// match returns the index of the expected method call matching the given
+// method call considering the given call counts, or -1 if no expected method
+// call matches.
+func (r *RPC) match(request *rpcRequest, counts []int) int {
+	if request == nil || request.Version != RPCVersion {
+		return -1
+	}
+	for index, call := range r.calls {
+		if counts[index] >= call.times {
+			continue
+		} else if call.matches(request) {
+			return index
+		} else if r.ordered {
+			return -1
+		}
+	}
+	return -1
+}
+
+// respond maps the response of a matched request to the prepared response
+// body. Requests containing only notifications are answered without content.
+func (r *RPC) respond(res *http.Response) *http.Response {
+	r.mutex.Lock()
+	data, ok := r.replies[res.Request]
+	r.mutex.Unlock()
+
+	if !ok || data == nil {
+		res.Status = fmt.Sprintf("%d %s", http.StatusNoContent,
+			http.StatusText(http.StatusNoContent))
+		res.StatusCode = http.StatusNoContent
+		res.Header.Del("Content-Type")
+		data = []byte{}
+	}
+	res.ContentLength = int64(len(data))
+	res.Body = io.NopCloser(bytes.NewReader(data))
+	return res
+}
+
+// release releases the prepared response body of the given request after the
+// response was built. This is done by the controller, since the response
+// mapper is skipped, e.g. if the response is set up to fail.
+func (r *RPC) release(req *http.Request) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.replies, req)
+}
+
+// String returns a description of the endpoint and the method calls that are
+// not exhausted.
+func (r *RPC) String() string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	pending := []string{}
+	for _, call := range r.calls {
+		if call.count < call.times {
+			pending = append(pending, call.String())
+		}
+	}
+	return fmt.Sprintf("rpc %s [%s]", r.Request().URLStruct,
+		strings.Join(pending, ", "))
+}
+
+// Params sets up the method call to match only, if the parameters are equal to
+// the given parameters after conversion to JSON.
+func (c *RPCCall) Params(params any) *RPCCall {
+	expect, err := normalize(params)
+	if err != nil {
+		panic(ErrRPCParams(err))
+	}
+	c.describe = string(expect)
+	c.params = func(actual json.RawMessage) bool {
+		actual, err := normalize(actual)
+		return err == nil && bytes.Equal(expect, actual)
+	}
+	return c
+}
+
+// ParamsFunc sets up the method call to match only, if the given function
+// accepts the raw parameters.
+func (c *RPCCall) ParamsFunc(match func(params json.RawMessage) bool) *RPCCall {
+	c.describe = "func"
+	c.params = match
+	return c
+}
+
+// Times sets up the number of expected method calls.
+func (c *RPCCall) Times(times int) *RPCCall {
+	c.times = times
+	return c
+}
+
+// Reply sets up the result of the method call after conversion to JSON.
+func (c *RPCCall) Reply(result any) *RPCCall {
+	data, err := json.Marshal(result)
+	if err != nil {
+		panic(ErrRPCResult(err))
+	}
+	c.result, c.err = data, nil
+	return c
+}
+
+// ReplyError sets up the method call to reply the error object with given
+// code, message, and optional additional data.
+func (c *RPCCall) ReplyError(code int, message string, data any) *RPCCall {
+	c.err = &RPCError{Code: code, Message: message, Data: data}
+	return c
+}
+
+// String returns a description of the method call with the number of actual
+// and expected calls.
+func (c *RPCCall) String() string {
+	return fmt.Sprintf("%s(%s) %d/%d", c.method, c.describe, c.count, c.times)
+}
+
+// matches returns whether the given method call matches the expected method
+// call.
+func (c *RPCCall) matches(request *rpcRequest) bool {
+	return c.method == request.Method &&
+		(c.params == nil || c.params(request.Params))
+}
+
+// normalize converts the given parameters to JSON and normalizes the result,
+// i.e. object keys are sorted and white space is removed.
+func normalize(params any) ([]byte, error) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, nil
+	}
+	return json.Marshal(value)
+}
+
+// reply creates the response body for the given responses. Without responses,
+// i.e. for notifications only, nil is returned.
+func reply(responses []*rpcResponse, batch bool) []byte {
+	if len(responses) == 0 {
+		return nil
+	} else if batch {
+		data, _ := json.Marshal(responses)
+		return data
+	}
+	data, _ := json.Marshal(responses[0])
+	return data
+}
+
+// ErrRPCParams creates an error reporting method call parameters that cannot
+// be converted to JSON.
+func ErrRPCParams(err error) error {
+	return fmt.Errorf("invalid rpc params: %w", err)
+}
+
+// ErrRPCResult creates an error reporting a method call result that cannot be
+// converted to JSON.
+func ErrRPCResult(err error) error {
+	return fmt.Errorf("invalid rpc result: %w", err)
+}
@@ -0,0 +1,360 @@
+package gock
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+
+	"github.com/tkrop/go-testing/test"
+)
+
+// rpcURL is the URL of the JSON-RPC endpoint used in the tests.
+const rpcURL = "http://rpc.test/api"
+
+// rpcExchange is a request to the JSON-RPC endpoint with expected response.
+type rpcExchange struct {
+	url          string
+	body         string
+	expectStatus int
+	expectBody   string
+	expectError  error
+}
+
+type RPCParams struct {
+	setup         func(rpc *RPC)
+	exchanges     []rpcExchange
+	expectPending string
+	expect        test.Expect
+}
+
+var testRPCParams = map[string]RPCParams{
+	"single call": {
+		setup: func(rpc *RPC) {
+			rpc.Call("sum").Params([]int{1, 2}).Reply(3)
+		},
+		exchanges: []rpcExchange{{
+			body:         `{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":1}`,
+			expectStatus: http.StatusOK,
+			expectBody:   `{"jsonrpc":"2.0","result":3,"id":1}`,
+		}},
+		expect: test.Success,
+	},
+	"single call string id": {
+		setup: func(rpc *RPC) {
+			rpc.Call("get").Params(map[string]any{"b": 2, "a": "x"}).
+				Reply(map[string]string{"name": "value"})
+		},
+		exchanges: []rpcExchange{{
+			body: `{"jsonrpc":"2.0","method":"get",` +
+				`"params":{"a": "x", "b": 2},"id":"abc"}`,
+			expectStatus: http.StatusOK,
+			expectBody: `{"jsonrpc":"2.0","result":{"name":"value"},` +
+				`"id":"abc"}`,
+		}},
+		expect: test.Success,
+	},
+	"call with null result": {
+		setup: func(rpc *RPC) {
+			rpc.Call("ping")
+		},
+		exchanges: []rpcExchange{{
+			body:         `{"jsonrpc":"2.0","method":"ping","id":7}`,
+			expectStatus: http.StatusOK,
+			expectBody:   `{"jsonrpc":"2.0","result":null,"id":7}`,
+		}},
+		expect: test.Success,
+	},
+	"call with error": {
+		setup: func(rpc *RPC) {
+			rpc.Call("get").Params(nil).Reply("ignored").
+				ReplyError(CodeInvalidParams, "invalid params", "missing")
+		},
+		exchanges: []rpcExchange{{
+			body:         `{"jsonrpc":"2.0","method":"get","id":1}`,
+			expectStatus: http.StatusOK,
+			expectBody: `{"jsonrpc":"2.0","error":{"code":-32602,` +
+				`"message":"invalid params","data":"missing"},"id":1}`,
+		}},
+		expect: test.Success,
+	},
+	"call with params func": {
+		setup: func(rpc *RPC) {
+			rpc.Call("get").ParamsFunc(func(params json.RawMessage) bool {
+				return bytes.Contains(params, []byte("x"))
+			}).Reply("x").Times(2)
+		},
+		exchanges: []rpcExchange{{
+			body:         `{"jsonrpc":"2.0","method":"get","params":["x"],"id":1}`,
+			expectStatus: http.StatusOK,
+			expectBody:   `{"jsonrpc":"2.0","result":"x","id":1}`,
+		}, {
+			body:        `{"jsonrpc":"2.0","method":"get","params":["y"],"id":2}`,
+			expectError: gock.ErrCannotMatch,
+		}, {
+			body:         `{"jsonrpc":"2.0","method":"get","params":["x"],"id":3}`,
+			expectStatus: http.StatusOK,
+			expectBody:   `{"jsonrpc":"2.0","result":"x","id":3}`,
+		}},
+		expect: test.Success,
+	},
+	"notification": {
+		setup: func(rpc *RPC) {
+			rpc.Call("notify").Params([]string{"event"})
+		},
+		exchanges: []rpcExchange{{
+			body:         `{"jsonrpc":"2.0","method":"notify","params":["event"]}`,
+			expectStatus: http.StatusNoContent,
+		}},
+		expect: test.Success,
+	},
+	"batch call": {
+		setup: func(rpc *RPC) {
+			rpc.Call("sum").Params([]int{1, 2}).Reply(3)
+			rpc.Call("sum").Params([]int{2, 2}).Reply(4)
+			rpc.Call("notify")
+		},
+		exchanges: []rpcExchange{{
+			body: `[{"jsonrpc":"2.0","method":"sum","params":[2,2],"id":1},` +
+				`{"jsonrpc":"2.0","method":"notify"},` +
+				`{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":2}]`,
+			expectStatus: http.StatusOK,
+			expectBody: `[{"jsonrpc":"2.0","result":4,"id":1},` +
+				`{"jsonrpc":"2.0","result":3,"id":2}]`,
+		}},
+		expect: test.Success,
+	},
+	"batch notifications": {
+		setup: func(rpc *RPC) {
+			rpc.Call("notify").Times(2)
+		},
+		exchanges: []rpcExchange{{
+			body: `[{"jsonrpc":"2.0","method":"notify"},` +
+				`{"jsonrpc":"2.0","method":"notify"}]`,
+			expectStatus: http.StatusNoContent,
+		}},
+		expect: test.Success,
+	},
+	"batch partial mismatch": {
+		setup: func(rpc *RPC) {
+			rpc.Call("sum").Reply(3)
+		},
+		exchanges: []rpcExchange{{
+			body: `[{"jsonrpc":"2.0","method":"sum","id":1},` +
+				`{"jsonrpc":"2.0","method":"other","id":2}]`,
+			expectError: gock.ErrCannotMatch,
+		}},
+		expectPending: "rpc http://rpc.test/api [sum() 0/1]",
+		expect:        test.Failure,
+	},
+	"call times exceeded": {
+		setup: func(rpc *RPC) {
+			rpc.Call("sum").Reply(3)
+		},
+		exchanges: []rpcExchange{{
+			body:         `{"jsonrpc":"2.0","method":"sum","id":1}`,
+			expectStatus: http.StatusOK,
+			expectBody:   `{"jsonrpc":"2.0","result":3,"id":1}`,
+		}, {
+			body:        `{"jsonrpc":"2.0","method":"sum","id":2}`,
+			expectError: gock.ErrCannotMatch,
+		}},
+		expect: test.Success,
+	},
+	"calls unordered": {
+		setup: func(rpc *RPC) {
+			rpc.Call("first").Reply(1)
+			rpc.Call("second").Reply(2)
+		},
+		exchanges: []rpcExchange{{
+			body:         `{"jsonrpc":"2.0","method":"second","id":1}`,
+			expectStatus: http.StatusOK,
+			expectBody:   `{"jsonrpc":"2.0","result":2,"id":1}`,
+		}, {
+			body:         `{"jsonrpc":"2.0","method":"first","id":2}`,
+			expectStatus: http.StatusOK,
+			expectBody:   `{"jsonrpc":"2.0","result":1,"id":2}`,
+		}},
+		expect: test.Success,
+	},
+	"calls in order": {
+		setup: func(rpc *RPC) {
+			rpc.InOrder()
+			rpc.Call("first").Reply(1)
+			rpc.Call("second").Reply(2)
+		},
+		exchanges: []rpcExchange{{
+			body:         `{"jsonrpc":"2.0","method":"first","id":1}`,
+			expectStatus: http.StatusOK,
+			expectBody:   `{"jsonrpc":"2.0","result":1,"id":1}`,
+		}, {
+			body:         `{"jsonrpc":"2.0","method":"second","id":2}`,
+			expectStatus: http.StatusOK,
+			expectBody:   `{"jsonrpc":"2.0","result":2,"id":2}`,
+		}},
+		expect: test.Success,
+	},
+	"calls out of order": {
+		setup: func(rpc *RPC) {
+			rpc.InOrder()
+			rpc.Call("first").Reply(1)
+			rpc.Call("second").Reply(2)
+		},
+		exchanges: []rpcExchange{{
+			body:        `{"jsonrpc":"2.0","method":"second","id":1}`,
+			expectError: gock.ErrCannotMatch,
+		}},
+		expectPending: "rpc http://rpc.test/api [first() 0/1, second() 0/1]",
+		expect:        test.Failure,
+	},
+	"missing calls": {
+		setup: func(rpc *RPC) {
+			rpc.Call("sum").Params([]int{1, 2}).Times(2)
+			rpc.Call("get").ParamsFunc(nil)
+		},
+		exchanges: []rpcExchange{{
+			body:         `{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":1}`,
+			expectStatus: http.StatusOK,
+			expectBody:   `{"jsonrpc":"2.0","result":null,"id":1}`,
+		}},
+		expectPending: "rpc http://rpc.test/api " +
+			"[sum([1,2]) 1/2, get(func) 0/1]",
+		expect: test.Failure,
+	},
+	"invalid version": {
+		setup: func(rpc *RPC) {
+			rpc.Call("sum").Reply(3)
+		},
+		exchanges: []rpcExchange{{
+			body:        `{"jsonrpc":"1.0","method":"sum","id":1}`,
+			expectError: gock.ErrCannotMatch,
+		}, {
+			body:         `{"jsonrpc":"2.0","method":"sum","id":1}`,
+			expectStatus: http.StatusOK,
+			expectBody:   `{"jsonrpc":"2.0","result":3,"id":1}`,
+		}},
+		expect: test.Success,
+	},
+	"invalid json": {
+		setup: func(rpc *RPC) {
+			rpc.Call("sum").Reply(3)
+		},
+		exchanges: []rpcExchange{{
+			body:        `{"jsonrpc":"2.0",`,
+			expectError: gock.ErrCannotMatch,
+		}, {
+			body:        `[null]`,
+			expectError: gock.ErrCannotMatch,
+		}, {
+			body:        `[]`,
+			expectError: gock.ErrCannotMatch,
+		}, {
+			body:         `{"jsonrpc":"2.0","method":"sum","id":1}`,
+			expectStatus: http.StatusOK,
+			expectBody:   `{"jsonrpc":"2.0","result":3,"id":1}`,
+		}},
+		expect: test.Success,
+	},
+	"response error": {
+		setup: func(rpc *RPC) {
+			rpc.Call("sum")
+			rpc.Response().SetError(assert.AnError)
+		},
+		exchanges: []rpcExchange{{
+			body:        `{"jsonrpc":"2.0","method":"sum","id":1}`,
+			expectError: assert.AnError,
+		}},
+		expect: test.Success,
+	},
+	"invalid url": {
+		setup: func(rpc *RPC) {
+			rpc.Call("sum").Reply(3)
+		},
+		exchanges: []rpcExchange{{
+			url:         "http://rpc.test/other",
+			body:        `{"jsonrpc":"2.0","method":"sum","id":1}`,
+			expectError: gock.ErrCannotMatch,
+		}, {
+			body:         `{"jsonrpc":"2.0","method":"sum","id":1}`,
+			expectStatus: http.StatusOK,
+			expectBody:   `{"jsonrpc":"2.0","result":3,"id":1}`,
+		}},
+		expect: test.Success,
+	},
+}
+
+func TestRPC(t *testing.T) {
+	test.Map(t, testRPCParams).
+		Run(func(t test.Test, param RPCParams) {
+			// Given
+			ctrl := NewGock(gomock.NewController(t))
+			rpc := ctrl.RPC(rpcURL)
+			param.setup(rpc)
+			client := &http.Client{}
+			ctrl.InterceptClient(client)
+
+			for _, exchange := range param.exchanges {
+				url := exchange.url
+				if url == "" {
+					url = rpcURL
+				}
+
+				// When
+				response, err := client.Post(url, "application/json",
+					bytes.NewBufferString(exchange.body))
+
+				// Then
+				if exchange.expectError != nil {
+					assert.Equal(t, NewRoundTrippertError(http.MethodPost,
+						url, exchange.expectError), err)
+					continue
+				}
+				require.NoError(t, err)
+				body, err := io.ReadAll(response.Body)
+				require.NoError(t, err)
+				assert.Equal(t, exchange.expectStatus, response.StatusCode)
+				assert.Equal(t, exchange.expectBody, string(body))
+			}
+
+			assert.Empty(t, rpc.replies)
+			if param.expectPending != "" {
+				assert.False(t, ctrl.MockStore.IsDone())
+				assert.Equal(t, param.expectPending, rpc.String())
+			} else {
+				assert.True(t, ctrl.MockStore.IsDone())
+			}
+		})
+}
+
+func TestRPCError(t *testing.T) {
+	// Given
+	err := &RPCError{Code: CodeMethodNotFound, Message: "method not found"}
+
+	// When
+	result := err.Error()
+
+	// Then
+	assert.Equal(t, "rpc error [code=-32601]: method not found", result)
+}
+
+func TestRPCPanic(t *testing.T) {
+	// Given
+	ctrl := NewGock(gomock.NewController(t))
+	call := ctrl.RPC(rpcURL).Call("sum").Times(0)
+
+	_, err := json.Marshal(func() {})
+
+	// When
+	assert.PanicsWithError(t, ErrRPCParams(err).Error(), func() {
+		call.Params(func() {})
+	})
+	assert.PanicsWithError(t, ErrRPCResult(err).Error(), func() {
+		call.Reply(func() {})
+	})
+}
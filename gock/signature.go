@@ -0,0 +1,537 @@
+package gock
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	_ "crypto/sha512" // registers SHA-384 and SHA-512.
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	gock "gopkg.in/h2non/gock.v1"
+)
+
+// DefaultSkew is the default clock skew accepted by the signature matchers.
+const DefaultSkew = 5 * time.Minute
+
+// SignatureOption is an option to set up the signature verification.
+type SignatureOption func(*verifier)
+
+// WithClock sets up the clock providing the current time for the validation
+// of the signing time, e.g. a fake clock returning a fixed time.
+func WithClock(clock func() time.Time) SignatureOption {
+	return func(v *verifier) {
+		v.clock = clock
+	}
+}
+
+// WithSkew sets up the maximum clock skew accepted between the signing time
+// and the current time.
+func WithSkew(skew time.Duration) SignatureOption {
+	return func(v *verifier) {
+		v.skew = skew
+	}
+}
+
+// verifier is the common setup of the signature verification.
+type verifier struct {
+	// scheme the name of the signature scheme.
+	scheme string
+	// clock the clock providing the current time.
+	clock func() time.Time
+	// skew the maximum accepted clock skew.
+	skew time.Duration
+}
+
+// newVerifier creates a new verifier for the given signature scheme applying
+// the given options.
+func newVerifier(scheme string, opts ...SignatureOption) *verifier {
+	v := &verifier{scheme: scheme, clock: time.Now, skew: DefaultSkew}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+// matcher creates a request matcher from the given verification function,
+// that reports verification failures as explaining errors.
+func (v *verifier) matcher(
+	verify func(req *http.Request, body []byte) error,
+) gock.MatchFunc {
+	return func(req *http.Request, _ *gock.Request) (bool, error) {
+		body, err := readBody(req)
+		if err != nil {
+			return false, ErrSignature(v.scheme, err.Error())
+		} else if err := verify(req, body); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+}
+
+// fail creates a signature verification error with given reason.
+func (v *verifier) fail(format string, args ...any) error {
+	return ErrSignature(v.scheme, fmt.Sprintf(format, args...))
+}
+
+// time validates that the given signing time is within the accepted clock
+// skew of the current time.
+func (v *verifier) time(at time.Time) error {
+	now := v.clock()
+	if at.Before(now.Add(-v.skew)) || at.After(now.Add(v.skew)) {
+		return v.fail("clock skew exceeded [time=%s, now=%s, skew=%s]",
+			at.UTC().Format(time.RFC3339), now.UTC().Format(time.RFC3339),
+			v.skew)
+	}
+	return nil
+}
+
+// SigV4 is the setup of the AWS signature version 4 verification.
+type SigV4 struct {
+	// AccessKey the expected access key identifier.
+	AccessKey string
+	// SecretKey the secret access key used to verify the signature.
+	SecretKey string
+	// Region the expected region of the credential scope.
+	Region string
+	// Service the expected service of the credential scope.
+	Service string
+	// SignedHeaders the additional headers required to be signed besides
+	// `host` and `x-amz-date`.
+	SignedHeaders []string
+}
+
+// MatchSigV4 creates a request matcher verifying the AWS signature version 4
+// of the `Authorization` header. It checks the credential scope, the required
+// signed headers, the payload hash, the clock skew of the `X-Amz-Date` header,
+// and the signature. Verification failures are reported as explaining errors.
+func MatchSigV4(setup SigV4, opts ...SignatureOption) gock.MatchFunc {
+	v := newVerifier("sigv4", opts...)
+	return v.matcher(func(req *http.Request, body []byte) error {
+		return v.sigV4(setup, req, body)
+	})
+}
+
+// sigV4 verifies the AWS signature version 4 of the given request.
+func (v *verifier) sigV4(setup SigV4, req *http.Request, body []byte) error {
+	auth := req.Header.Get("Authorization")
+	algorithm, params, _ := strings.Cut(auth, " ")
+	if algorithm != "AWS4-HMAC-SHA256" {
+		return v.fail("invalid algorithm [authorization=%q]", auth)
+	}
+	fields := map[string]string{}
+	for _, param := range strings.Split(params, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+		fields[key] = value
+	}
+
+	scope := strings.SplitN(fields["Credential"], "/", 2)
+	if len(scope) != 2 || scope[0] != setup.AccessKey {
+		return v.fail("invalid access key [credential=%q]",
+			fields["Credential"])
+	}
+	date := req.Header.Get("X-Amz-Date")
+	at, err := time.Parse("20060102T150405Z", date)
+	if err != nil {
+		return v.fail("invalid date [x-amz-date=%q]", date)
+	}
+	expect := strings.Join([]string{
+		date[:8], setup.Region, setup.Service, "aws4_request",
+	}, "/")
+	if scope[1] != expect {
+		return v.fail("invalid credential scope [expect=%s, actual=%s]",
+			expect, scope[1])
+	}
+
+	signed := strings.Split(fields["SignedHeaders"], ";")
+	for _, header := range append([]string{"host", "x-amz-date"},
+		setup.SignedHeaders...) {
+		if !contains(signed, strings.ToLower(header)) {
+			return v.fail("header not signed [header=%s]",
+				strings.ToLower(header))
+		}
+	}
+	if err := v.time(at); err != nil {
+		return err
+	}
+
+	payload := hexSHA256(body)
+	if value := req.Header.Get("X-Amz-Content-Sha256"); value ==
+		"UNSIGNED-PAYLOAD" {
+		payload = value
+	} else if value != "" && value != payload {
+		return v.fail("payload hash mismatch [expect=%s, actual=%s]",
+			payload, value)
+	}
+
+	headers := []string{}
+	for _, header := range signed {
+		value, ok := headerValue(req, header)
+		if !ok {
+			return v.fail("signed header missing [header=%s]", header)
+		}
+		headers = append(headers, header+":"+value+"\n")
+	}
+	canonical := strings.Join([]string{
+		req.Method, escapedPath(req.URL), canonicalQuery(req.URL.Query()),
+		strings.Join(headers, ""), fields["SignedHeaders"], payload,
+	}, "\n")
+	message := strings.Join([]string{
+		"AWS4-HMAC-SHA256", date, scope[1], hexSHA256([]byte(canonical)),
+	}, "\n")
+
+	key := []byte("AWS4" + setup.SecretKey)
+	for _, part := range strings.Split(scope[1], "/") {
+		key = hmacSum(sha256.New, key, []byte(part))
+	}
+	signature := hex.EncodeToString(hmacSum(sha256.New, key, []byte(message)))
+	if !hmac.Equal([]byte(signature), []byte(fields["Signature"])) {
+		return v.fail("signature mismatch [expect=%s, actual=%s]",
+			signature, fields["Signature"])
+	}
+	return nil
+}
+
+// HMAC is the setup of a HMAC request signature verification.
+type HMAC struct {
+	// Header the name of the header carrying the signature.
+	Header string
+	// Prefix the optional prefix of the signature value, e.g. `sha256=`.
+	Prefix string
+	// Key the shared secret key used to verify the signature.
+	Key []byte
+	// Hash the hash function of the HMAC, by default SHA-256.
+	Hash func() hash.Hash
+	// Encode the encoding of the signature, by default hex encoding.
+	Encode func(sum []byte) string
+	// TimestampHeader the optional name of the header carrying the signing
+	// time in unix seconds, that is checked for clock skew.
+	TimestampHeader string
+	// Message the function providing the signed message, by default the
+	// request body prefixed by the timestamp and `.`, if a timestamp header
+	// is set up.
+	Message func(req *http.Request, body []byte) []byte
+}
+
+// MatchHMAC creates a request matcher verifying the HMAC signature of the
+// request in the header given by the setup. It checks the clock skew of the
+// optional timestamp header and the signature of the message. Verification
+// failures are reported as explaining errors.
+func MatchHMAC(setup HMAC, opts ...SignatureOption) gock.MatchFunc {
+	v := newVerifier("hmac", opts...)
+	return v.matcher(func(req *http.Request, body []byte) error {
+		return v.hmac(setup, req, body)
+	})
+}
+
+// hmac verifies the HMAC signature of the given request.
+func (v *verifier) hmac(setup HMAC, req *http.Request, body []byte) error {
+	value := req.Header.Get(setup.Header)
+	if value == "" {
+		return v.fail("signature missing [header=%s]", setup.Header)
+	} else if !strings.HasPrefix(value, setup.Prefix) {
+		return v.fail("invalid signature prefix [header=%s, expect=%s]",
+			setup.Header, setup.Prefix)
+	}
+
+	message := body
+	if setup.TimestampHeader != "" {
+		stamp := req.Header.Get(setup.TimestampHeader)
+		seconds, err := strconv.ParseInt(stamp, 10, 64)
+		if err != nil {
+			return v.fail("invalid timestamp [header=%s, value=%q]",
+				setup.TimestampHeader, stamp)
+		} else if err := v.time(time.Unix(seconds, 0)); err != nil {
+			return err
+		}
+		message = append([]byte(stamp+"."), body...)
+	}
+	if setup.Message != nil {
+		message = setup.Message(req, body)
+	}
+
+	hash, encode := setup.Hash, setup.Encode
+	if hash == nil {
+		hash = sha256.New
+	}
+	if encode == nil {
+		encode = hex.EncodeToString
+	}
+	signature := encode(hmacSum(hash, setup.Key, message))
+	actual := strings.TrimPrefix(value, setup.Prefix)
+	if !hmac.Equal([]byte(signature), []byte(actual)) {
+		return v.fail("signature mismatch [header=%s, expect=%s, actual=%s]",
+			setup.Header, signature, actual)
+	}
+	return nil
+}
+
+// JWT is the setup of a JWT bearer token verification.
+type JWT struct {
+	// Key the key used to verify the token signature, i.e. a `[]byte` for
+	// `HS*`, a `*rsa.PublicKey` for `RS*`, and a `*ecdsa.PublicKey` for
+	// `ES*` algorithms.
+	Key any
+	// Claims the expected claims of the token compared after conversion to
+	// JSON.
+	Claims map[string]any
+}
+
+// MatchJWT creates a request matcher verifying the JWT bearer token of the
+// `Authorization` header. It checks the signature, the expiry, not-before,
+// and issued-at claims considering the clock skew, and the expected claims.
+// Verification failures are reported as explaining errors.
+func MatchJWT(setup JWT, opts ...SignatureOption) gock.MatchFunc {
+	v := newVerifier("jwt", opts...)
+	return v.matcher(func(req *http.Request, _ []byte) error {
+		return v.jwt(setup, req)
+	})
+}
+
+// jwt verifies the JWT bearer token of the given request.
+func (v *verifier) jwt(setup JWT, req *http.Request) error {
+	auth := req.Header.Get("Authorization")
+	token := strings.TrimPrefix(auth, "Bearer ")
+	parts := strings.Split(token, ".")
+	if token == auth || len(parts) != 3 {
+		return v.fail("bearer token missing [authorization=%q]", auth)
+	}
+
+	header := struct {
+		Algorithm string `json:"alg"`
+	}{}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return v.fail("invalid header: %v", err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return v.fail("invalid signature encoding: %v", err)
+	}
+	if err := verifyJWT(header.Algorithm, setup.Key,
+		[]byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return v.fail("signature mismatch [alg=%s]: %v", header.Algorithm, err)
+	}
+
+	claims := map[string]any{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return v.fail("invalid claims: %v", err)
+	}
+	now := v.clock()
+	if exp, ok := claims["exp"].(float64); ok &&
+		now.Add(-v.skew).After(time.Unix(int64(exp), 0)) {
+		return v.fail("token expired [exp=%d]", int64(exp))
+	}
+	if nbf, ok := claims["nbf"].(float64); ok &&
+		now.Add(v.skew).Before(time.Unix(int64(nbf), 0)) {
+		return v.fail("token not yet valid [nbf=%d]", int64(nbf))
+	}
+	if iat, ok := claims["iat"].(float64); ok &&
+		now.Add(v.skew).Before(time.Unix(int64(iat), 0)) {
+		return v.fail("token issued in future [iat=%d]", int64(iat))
+	}
+
+	names := make([]string, 0, len(setup.Claims))
+	for name := range setup.Claims {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		expect, _ := normalize(setup.Claims[name])
+		actual, _ := normalize(claims[name])
+		if !bytes.Equal(expect, actual) {
+			return v.fail("claim mismatch [claim=%s, expect=%s, actual=%s]",
+				name, expect, actual)
+		}
+	}
+	return nil
+}
+
+// verifyJWT verifies the signature of the given signed token content using the
+// given algorithm and key.
+func verifyJWT(algorithm string, key any, content, signature []byte) error {
+	hashes := map[string]crypto.Hash{
+		"256": crypto.SHA256, "384": crypto.SHA384, "512": crypto.SHA512,
+	}
+	if len(algorithm) != 5 || hashes[algorithm[2:]] == 0 {
+		return ErrAlgorithm(algorithm)
+	}
+	hash := hashes[algorithm[2:]]
+
+	switch key := key.(type) {
+	case []byte:
+		if algorithm[:2] != "HS" {
+			return ErrAlgorithm(algorithm)
+		} else if !hmac.Equal(signature, hmacSum(hash.New, key, content)) {
+			return errInvalidSignature
+		}
+		return nil
+	case *rsa.PublicKey:
+		if algorithm[:2] != "RS" {
+			return ErrAlgorithm(algorithm)
+		}
+		return rsa.VerifyPKCS1v15(key, hash, digest(hash, content), signature)
+	case *ecdsa.PublicKey:
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if algorithm[:2] != "ES" {
+			return ErrAlgorithm(algorithm)
+		} else if len(signature) != 2*size {
+			return errInvalidSignature
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(key, digest(hash, content), r, s) {
+			return errInvalidSignature
+		}
+		return nil
+	default:
+		return ErrKeyType(key)
+	}
+}
+
+// errInvalidSignature is the error reported for invalid token signatures.
+var errInvalidSignature = errors.New("invalid signature")
+
+// readBody reads the body of the given request and restores it for further
+// use.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return []byte{}, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// headerValue returns the canonical value of the header with given lower case
+// name of the given request and whether the header exists.
+func headerValue(req *http.Request, name string) (string, bool) {
+	switch name {
+	case "host":
+		if req.Host != "" {
+			return req.Host, true
+		}
+		return req.URL.Host, req.URL.Host != ""
+	case "content-length":
+		if req.Header.Get("Content-Length") == "" && req.ContentLength >= 0 {
+			return strconv.FormatInt(req.ContentLength, 10), true
+		}
+	}
+
+	values := []string{}
+	for _, value := range req.Header.Values(name) {
+		values = append(values, strings.Join(strings.Fields(value), " "))
+	}
+	return strings.Join(values, ","), len(values) != 0
+}
+
+// escapedPath returns the canonical escaped path of the given URL.
+func escapedPath(url *url.URL) string {
+	if path := url.EscapedPath(); path != "" {
+		return path
+	}
+	return "/"
+}
+
+// canonicalQuery returns the canonical query string of the given query values
+// sorted by key and value using strict URI encoding.
+func canonicalQuery(query url.Values) string {
+	params := []string{}
+	for key, values := range query {
+		for _, value := range values {
+			params = append(params, escape(key)+"="+escape(value))
+		}
+	}
+	sort.Strings(params)
+	return strings.Join(params, "&")
+}
+
+// escape escapes the given string using strict URI encoding, i.e. encoding all
+// characters except the unreserved characters.
+func escape(value string) string {
+	builder := strings.Builder{}
+	for _, char := range []byte(value) {
+		if ('A' <= char && char <= 'Z') || ('a' <= char && char <= 'z') ||
+			('0' <= char && char <= '9') || strings.IndexByte("-_.~", char) >= 0 {
+			builder.WriteByte(char)
+		} else {
+			fmt.Fprintf(&builder, "%%%02X", char)
+		}
+	}
+	return builder.String()
+}
+
+// contains returns whether the given values contain the given value.
+func contains(values []string, value string) bool {
+	for _, actual := range values {
+		if actual == value {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeSegment decodes the given base64 URL encoded JSON token segment into
+// the given value.
+func decodeSegment(segment string, value any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, value)
+}
+
+// digest returns the digest of the given data using the given hash.
+func digest(hash crypto.Hash, data []byte) []byte {
+	h := hash.New()
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// hmacSum returns the HMAC of the given data using the given hash and key.
+func hmacSum(hash func() hash.Hash, key, data []byte) []byte {
+	mac := hmac.New(hash, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// hexSHA256 returns the hex encoded SHA-256 hash of the given data.
+func hexSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// ErrSignature creates an error reporting a failed signature verification of
+// the given scheme with given reason.
+func ErrSignature(scheme, reason string) error {
+	return fmt.Errorf("signature verification failed [scheme=%s]: %s",
+		scheme, reason)
+}
+
+// ErrAlgorithm creates an error reporting an unsupported or mismatching token
+// signature algorithm.
+func ErrAlgorithm(algorithm string) error {
+	return fmt.Errorf("unsupported algorithm [alg=%s]", algorithm)
+}
+
+// ErrKeyType creates an error reporting an unsupported verification key type.
+func ErrKeyType(key any) error {
+	return fmt.Errorf("unsupported key type [type=%T]", key)
+}
@@ -0,0 +1,489 @@
+package gock
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+
+	"github.com/tkrop/go-testing/test"
+)
+
+const (
+	// sigV4Auth is the authorization header of the AWS signature version 4
+	// example request.
+	sigV4Auth = "AWS4-HMAC-SHA256 " +
+		"Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, " +
+		"SignedHeaders=content-type;host;x-amz-date, " +
+		"Signature=" + sigV4Signature
+	// sigV4Signature is the signature of the AWS signature version 4 example
+	// request.
+	sigV4Signature = "5d672d79c15b13162d9279b0855cfba6" +
+		"789a8edb4c82c400e06b5924a6f2b5d7"
+	// emptyHash is the SHA-256 hash of the empty payload.
+	emptyHash = "e3b0c44298fc1c149afbf4c8996fb924" +
+		"27ae41e4649b934ca495991b7852b855"
+)
+
+var (
+	// sigV4Time is the signing time of the AWS signature version 4 example.
+	sigV4Time = time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+	// sigV4Setup is the setup of the AWS signature version 4 example.
+	sigV4Setup = SigV4{
+		AccessKey: "AKIDEXAMPLE",
+		SecretKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		Region:    "us-east-1", Service: "iam",
+	}
+
+	// rsaKey is the RSA key used to sign tokens.
+	rsaKey, _ = rsa.GenerateKey(rand.Reader, 2048)
+	// ecdsaKey is the ECDSA key used to sign tokens.
+	ecdsaKey, _ = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	// hmacKey is the shared key used to sign tokens and messages.
+	hmacKey = []byte("secret")
+)
+
+// sigV4Request creates the AWS signature version 4 example request modified
+// by the given functions.
+func sigV4Request(modify ...func(req *http.Request)) *http.Request {
+	req, _ := http.NewRequest(http.MethodGet, "https://iam.amazonaws.com/"+
+		"?Action=ListUsers&Version=2010-05-08", nil)
+	req.Header.Set("Content-Type",
+		"application/x-www-form-urlencoded; charset=utf-8")
+	req.Header.Set("X-Amz-Date", "20150830T123600Z")
+	req.Header.Set("Authorization", sigV4Auth)
+	for _, modify := range modify {
+		modify(req)
+	}
+	return req
+}
+
+// setHeader creates a function setting the header with given name and value.
+func setHeader(name, value string) func(req *http.Request) {
+	return func(req *http.Request) {
+		req.Header.Set(name, value)
+	}
+}
+
+// hmacRequest creates a request with given body signed by the HMAC of the
+// given message.
+func hmacRequest(body, stamp, message string) *http.Request {
+	req, _ := http.NewRequest(http.MethodPost, "http://hook.test/event",
+		strings.NewReader(body))
+	mac := hmac.New(sha256.New, hmacKey)
+	mac.Write([]byte(message))
+	req.Header.Set("X-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	if stamp != "" {
+		req.Header.Set("X-Timestamp", stamp)
+	}
+	return req
+}
+
+// token creates a JWT signed with the given algorithm and key containing the
+// given claims.
+func token(algorithm string, key any, claims map[string]any) string {
+	header, _ := json.Marshal(map[string]string{"alg": algorithm})
+	payload, _ := json.Marshal(claims)
+	content := base64.RawURLEncoding.EncodeToString(header) + "." +
+		base64.RawURLEncoding.EncodeToString(payload)
+	sum := sha256.Sum256([]byte(content))
+
+	var signature []byte
+	switch key := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(content))
+		signature = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		signature, _ = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, sum[:])
+	case *ecdsa.PrivateKey:
+		r, s, _ := ecdsa.Sign(rand.Reader, key, sum[:])
+		signature = append(r.FillBytes(make([]byte, 32)),
+			s.FillBytes(make([]byte, 32))...)
+	}
+	return content + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// jwtRequest creates a request with the given authorization header.
+func jwtRequest(auth string) *http.Request {
+	req, _ := http.NewRequest(http.MethodGet, "http://api.test/user", nil)
+	if auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+	return req
+}
+
+// fixedClock returns a fake clock always returning the given time.
+func fixedClock(at time.Time) func() time.Time {
+	return func() time.Time { return at }
+}
+
+type SignatureParams struct {
+	match       gock.MatchFunc
+	request     *http.Request
+	expectError error
+}
+
+var testSignatureParams = map[string]SignatureParams{
+	"sigv4 valid": {
+		match:   MatchSigV4(sigV4Setup, WithClock(fixedClock(sigV4Time))),
+		request: sigV4Request(),
+	},
+	"sigv4 valid with skew": {
+		match: MatchSigV4(sigV4Setup, WithSkew(time.Hour),
+			WithClock(fixedClock(sigV4Time.Add(time.Hour)))),
+		request: sigV4Request(),
+	},
+	"sigv4 valid with payload hash": {
+		match: MatchSigV4(SigV4{
+			AccessKey: sigV4Setup.AccessKey, SecretKey: sigV4Setup.SecretKey,
+			Region: sigV4Setup.Region, Service: sigV4Setup.Service,
+			SignedHeaders: []string{"Content-Type"},
+		}, WithClock(fixedClock(sigV4Time))),
+		request: sigV4Request(setHeader("X-Amz-Content-Sha256", emptyHash)),
+	},
+	"sigv4 invalid algorithm": {
+		match: MatchSigV4(sigV4Setup, WithClock(fixedClock(sigV4Time))),
+		request: sigV4Request(setHeader("Authorization",
+			"AWS4-HMAC-SHA1 Credential=x")),
+		expectError: ErrSignature("sigv4", `invalid algorithm `+
+			`[authorization="AWS4-HMAC-SHA1 Credential=x"]`),
+	},
+	"sigv4 invalid access key": {
+		match: MatchSigV4(SigV4{AccessKey: "other"},
+			WithClock(fixedClock(sigV4Time))),
+		request: sigV4Request(),
+		expectError: ErrSignature("sigv4", `invalid access key [credential=`+
+			`"AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request"]`),
+	},
+	"sigv4 invalid date": {
+		match:   MatchSigV4(sigV4Setup, WithClock(fixedClock(sigV4Time))),
+		request: sigV4Request(setHeader("X-Amz-Date", "2015-08-30")),
+		expectError: ErrSignature("sigv4",
+			`invalid date [x-amz-date="2015-08-30"]`),
+	},
+	"sigv4 invalid scope": {
+		match: MatchSigV4(SigV4{
+			AccessKey: sigV4Setup.AccessKey, SecretKey: sigV4Setup.SecretKey,
+			Region: "eu-central-1", Service: sigV4Setup.Service,
+		}, WithClock(fixedClock(sigV4Time))),
+		request: sigV4Request(),
+		expectError: ErrSignature("sigv4", "invalid credential scope "+
+			"[expect=20150830/eu-central-1/iam/aws4_request, "+
+			"actual=20150830/us-east-1/iam/aws4_request]"),
+	},
+	"sigv4 header not signed": {
+		match: MatchSigV4(SigV4{
+			AccessKey: sigV4Setup.AccessKey, SecretKey: sigV4Setup.SecretKey,
+			Region: sigV4Setup.Region, Service: sigV4Setup.Service,
+			SignedHeaders: []string{"X-Amz-Content-Sha256"},
+		}, WithClock(fixedClock(sigV4Time))),
+		request: sigV4Request(),
+		expectError: ErrSignature("sigv4",
+			"header not signed [header=x-amz-content-sha256]"),
+	},
+	"sigv4 clock skew exceeded": {
+		match: MatchSigV4(sigV4Setup,
+			WithClock(fixedClock(sigV4Time.Add(-6*time.Minute)))),
+		request: sigV4Request(),
+		expectError: ErrSignature("sigv4", "clock skew exceeded "+
+			"[time=2015-08-30T12:36:00Z, now=2015-08-30T12:30:00Z, "+
+			"skew=5m0s]"),
+	},
+	"sigv4 payload hash mismatch": {
+		match:   MatchSigV4(sigV4Setup, WithClock(fixedClock(sigV4Time))),
+		request: sigV4Request(setHeader("X-Amz-Content-Sha256", "0000")),
+		expectError: ErrSignature("sigv4", "payload hash mismatch "+
+			"[expect="+emptyHash+", actual=0000]"),
+	},
+	"sigv4 signed header missing": {
+		match: MatchSigV4(sigV4Setup, WithClock(fixedClock(sigV4Time))),
+		request: sigV4Request(func(req *http.Request) {
+			req.Header.Del("Content-Type")
+		}),
+		expectError: ErrSignature("sigv4",
+			"signed header missing [header=content-type]"),
+	},
+	"sigv4 signature mismatch": {
+		match: MatchSigV4(sigV4Setup, WithClock(fixedClock(sigV4Time))),
+		request: sigV4Request(setHeader("X-Amz-Content-Sha256",
+			"UNSIGNED-PAYLOAD")),
+		expectError: ErrSignature("sigv4", "signature mismatch "+
+			"[expect=86116edbb7e0e8c675dc9cfa9fcfcd01"+
+			"15a98ee9cc1196e4623cd2673a806766, actual="+sigV4Signature+"]"),
+	},
+	"sigv4 modified request": {
+		match: MatchSigV4(sigV4Setup, WithClock(fixedClock(sigV4Time))),
+		request: sigV4Request(func(req *http.Request) {
+			req.URL.RawQuery = "Action=ListUsers&Version=2010-05-09"
+		}),
+		expectError: ErrSignature("sigv4", "signature mismatch "+
+			"[expect=24082efea19c48713058c41de23d796e"+
+			"19e40039920d385963f1c879ef9ef373, actual="+sigV4Signature+"]"),
+	},
+
+	"hmac valid": {
+		match: MatchHMAC(HMAC{
+			Header: "X-Signature", Prefix: "sha256=", Key: hmacKey,
+		}),
+		request: hmacRequest(`{"event":"push"}`, "", `{"event":"push"}`),
+	},
+	"hmac valid with timestamp": {
+		match: MatchHMAC(HMAC{
+			Header: "X-Signature", Prefix: "sha256=", Key: hmacKey,
+			TimestampHeader: "X-Timestamp",
+		}, WithClock(fixedClock(time.Unix(1000, 0)))),
+		request: hmacRequest(`{"event":"push"}`, "1000",
+			`1000.{"event":"push"}`),
+	},
+	"hmac valid with message": {
+		match: MatchHMAC(HMAC{
+			Header: "X-Signature", Prefix: "sha256=", Key: hmacKey,
+			Message: func(req *http.Request, body []byte) []byte {
+				return append([]byte(req.Method+" "+req.URL.Path+"\n"),
+					body...)
+			},
+			Encode: hex.EncodeToString, Hash: sha256.New,
+		}),
+		request: hmacRequest(`{}`, "", "POST /event\n{}"),
+	},
+	"hmac signature missing": {
+		match:   MatchHMAC(HMAC{Header: "X-Other", Key: hmacKey}),
+		request: hmacRequest(`{}`, "", `{}`),
+		expectError: ErrSignature("hmac",
+			"signature missing [header=X-Other]"),
+	},
+	"hmac invalid prefix": {
+		match: MatchHMAC(HMAC{
+			Header: "X-Signature", Prefix: "sha1=", Key: hmacKey,
+		}),
+		request: hmacRequest(`{}`, "", `{}`),
+		expectError: ErrSignature("hmac", "invalid signature prefix "+
+			"[header=X-Signature, expect=sha1=]"),
+	},
+	"hmac invalid timestamp": {
+		match: MatchHMAC(HMAC{
+			Header: "X-Signature", Prefix: "sha256=", Key: hmacKey,
+			TimestampHeader: "X-Timestamp",
+		}),
+		request: hmacRequest(`{}`, "now", `now.{}`),
+		expectError: ErrSignature("hmac", "invalid timestamp "+
+			`[header=X-Timestamp, value="now"]`),
+	},
+	"hmac clock skew exceeded": {
+		match: MatchHMAC(HMAC{
+			Header: "X-Signature", Prefix: "sha256=", Key: hmacKey,
+			TimestampHeader: "X-Timestamp",
+		}, WithClock(fixedClock(time.Unix(1000, 0)))),
+		request: hmacRequest(`{}`, "1400", `1400.{}`),
+		expectError: ErrSignature("hmac", "clock skew exceeded "+
+			"[time=1970-01-01T00:23:20Z, now=1970-01-01T00:16:40Z, "+
+			"skew=5m0s]"),
+	},
+	"hmac signature mismatch": {
+		match: MatchHMAC(HMAC{
+			Header: "X-Signature", Prefix: "sha256=", Key: []byte("other"),
+			Encode: base64.StdEncoding.EncodeToString,
+		}),
+		request: hmacRequest(`{}`, "", `{}`),
+		expectError: ErrSignature("hmac", "signature mismatch "+
+			"[header=X-Signature, "+
+			"expect=Mt8k408OnssTf6QyCrDVC8wwhD2IAA8TYHSLYUS06OU=, "+
+			"actual=77325902caca812dc259733aacd046b7"+
+			"3817372c777b8d95b402647474516e13]"),
+	},
+
+	"jwt valid hs256": {
+		match: MatchJWT(JWT{
+			Key: hmacKey, Claims: map[string]any{"sub": "user", "n": 1},
+		}),
+		request: jwtRequest("Bearer " + token("HS256", hmacKey,
+			map[string]any{"sub": "user", "n": 1, "other": true})),
+	},
+	"jwt valid rs256": {
+		match: MatchJWT(JWT{
+			Key: &rsaKey.PublicKey, Claims: map[string]any{"sub": "user"},
+		}, WithClock(fixedClock(time.Unix(1000, 0)))),
+		request: jwtRequest("Bearer " + token("RS256", rsaKey,
+			map[string]any{
+				"sub": "user", "exp": 1100, "nbf": 900, "iat": 900,
+			})),
+	},
+	"jwt valid es256": {
+		match: MatchJWT(JWT{Key: &ecdsaKey.PublicKey}),
+		request: jwtRequest("Bearer " + token("ES256", ecdsaKey,
+			map[string]any{"sub": "user"})),
+	},
+	"jwt token missing": {
+		match:   MatchJWT(JWT{Key: hmacKey}),
+		request: jwtRequest("Basic dXNlcjpwYXNz"),
+		expectError: ErrSignature("jwt", "bearer token missing "+
+			`[authorization="Basic dXNlcjpwYXNz"]`),
+	},
+	"jwt invalid header": {
+		match:   MatchJWT(JWT{Key: hmacKey}),
+		request: jwtRequest("Bearer e30.e30.e30"),
+		expectError: ErrSignature("jwt", "signature mismatch [alg=]: "+
+			ErrAlgorithm("").Error()),
+	},
+	"jwt invalid header encoding": {
+		match:   MatchJWT(JWT{Key: hmacKey}),
+		request: jwtRequest("Bearer !.e30.e30"),
+		expectError: ErrSignature("jwt", "invalid header: "+
+			"illegal base64 data at input byte 0"),
+	},
+	"jwt invalid signature encoding": {
+		match:   MatchJWT(JWT{Key: hmacKey}),
+		request: jwtRequest("Bearer e30.e30.!"),
+		expectError: ErrSignature("jwt", "invalid signature encoding: "+
+			"illegal base64 data at input byte 0"),
+	},
+	"jwt invalid claims": {
+		match: MatchJWT(JWT{Key: hmacKey}),
+		request: jwtRequest("Bearer " + func() string {
+			parts := strings.Split(token("HS256", hmacKey, nil), ".")
+			content := parts[0] + ".W10"
+			mac := hmac.New(sha256.New, hmacKey)
+			mac.Write([]byte(content))
+			return content + "." +
+				base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+		}()),
+		expectError: ErrSignature("jwt", "invalid claims: json: cannot "+
+			"unmarshal array into Go value of type map[string]interface {}"),
+	},
+	"jwt wrong key": {
+		match: MatchJWT(JWT{Key: []byte("other")}),
+		request: jwtRequest("Bearer " + token("HS256", hmacKey,
+			map[string]any{"sub": "user"})),
+		expectError: ErrSignature("jwt",
+			"signature mismatch [alg=HS256]: invalid signature"),
+	},
+	"jwt wrong algorithm": {
+		match: MatchJWT(JWT{Key: &rsaKey.PublicKey}),
+		request: jwtRequest("Bearer " + token("HS256", hmacKey,
+			map[string]any{"sub": "user"})),
+		expectError: ErrSignature("jwt", "signature mismatch [alg=HS256]: "+
+			ErrAlgorithm("HS256").Error()),
+	},
+	"jwt wrong ecdsa signature": {
+		match: MatchJWT(JWT{Key: &ecdsaKey.PublicKey}),
+		request: jwtRequest("Bearer " + token("ES256", hmacKey,
+			map[string]any{"sub": "user"})),
+		expectError: ErrSignature("jwt",
+			"signature mismatch [alg=ES256]: invalid signature"),
+	},
+	"jwt wrong rsa signature": {
+		match: MatchJWT(JWT{Key: &rsaKey.PublicKey}),
+		request: jwtRequest("Bearer " + token("RS256", hmacKey,
+			map[string]any{"sub": "user"})),
+		expectError: ErrSignature("jwt", "signature mismatch [alg=RS256]: "+
+			rsa.ErrVerification.Error()),
+	},
+	"jwt unsupported key type": {
+		match: MatchJWT(JWT{Key: "secret"}),
+		request: jwtRequest("Bearer " + token("HS256", hmacKey,
+			map[string]any{"sub": "user"})),
+		expectError: ErrSignature("jwt", "signature mismatch [alg=HS256]: "+
+			ErrKeyType("secret").Error()),
+	},
+	"jwt token expired": {
+		match: MatchJWT(JWT{Key: hmacKey},
+			WithClock(fixedClock(time.Unix(1400, 0)))),
+		request: jwtRequest("Bearer " + token("HS256", hmacKey,
+			map[string]any{"exp": 1000})),
+		expectError: ErrSignature("jwt", "token expired [exp=1000]"),
+	},
+	"jwt token not yet valid": {
+		match: MatchJWT(JWT{Key: hmacKey},
+			WithClock(fixedClock(time.Unix(1000, 0)))),
+		request: jwtRequest("Bearer " + token("HS256", hmacKey,
+			map[string]any{"nbf": 1400})),
+		expectError: ErrSignature("jwt", "token not yet valid [nbf=1400]"),
+	},
+	"jwt token issued in future": {
+		match: MatchJWT(JWT{Key: hmacKey}, WithSkew(time.Second),
+			WithClock(fixedClock(time.Unix(1000, 0)))),
+		request: jwtRequest("Bearer " + token("HS256", hmacKey,
+			map[string]any{"iat": 1002})),
+		expectError: ErrSignature("jwt", "token issued in future [iat=1002]"),
+	},
+	"jwt claim mismatch": {
+		match: MatchJWT(JWT{
+			Key: hmacKey, Claims: map[string]any{
+				"aud": []string{"api"}, "sub": "admin",
+			},
+		}),
+		request: jwtRequest("Bearer " + token("HS256", hmacKey,
+			map[string]any{"aud": []string{"api"}, "sub": "user"})),
+		expectError: ErrSignature("jwt", "claim mismatch "+
+			`[claim=sub, expect="admin", actual="user"]`),
+	},
+}
+
+func TestSignature(t *testing.T) {
+	test.Map(t, testSignatureParams).
+		Run(func(t test.Test, param SignatureParams) {
+			// When
+			matches, err := param.match(param.request, nil)
+
+			// Then
+			assert.Equal(t, param.expectError, err)
+			assert.Equal(t, param.expectError == nil, matches)
+		})
+}
+
+func TestSignatureReadError(t *testing.T) {
+	// Given
+	match := MatchHMAC(HMAC{Header: "X-Signature", Key: hmacKey})
+	req := hmacRequest("", "", "")
+	req.Body = errReader{}
+
+	// When
+	matches, err := match(req, nil)
+
+	// Then
+	assert.False(t, matches)
+	assert.Equal(t, ErrSignature("hmac", assert.AnError.Error()), err)
+}
+
+// errReader is a request body failing on read.
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) { return 0, assert.AnError }
+func (errReader) Close() error             { return nil }
+
+func TestSignatureController(t *testing.T) {
+	// Given
+	ctrl := NewGock(gomock.NewController(t))
+	ctrl.New("http://hook.test").Post("/event").
+		AddMatcher(MatchHMAC(HMAC{
+			Header: "X-Signature", Prefix: "sha256=", Key: hmacKey,
+		})).Reply(http.StatusAccepted)
+	client := &http.Client{}
+	ctrl.InterceptClient(client)
+
+	// When
+	_, errInvalid := client.Do(hmacRequest(`{}`, "", `{"other":true}`))
+	response, err := client.Do(hmacRequest(`{}`, "", `{}`))
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, response.StatusCode)
+	assert.ErrorContains(t, errInvalid, "signature verification failed "+
+		"[scheme=hmac]: signature mismatch [header=X-Signature")
+}
@@ -0,0 +1,250 @@
+// Package harness contains a small component test harness that builds and
+// tears down a component graph, where each dependency of the component under
+// test can be bound to a mock, a fake, or a stand-in server per test case. It
+// is part of the public interface, however, we are still experimenting to
+// optimize the interface and the user experience.
+package harness
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	gosync "sync"
+
+	"github.com/tkrop/go-testing/gock"
+	"github.com/tkrop/go-testing/internal/reflect"
+	"github.com/tkrop/go-testing/mock"
+	"github.com/tkrop/go-testing/test"
+)
+
+// Harness is a component test harness wiring the component under test with
+// its dependencies. The dependencies are bound by type to providers that are
+// resolved lazily and only once per harness, i.e. per test case.
+type Harness struct {
+	// state the state shared by the harness and the views passed to the
+	// providers.
+	*state
+	// resolving the dependencies resolved by the call chain of the providers
+	// receiving this harness view to detect cycles.
+	resolving []reflect.Type
+}
+
+// state is the state of a harness shared by all harness views.
+type state struct {
+	// t the attached test context.
+	t test.Test
+	// mocks the mock handler shared by all mocks and the gock controller.
+	mocks *mock.Mocks
+
+	// mu the mutex to protect the bindings and cleanups.
+	mu gosync.Mutex
+	// providers the providers of the dependencies by type.
+	providers map[reflect.Type]func(*Harness) any
+	// instances the resolved dependencies by type.
+	instances map[reflect.Type]any
+	// cleanups the cleanup functions in order of registration.
+	cleanups []func() error
+	// dir the lazily created temporary directory.
+	dir string
+}
+
+// New creates a new component test harness for the given test. The harness
+// creates a mock handler for the test and registers its cleanup with the
+// test, if the test supports cleanups.
+func New(t test.Test) *Harness {
+	h := &Harness{state: &state{
+		t:         t,
+		mocks:     mock.NewMock(t),
+		providers: map[reflect.Type]func(*Harness) any{},
+		instances: map[reflect.Type]any{},
+	}}
+	if c, ok := t.(test.Cleanuper); ok {
+		c.Cleanup(h.cleanup)
+	}
+	return h
+}
+
+// Expect configures the harness to expect the given mock setup. The setup may
+// combine `gomock` mock calls and HTTP request/response mocks of the gock
+// controller requested via `mock.Get(mocks, gock.NewGock)`.
+func (h *Harness) Expect(setup mock.SetupFunc) *Harness {
+	h.mocks.Expect(setup)
+	return h
+}
+
+// Mocks returns the mock handler of the harness.
+func (h *Harness) Mocks() *mock.Mocks {
+	return h.mocks
+}
+
+// Gock returns the HTTP request/response mock controller of the harness that
+// is shared with the mock setups.
+func (h *Harness) Gock() *gock.Controller {
+	return mock.Get(h.mocks, gock.NewGock)
+}
+
+// TempDir returns a temporary directory that is created on first request and
+// removed when the harness is torn down.
+func (h *Harness) TempDir() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.dir == "" {
+		dir, err := os.MkdirTemp("", "harness-")
+		if err != nil {
+			panic(err)
+		}
+		h.dir = dir
+		h.cleanups = append(h.cleanups, func() error {
+			return os.RemoveAll(dir)
+		})
+	}
+	return h.dir
+}
+
+// Cleanup registers the given cleanup function to tear down a dependency,
+// e.g. to close a stand-in server. The cleanup functions are called in
+// reverse order of registration when the test finishes. All errors are
+// collected and reported together.
+func (h *Harness) Cleanup(cleanup func() error) *Harness {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.cleanups = append(h.cleanups, cleanup)
+	return h
+}
+
+// cleanup calls the registered cleanup functions in reverse order and reports
+// the collected errors as a single combined failure.
+func (h *Harness) cleanup() {
+	h.mu.Lock()
+	cleanups := h.cleanups
+	h.cleanups = nil
+	h.mu.Unlock()
+
+	errs := []error{}
+	for index := len(cleanups) - 1; index >= 0; index-- {
+		if err := cleanups[index](); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) != 0 {
+		h.t.Errorf("%v", ErrCleanup(errs))
+	}
+}
+
+// Bind binds the dependency of type `T` to the given provider function. The
+// provider is called lazily on the first request of the dependency and may
+// resolve further dependencies via `Get`. An existing binding is replaced,
+// allowing test cases to override default bindings.
+func Bind[T any](h *Harness, provider func(h *Harness) T) *Harness {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	dtype := typeOf[T]()
+	h.providers[dtype] = func(h *Harness) any {
+		return provider(h)
+	}
+	delete(h.instances, dtype)
+	return h
+}
+
+// BindValue binds the dependency of type `T` to the given value, e.g. a fake
+// or a stand-in server client.
+func BindValue[T any](h *Harness, value T) *Harness {
+	return Bind(h, func(*Harness) T { return value })
+}
+
+// BindMock binds the dependency of type `T` to the singleton mock created by
+// the given `gomock` constructor, e.g. `NewMockRepo`. The mock must implement
+// the dependency type `T`.
+func BindMock[T any, M any](
+	h *Harness, creator func(*mock.Controller) *M,
+) *Harness {
+	return Bind(h, func(h *Harness) T {
+		mock := mock.Get(h.mocks, creator)
+		if dep, ok := any(mock).(T); ok {
+			return dep
+		}
+		panic(ErrMockType(typeOf[T](), reflect.TypeOf(mock)))
+	})
+}
+
+// Get resolves the dependency of type `T` using the bound provider. The
+// dependency is created once per harness and reused on further requests. The
+// provider receives a harness view tracking the call chain of the resolution,
+// so that concurrent requests of the same dependency are not mistaken as
+// cycles. It panics if the dependency is not bound or has a cyclic dependency.
+func Get[T any](h *Harness) T {
+	dtype := typeOf[T]()
+
+	h.mu.Lock()
+	if instance, ok := h.instances[dtype]; ok {
+		h.mu.Unlock()
+		value, _ := instance.(T)
+		return value
+	}
+	provider, ok := h.providers[dtype]
+	if !ok {
+		h.mu.Unlock()
+		panic(ErrUnbound(dtype))
+	}
+	h.mu.Unlock()
+
+	resolving := append(append([]reflect.Type{}, h.resolving...), dtype)
+	for _, rtype := range h.resolving {
+		if rtype == dtype {
+			panic(ErrCycle(resolving))
+		}
+	}
+	instance := provider(&Harness{state: h.state, resolving: resolving})
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if actual, ok := h.instances[dtype]; ok {
+		// Keep the instance resolved by a concurrent request.
+		instance = actual
+	} else {
+		h.instances[dtype] = instance
+	}
+	value, _ := instance.(T)
+	return value
+}
+
+// typeOf returns the reflection type of the given type parameter.
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// ErrUnbound creates an error reporting a dependency without binding.
+func ErrUnbound(dtype reflect.Type) error {
+	return fmt.Errorf("dependency not bound [type=%v]", dtype)
+}
+
+// ErrCycle creates an error reporting a cyclic dependency.
+func ErrCycle(cycle []reflect.Type) error {
+	names := make([]string, 0, len(cycle))
+	for _, dtype := range cycle {
+		names = append(names, dtype.String())
+	}
+	return fmt.Errorf("dependency cycle [%s]", strings.Join(names, " -> "))
+}
+
+// ErrMockType creates an error reporting a mock not implementing the type of
+// the dependency it is bound to.
+func ErrMockType(dtype, mtype reflect.Type) error {
+	return fmt.Errorf("mock not implementing dependency [type=%v, mock=%v]",
+		dtype, mtype)
+}
+
+// ErrCleanup creates an error reporting the combined errors of the cleanup
+// functions.
+func ErrCleanup(errs []error) error {
+	msgs := make([]string, 0, len(errs))
+	for _, err := range errs {
+		msgs = append(msgs, err.Error())
+	}
+	return errors.New("harness cleanup failed:\n\t" +
+		strings.Join(msgs, "\n\t"))
+}
@@ -0,0 +1,345 @@
+package harness_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	gosync "sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tkrop/go-testing/gock"
+	"github.com/tkrop/go-testing/harness"
+	"github.com/tkrop/go-testing/mock"
+	"github.com/tkrop/go-testing/test"
+)
+
+//go:generate mockgen -package=harness_test -destination=mock_iface_test.go -source=harness_test.go  Repo
+
+// Repo is the repository dependency of the service under test.
+type Repo interface {
+	Load(key string) (string, error)
+}
+
+// Clock is the clock dependency of the service under test.
+type Clock interface {
+	Now() time.Time
+}
+
+// FakeClock is a fake clock returning a fixed time.
+type FakeClock struct {
+	now time.Time
+}
+
+// Now returns the fixed time of the fake clock.
+func (c *FakeClock) Now() time.Time {
+	return c.now
+}
+
+// Service is the component under test.
+type Service struct {
+	repo   Repo
+	clock  Clock
+	client *http.Client
+	dir    string
+}
+
+// Fetch fetches the remote value of the given key and stores it in a file.
+func (s *Service) Fetch(key string) (string, error) {
+	value, err := s.repo.Load(key)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := s.client.Get("http://api.test/" + value)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	result := string(body) + "@" + s.clock.Now().Format(time.RFC3339)
+	return result, os.WriteFile(filepath.Join(s.dir, key), []byte(result), 0o600)
+}
+
+// now is the fixed time of the fake clock.
+var now = time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// setupService sets up the default bindings of the service under test.
+func setupService(h *harness.Harness) *harness.Harness {
+	harness.BindMock[Repo](h, NewMockRepo)
+	harness.BindValue[Clock](h, &FakeClock{now: now})
+	harness.Bind(h, func(h *harness.Harness) *http.Client {
+		client := &http.Client{}
+		h.Gock().InterceptClient(client)
+		return client
+	})
+	return harness.Bind(h, func(h *harness.Harness) *Service {
+		return &Service{
+			repo:   harness.Get[Repo](h),
+			clock:  harness.Get[Clock](h),
+			client: harness.Get[*http.Client](h),
+			dir:    h.TempDir(),
+		}
+	})
+}
+
+// Load creates a mock setup for loading the given key.
+func Load(key, value string, err error) mock.SetupFunc {
+	return func(mocks *mock.Mocks) any {
+		return mock.Get(mocks, NewMockRepo).EXPECT().Load(key).
+			DoAndReturn(mocks.Return(Repo.Load, value, err))
+	}
+}
+
+// Fetch creates a HTTP request/response mock setup for the given value.
+func Fetch(value, body string) mock.SetupFunc {
+	return func(mocks *mock.Mocks) any {
+		mock.Get(mocks, gock.NewGock).New("http://api.test").
+			Get("/" + value).Reply(http.StatusOK).BodyString(body)
+		return nil
+	}
+}
+
+type HarnessParams struct {
+	setup        mock.SetupFunc
+	bind         func(h *harness.Harness)
+	key          string
+	expectResult string
+	expectError  error
+}
+
+var testHarnessParams = map[string]HarnessParams{
+	"fetch value": {
+		setup: mock.Chain(
+			Load("key", "value", nil),
+			Fetch("value", "body"),
+		),
+		key:          "key",
+		expectResult: "body@2022-01-02T03:04:05Z",
+	},
+	"fetch with other clock": {
+		setup: mock.Chain(
+			Load("key", "value", nil),
+			Fetch("value", "body"),
+		),
+		bind: func(h *harness.Harness) {
+			harness.BindValue[Clock](h, &FakeClock{now: time.Time{}})
+		},
+		key:          "key",
+		expectResult: "body@0001-01-01T00:00:00Z",
+	},
+	"load failing": {
+		setup:       Load("key", "", assert.AnError),
+		key:         "key",
+		expectError: assert.AnError,
+	},
+}
+
+func TestHarness(t *testing.T) {
+	test.Map(t, testHarnessParams).
+		Run(func(t test.Test, param HarnessParams) {
+			// Given
+			h := setupService(harness.New(t)).Expect(param.setup)
+			if param.bind != nil {
+				param.bind(h)
+			}
+			service := harness.Get[*Service](h)
+
+			// When
+			result, err := service.Fetch(param.key)
+
+			// Then
+			assert.Equal(t, param.expectError, err)
+			assert.Equal(t, param.expectResult, result)
+			assert.Same(t, service, harness.Get[*Service](h))
+			if param.expectError == nil {
+				content, err := os.ReadFile(filepath.Join(h.TempDir(), param.key))
+				require.NoError(t, err)
+				assert.Equal(t, param.expectResult, string(content))
+			}
+		})
+}
+
+type HarnessCleanupParams struct {
+	errs   []error
+	setup  mock.SetupFunc
+	expect test.Expect
+}
+
+var testHarnessCleanupParams = map[string]HarnessCleanupParams{
+	"success": {
+		errs:   []error{nil, nil},
+		expect: test.Success,
+	},
+	"single failure": {
+		errs: []error{nil, errors.New("first")},
+		setup: test.Errorf("%v", harness.ErrCleanup(
+			[]error{errors.New("first")})),
+	},
+	"combined failures": {
+		errs: []error{errors.New("first"), errors.New("second")},
+		setup: test.Errorf("%v", harness.ErrCleanup(
+			[]error{errors.New("second"), errors.New("first")})),
+	},
+}
+
+func TestHarnessCleanup(t *testing.T) {
+	test.Map(t, testHarnessCleanupParams).
+		Run(func(t test.Test, param HarnessCleanupParams) {
+			// Given
+			var dir string
+			t.(*test.Tester).Cleanup(func() {
+				_, err := os.Stat(dir)
+				assert.True(t, os.IsNotExist(err))
+			})
+			h := harness.New(t).Expect(param.setup)
+			dir = h.TempDir()
+
+			// When
+			for _, err := range param.errs {
+				err := err
+				h.Cleanup(func() error {
+					_, serr := os.Stat(dir)
+					assert.NoError(t, serr)
+					return err
+				})
+			}
+
+			// Then
+			assert.Equal(t, dir, h.TempDir())
+		})
+}
+
+// Cycle is a dependency depending on itself via Other.
+type Cycle struct{ other *Other }
+
+// Other is a dependency depending on Cycle.
+type Other struct{ cycle *Cycle }
+
+type HarnessGetParams struct {
+	bind        func(h *harness.Harness)
+	get         func(h *harness.Harness)
+	expectPanic error
+}
+
+var testHarnessGetParams = map[string]HarnessGetParams{
+	"unbound": {
+		get: func(h *harness.Harness) {
+			harness.Get[Clock](h)
+		},
+		expectPanic: harness.ErrUnbound(
+			reflect.TypeOf((*Clock)(nil)).Elem()),
+	},
+	"cycle": {
+		bind: func(h *harness.Harness) {
+			harness.Bind(h, func(h *harness.Harness) *Cycle {
+				return &Cycle{other: harness.Get[*Other](h)}
+			})
+			harness.Bind(h, func(h *harness.Harness) *Other {
+				return &Other{cycle: harness.Get[*Cycle](h)}
+			})
+		},
+		get: func(h *harness.Harness) {
+			harness.Get[*Cycle](h)
+		},
+		expectPanic: harness.ErrCycle([]reflect.Type{
+			reflect.TypeOf(&Cycle{}), reflect.TypeOf(&Other{}),
+			reflect.TypeOf(&Cycle{}),
+		}),
+	},
+	"mock type": {
+		bind: func(h *harness.Harness) {
+			harness.BindMock[Clock](h, NewMockRepo)
+		},
+		get: func(h *harness.Harness) {
+			harness.Get[Clock](h)
+		},
+		expectPanic: harness.ErrMockType(
+			reflect.TypeOf((*Clock)(nil)).Elem(),
+			reflect.TypeOf(&MockRepo{})),
+	},
+	"nil value": {
+		bind: func(h *harness.Harness) {
+			harness.BindValue[Clock](h, nil)
+		},
+		get: func(h *harness.Harness) {
+			harness.Get[Clock](h)
+		},
+	},
+	"panic recovered": {
+		bind: func(h *harness.Harness) {
+			failed := false
+			harness.Bind(h, func(*harness.Harness) *Other {
+				if !failed {
+					failed = true
+					panic("failed")
+				}
+				return &Other{}
+			})
+		},
+		get: func(h *harness.Harness) {
+			func() {
+				defer func() { _ = recover() }()
+				harness.Get[*Other](h)
+			}()
+			harness.Get[*Other](h)
+		},
+	},
+}
+
+func TestHarnessGet(t *testing.T) {
+	test.Map(t, testHarnessGetParams).
+		Run(func(t test.Test, param HarnessGetParams) {
+			// Given
+			h := harness.New(t)
+			if param.bind != nil {
+				param.bind(h)
+			}
+			defer func() {
+				assert.Equal(t, param.expectPanic, recover())
+			}()
+
+			// When
+			param.get(h)
+		})
+}
+
+func TestHarnessGetConcurrent(t *testing.T) {
+	// Given
+	h := harness.New(t)
+	mu, calls := gosync.Mutex{}, 0
+	entered, release := make(chan struct{}), make(chan struct{})
+	harness.Bind(h, func(*harness.Harness) *Other {
+		mu.Lock()
+		calls++
+		first := calls == 1
+		mu.Unlock()
+		if first {
+			close(entered)
+			<-release
+		} else {
+			close(release)
+		}
+		return &Other{}
+	})
+
+	// When
+	done := make(chan *Other)
+	go func() { done <- harness.Get[*Other](h) }()
+	<-entered
+	other := harness.Get[*Other](h)
+
+	// Then
+	assert.Same(t, other, <-done)
+	assert.Same(t, other, harness.Get[*Other](h))
+}
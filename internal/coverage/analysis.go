@@ -0,0 +1,105 @@
+package coverage
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// Case is the coverage of a single test case.
+type Case struct {
+	// Name the full name of the test case.
+	Name string
+	// Blocks the blocks covered by the test case.
+	Blocks []Block
+	// Unique the blocks covered only by this test case.
+	Unique []Block
+}
+
+// Analysis is the result of the per test case coverage analysis.
+type Analysis struct {
+	// Cases the coverage of the test cases sorted by name.
+	Cases []*Case
+	// Redundant the test cases that add no unique coverage.
+	Redundant []*Case
+	// Single the blocks that are covered by only a single test case mapped
+	// to the covering test case.
+	Single map[Block]*Case
+}
+
+// Analyze analyzes the given coverage profiles per test case name and finds
+// the test cases that add no unique coverage, i.e. test cases that could be
+// removed individually without loosing coverage, and the blocks covered by
+// only a single test case.
+func Analyze(profiles map[string]Profile) *Analysis {
+	analysis := &Analysis{Single: map[Block]*Case{}}
+	cases := map[Block][]*Case{}
+
+	for name, profile := range profiles {
+		tcase := &Case{Name: name, Blocks: profile.Covered()}
+		for _, block := range tcase.Blocks {
+			cases[block] = append(cases[block], tcase)
+		}
+		analysis.Cases = append(analysis.Cases, tcase)
+	}
+	sort.Slice(analysis.Cases, func(i, j int) bool {
+		return analysis.Cases[i].Name < analysis.Cases[j].Name
+	})
+
+	for block, tcases := range cases {
+		if len(tcases) == 1 {
+			tcases[0].Unique = append(tcases[0].Unique, block)
+			analysis.Single[block] = tcases[0]
+		}
+	}
+
+	for _, tcase := range analysis.Cases {
+		sortBlocks(tcase.Unique)
+		if len(tcase.Unique) == 0 {
+			analysis.Redundant = append(analysis.Redundant, tcase)
+		}
+	}
+	return analysis
+}
+
+// Report writes a report of the analysis to the given writer. The report
+// contains a summary of covered and uniquely covered blocks per test case,
+// the list of redundant test cases, and - if verbose is set - the lines that
+// are covered by only a single test case.
+func (a *Analysis) Report(w io.Writer, verbose bool) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "CASE\tBLOCKS\tUNIQUE")
+	for _, tcase := range a.Cases {
+		fmt.Fprintf(tw, "%s\t%d\t%d\n", tcase.Name,
+			len(tcase.Blocks), len(tcase.Unique))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	builder := strings.Builder{}
+	if len(a.Redundant) != 0 {
+		builder.WriteString("\nredundant cases (no unique coverage):\n")
+		for _, tcase := range a.Redundant {
+			fmt.Fprintf(&builder, "\t%s\n", tcase.Name)
+		}
+	}
+
+	if verbose && len(a.Single) != 0 {
+		blocks := make([]Block, 0, len(a.Single))
+		for block := range a.Single {
+			blocks = append(blocks, block)
+		}
+		sortBlocks(blocks)
+
+		builder.WriteString("\nlines covered by only one case:\n")
+		for _, block := range blocks {
+			fmt.Fprintf(&builder, "\t%s\t%s\n", block, a.Single[block].Name)
+		}
+	}
+
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
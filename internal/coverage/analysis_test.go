@@ -0,0 +1,112 @@
+package coverage_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tkrop/go-testing/internal/coverage"
+	"github.com/tkrop/go-testing/test"
+)
+
+type AnalyzeParams struct {
+	profiles        map[string]coverage.Profile
+	verbose         bool
+	expectRedundant []string
+	expectUnique    map[string][]coverage.Block
+	expectReport    string
+}
+
+var testAnalyzeParams = map[string]AnalyzeParams{
+	"empty": {
+		profiles:     map[string]coverage.Profile{},
+		expectUnique: map[string][]coverage.Block{},
+		expectReport: "CASE  BLOCKS  UNIQUE\n",
+	},
+	"single case": {
+		profiles: map[string]coverage.Profile{
+			"TestA/a": {blockA: 1, blockB: 0},
+		},
+		verbose: true,
+		expectUnique: map[string][]coverage.Block{
+			"TestA/a": {blockA},
+		},
+		expectReport: "CASE     BLOCKS  UNIQUE\n" +
+			"TestA/a  1       1\n" +
+			"\nlines covered by only one case:\n" +
+			"\tpkg/file.go:3-4\tTestA/a\n",
+	},
+	"redundant case": {
+		profiles: map[string]coverage.Profile{
+			"TestA/a": {blockA: 1, blockB: 1, blockC: 0},
+			"TestA/b": {blockA: 1, blockB: 0, blockC: 1},
+			"TestA/c": {blockA: 1, blockB: 0, blockC: 0},
+		},
+		verbose:         true,
+		expectRedundant: []string{"TestA/c"},
+		expectUnique: map[string][]coverage.Block{
+			"TestA/a": {blockB},
+			"TestA/b": {blockC},
+			"TestA/c": nil,
+		},
+		expectReport: "CASE     BLOCKS  UNIQUE\n" +
+			"TestA/a  2       1\n" +
+			"TestA/b  2       1\n" +
+			"TestA/c  1       0\n" +
+			"\nredundant cases (no unique coverage):\n" +
+			"\tTestA/c\n" +
+			"\nlines covered by only one case:\n" +
+			"\tpkg/file.go:4-6\tTestA/a\n" +
+			"\tpkg/file.go:7\tTestA/b\n",
+	},
+	"redundant cases not verbose": {
+		profiles: map[string]coverage.Profile{
+			"TestA/a": {blockA: 1, blockC: 1},
+			"TestA/b": {blockA: 1, blockC: 1},
+		},
+		expectRedundant: []string{"TestA/a", "TestA/b"},
+		expectUnique: map[string][]coverage.Block{
+			"TestA/a": nil,
+			"TestA/b": nil,
+		},
+		expectReport: "CASE     BLOCKS  UNIQUE\n" +
+			"TestA/a  2       0\n" +
+			"TestA/b  2       0\n" +
+			"\nredundant cases (no unique coverage):\n" +
+			"\tTestA/a\n\tTestA/b\n",
+	},
+}
+
+func TestAnalyze(t *testing.T) {
+	test.Map(t, testAnalyzeParams).
+		Run(func(t test.Test, param AnalyzeParams) {
+			// When
+			analysis := coverage.Analyze(param.profiles)
+
+			// Then
+			redundant := []string{}
+			for _, tcase := range analysis.Redundant {
+				redundant = append(redundant, tcase.Name)
+			}
+			if param.expectRedundant == nil {
+				param.expectRedundant = []string{}
+			}
+			assert.Equal(t, param.expectRedundant, redundant)
+
+			unique := map[string][]coverage.Block{}
+			for _, tcase := range analysis.Cases {
+				unique[tcase.Name] = tcase.Unique
+			}
+			assert.Equal(t, param.expectUnique, unique)
+
+			// When
+			builder := &strings.Builder{}
+			err := analysis.Report(builder, param.verbose)
+
+			// Then
+			require.NoError(t, err)
+			assert.Equal(t, param.expectReport, builder.String())
+		})
+}
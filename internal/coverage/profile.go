@@ -0,0 +1,124 @@
+// Package coverage contains a small engine to collect the coverage of each
+// test case of a package separately and to analyze which test cases add no
+// unique coverage and which lines are covered by only a single test case. It
+// is currently not part of the public interface and must be consider as highly
+// instable.
+package coverage
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Block is a source code block of a coverage profile.
+type Block struct {
+	// File the file name of the block.
+	File string
+	// StartLine the start line of the block.
+	StartLine int
+	// StartCol the start column of the block.
+	StartCol int
+	// EndLine the end line of the block.
+	EndLine int
+	// EndCol the end column of the block.
+	EndCol int
+	// Stmts the number of statements in the block.
+	Stmts int
+}
+
+// String returns a readable representation of the block.
+func (b Block) String() string {
+	if b.StartLine == b.EndLine {
+		return fmt.Sprintf("%s:%d", b.File, b.StartLine)
+	}
+	return fmt.Sprintf("%s:%d-%d", b.File, b.StartLine, b.EndLine)
+}
+
+// Profile is a parsed coverage profile mapping the blocks to their count.
+type Profile map[Block]int
+
+// Covered returns the sorted blocks of the profile that are covered, i.e.
+// that have a count greater than zero.
+func (p Profile) Covered() []Block {
+	blocks := make([]Block, 0, len(p))
+	for block, count := range p {
+		if count > 0 {
+			blocks = append(blocks, block)
+		}
+	}
+	sortBlocks(blocks)
+	return blocks
+}
+
+// ParseProfile parses a coverage profile as written by `go test
+// -coverprofile` from the given reader. Counts of blocks listed multiple
+// times, e.g. for multiple packages, are summed up.
+func ParseProfile(reader io.Reader) (Profile, error) {
+	profile := Profile{}
+	scanner := bufio.NewScanner(reader)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "mode:") {
+			continue
+		}
+
+		block, count, err := parseLine(text)
+		if err != nil {
+			return nil, ErrProfile(line, text)
+		}
+		profile[block] += count
+	}
+	return profile, scanner.Err()
+}
+
+// parseLine parses a single coverage profile line of the format
+// `file:startLine.startCol,endLine.endCol stmts count`.
+func parseLine(text string) (Block, int, error) {
+	block := Block{}
+	index := strings.LastIndex(text, ":")
+	if index < 0 {
+		return block, 0, ErrProfile(0, text)
+	}
+	block.File = text[:index]
+
+	fields := strings.Fields(text[index+1:])
+	if len(fields) != 3 {
+		return block, 0, ErrProfile(0, text)
+	}
+
+	_, err := fmt.Sscanf(fields[0], "%d.%d,%d.%d",
+		&block.StartLine, &block.StartCol, &block.EndLine, &block.EndCol)
+	if err != nil {
+		return block, 0, err
+	}
+	if block.Stmts, err = strconv.Atoi(fields[1]); err != nil {
+		return block, 0, err
+	}
+	count, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return block, 0, err
+	}
+	return block, count, nil
+}
+
+// sortBlocks sorts the given blocks by file and position.
+func sortBlocks(blocks []Block) {
+	sort.Slice(blocks, func(i, j int) bool {
+		bi, bj := blocks[i], blocks[j]
+		if bi.File != bj.File {
+			return bi.File < bj.File
+		} else if bi.StartLine != bj.StartLine {
+			return bi.StartLine < bj.StartLine
+		}
+		return bi.StartCol < bj.StartCol
+	})
+}
+
+// ErrProfile creates an error reporting an invalid coverage profile line.
+func ErrProfile(line int, text string) error {
+	return fmt.Errorf("invalid coverage profile line %d: %s", line, text)
+}
@@ -0,0 +1,109 @@
+package coverage_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tkrop/go-testing/internal/coverage"
+	"github.com/tkrop/go-testing/test"
+)
+
+var (
+	blockA = coverage.Block{
+		File: "pkg/file.go", StartLine: 3, StartCol: 24,
+		EndLine: 4, EndCol: 12, Stmts: 1,
+	}
+	blockB = coverage.Block{
+		File: "pkg/file.go", StartLine: 4, StartCol: 12,
+		EndLine: 6, EndCol: 3, Stmts: 1,
+	}
+	blockC = coverage.Block{
+		File: "pkg/file.go", StartLine: 7, StartCol: 2,
+		EndLine: 7, EndCol: 10, Stmts: 1,
+	}
+)
+
+type ParseProfileParams struct {
+	profile       string
+	expectProfile coverage.Profile
+	expectError   error
+}
+
+var testParseProfileParams = map[string]ParseProfileParams{
+	"empty": {
+		profile:       "",
+		expectProfile: coverage.Profile{},
+	},
+	"mode only": {
+		profile:       "mode: set\n",
+		expectProfile: coverage.Profile{},
+	},
+	"blocks": {
+		profile: "mode: set\n" +
+			"pkg/file.go:3.24,4.12 1 1\n" +
+			"pkg/file.go:4.12,6.3 1 0\n" +
+			"pkg/file.go:7.2,7.10 1 1\n",
+		expectProfile: coverage.Profile{blockA: 1, blockB: 0, blockC: 1},
+	},
+	"blocks summed": {
+		profile: "mode: count\n" +
+			"pkg/file.go:3.24,4.12 1 1\n" +
+			"pkg/file.go:3.24,4.12 1 2\n",
+		expectProfile: coverage.Profile{blockA: 3},
+	},
+	"invalid fields": {
+		profile:     "mode: set\npkg/file.go:3.24,4.12 1\n",
+		expectError: coverage.ErrProfile(2, "pkg/file.go:3.24,4.12 1"),
+	},
+	"invalid position": {
+		profile:     "pkg/file.go:3,4.12 1 1\n",
+		expectError: coverage.ErrProfile(1, "pkg/file.go:3,4.12 1 1"),
+	},
+	"invalid statements": {
+		profile:     "pkg/file.go:3.24,4.12 x 1\n",
+		expectError: coverage.ErrProfile(1, "pkg/file.go:3.24,4.12 x 1"),
+	},
+	"invalid count": {
+		profile:     "pkg/file.go:3.24,4.12 1 x\n",
+		expectError: coverage.ErrProfile(1, "pkg/file.go:3.24,4.12 1 x"),
+	},
+	"missing file": {
+		profile:     "3.24,4.12 1 1\n",
+		expectError: coverage.ErrProfile(1, "3.24,4.12 1 1"),
+	},
+}
+
+func TestParseProfile(t *testing.T) {
+	test.Map(t, testParseProfileParams).
+		Run(func(t test.Test, param ParseProfileParams) {
+			// When
+			profile, err := coverage.ParseProfile(
+				strings.NewReader(param.profile))
+
+			// Then
+			assert.Equal(t, param.expectError, err)
+			assert.Equal(t, param.expectProfile, profile)
+		})
+}
+
+func TestProfileCovered(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	profile := coverage.Profile{blockC: 1, blockB: 0, blockA: 2}
+
+	// When
+	blocks := profile.Covered()
+
+	// Then
+	assert.Equal(t, []coverage.Block{blockA, blockC}, blocks)
+}
+
+func TestBlockString(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "pkg/file.go:3-4", blockA.String())
+	assert.Equal(t, "pkg/file.go:7", blockC.String())
+}
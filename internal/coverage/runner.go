@@ -0,0 +1,106 @@
+package coverage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/tkrop/go-testing/internal/gotest"
+)
+
+// Runner is a runner collecting the coverage per test case of a package.
+type Runner struct {
+	// Dir the directory of the package under test.
+	Dir string
+	// Args the additional arguments for `go test`, e.g. `-coverpkg`.
+	Args []string
+
+	// exec the function executing the tests in the package directory.
+	exec func(dir string, args ...string) ([]gotest.Event, error)
+}
+
+// NewRunner creates a new coverage runner for the package in the given
+// directory using the given additional `go test` arguments.
+func NewRunner(dir string, args ...string) *Runner {
+	return &Runner{Dir: dir, Args: args, exec: gotest.Run}
+}
+
+// Cases runs the tests of the package matching the given run pattern once and
+// returns the names of all test cases, i.e. the tests and sub-tests without
+// further sub-tests. The tests must succeed to allow a meaningful coverage
+// analysis. If the pattern is empty, all tests are run.
+func (r *Runner) Cases(run string) ([]string, error) {
+	args := r.args()
+	if run != "" {
+		args = append(args, "-run="+run)
+	}
+
+	events, err := r.exec(r.Dir, args...)
+	if err != nil {
+		return nil, err
+	} else if failed := gotest.Failed(events, true); len(failed) != 0 ||
+		gotest.PackageFailed(events) || gotest.BuildFailed(events) {
+		return nil, ErrBaseline(gotest.Output(events, ""))
+	}
+	return gotest.Passed(events, true), nil
+}
+
+// Run runs each of the given test cases separately collecting the coverage
+// profile of each test case. Test cases that fail are reported with an error.
+func (r *Runner) Run(cases ...string) (map[string]Profile, error) {
+	temp, err := os.MkdirTemp("", "coverage-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(temp)
+
+	profiles := map[string]Profile{}
+	for index, name := range cases {
+		path := filepath.Join(temp, strconv.Itoa(index)+".out")
+		events, err := r.exec(r.Dir, append(r.args(),
+			"-run="+gotest.RunPattern(name), "-coverprofile="+path)...)
+		if err != nil {
+			return nil, err
+		} else if failed := gotest.Failed(events, true); len(failed) != 0 ||
+			gotest.BuildFailed(events) {
+			return nil, ErrCase(name, gotest.Output(events, name))
+		} else if gotest.PackageFailed(events) {
+			// Timeouts, hangs, and exits are only reported by the package.
+			return nil, ErrCase(name, gotest.Output(events, ""))
+		}
+
+		profile, err := readProfile(path)
+		if err != nil {
+			return nil, err
+		}
+		profiles[name] = profile
+	}
+	return profiles, nil
+}
+
+// args returns the `go test` arguments used for the test runs.
+func (r *Runner) args() []string {
+	return append([]string{"-count=1"}, r.Args...)
+}
+
+// readProfile reads the coverage profile from the given path.
+func readProfile(path string) (Profile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ParseProfile(file)
+}
+
+// ErrBaseline creates an error reporting that the tests are failing.
+func ErrBaseline(output string) error {
+	return errors.New("tests failing:\n" + output)
+}
+
+// ErrCase creates an error reporting that the test case with the given name
+// failed while collecting its coverage.
+func ErrCase(name, output string) error {
+	return errors.New("test case [" + name + "] failing:\n" + output)
+}
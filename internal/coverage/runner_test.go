@@ -0,0 +1,168 @@
+package coverage
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tkrop/go-testing/internal/gotest"
+	"github.com/tkrop/go-testing/test"
+)
+
+// Test events for a passing, failing, timed out, and not compiling test runs.
+var (
+	eventsPass = []gotest.Event{
+		{Action: gotest.ActionPass, Test: "TestMax/first"},
+		{Action: gotest.ActionPass, Test: "TestMax/second"},
+		{Action: gotest.ActionPass, Test: "TestMax"},
+		{Action: gotest.ActionPass},
+	}
+	eventsFail = []gotest.Event{
+		{Action: gotest.ActionOutput, Test: "TestMax/first", Output: "fail\n"},
+		{Action: gotest.ActionFail, Test: "TestMax/first"},
+		{Action: gotest.ActionFail, Test: "TestMax"},
+		{Action: gotest.ActionFail},
+	}
+	eventsTimeout = []gotest.Event{
+		{Action: gotest.ActionRun, Test: "TestMax"},
+		{Action: gotest.ActionRun, Test: "TestMax/first"},
+		{Action: gotest.ActionOutput, Output: "panic: test timed out\n"},
+		{Action: gotest.ActionFail},
+	}
+	eventsBuild = []gotest.Event{
+		{Action: gotest.ActionOutput, Output: "FAIL pkg [build failed]\n"},
+		{Action: gotest.ActionFail},
+	}
+)
+
+// Test coverage profiles written by the fake test execution per test case.
+var profiles = map[string]string{
+	"^TestMax$/^first$": "mode: set\n" +
+		"pkg/max.go:3.24,4.12 1 1\npkg/max.go:4.12,6.3 1 1\n",
+	"^TestMax$/^second$": "mode: set\n" +
+		"pkg/max.go:3.24,4.12 1 1\npkg/max.go:4.12,6.3 1 0\n",
+}
+
+// newExec creates a fake test execution returning the given events for the
+// discovery run and writing the coverage profiles for the test case runs.
+func newExec(
+	t test.Test, cases, runs []gotest.Event, profile string,
+) func(string, ...string) ([]gotest.Event, error) {
+	return func(dir string, args ...string) ([]gotest.Event, error) {
+		assert.Equal(t, "-count=1", args[0])
+		last := args[len(args)-1]
+		if strings.HasPrefix(last, "-run=TestMax") {
+			return cases, nil
+		}
+		if !strings.HasPrefix(last, "-coverprofile=") {
+			return cases, nil
+		}
+
+		pattern := strings.TrimPrefix(args[len(args)-2], "-run=")
+		content, ok := profiles[pattern]
+		if !ok {
+			content = profile
+		}
+		path := strings.TrimPrefix(last, "-coverprofile=")
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return runs, nil
+	}
+}
+
+type RunnerParams struct {
+	run          string
+	cases        []gotest.Event
+	runs         []gotest.Event
+	profile      string
+	execErr      error
+	expectCases  []string
+	expectError  error
+	expectReport string
+}
+
+var testRunnerParams = map[string]RunnerParams{
+	"cases failing": {
+		cases:       eventsFail,
+		expectError: ErrBaseline("fail\n"),
+	},
+	"cases build failed": {
+		cases:       eventsBuild,
+		expectError: ErrBaseline("FAIL pkg [build failed]\n"),
+	},
+	"cases timeout": {
+		cases:       eventsTimeout,
+		expectError: ErrBaseline("panic: test timed out\n"),
+	},
+	"exec failing": {
+		execErr:     errors.New("exec failed"),
+		expectError: errors.New("exec failed"),
+	},
+	"case failing": {
+		cases:       eventsPass,
+		runs:        eventsFail,
+		expectCases: []string{"TestMax/first", "TestMax/second"},
+		expectError: ErrCase("TestMax/first", "fail\n"),
+	},
+	"case timeout": {
+		cases:       eventsPass,
+		runs:        eventsTimeout,
+		expectCases: []string{"TestMax/first", "TestMax/second"},
+		expectError: ErrCase("TestMax/first",
+			"panic: test timed out\n"),
+	},
+	"case invalid profile": {
+		cases: []gotest.Event{
+			{Action: gotest.ActionPass, Test: "TestOther"},
+		},
+		runs:        eventsPass,
+		profile:     "invalid\n",
+		expectCases: []string{"TestOther"},
+		expectError: ErrProfile(1, "invalid"),
+	},
+	"cases analyzed": {
+		run:         "TestMax",
+		cases:       eventsPass,
+		runs:        eventsPass,
+		expectCases: []string{"TestMax/first", "TestMax/second"},
+		expectReport: "CASE            BLOCKS  UNIQUE\n" +
+			"TestMax/first   2       1\n" +
+			"TestMax/second  1       0\n" +
+			"\nredundant cases (no unique coverage):\n" +
+			"\tTestMax/second\n" +
+			"\nlines covered by only one case:\n" +
+			"\tpkg/max.go:4-6\tTestMax/first\n",
+	},
+}
+
+func TestRunner(t *testing.T) {
+	test.Map(t, testRunnerParams).
+		Run(func(t test.Test, param RunnerParams) {
+			// Given
+			runner := NewRunner(".")
+			runner.exec = newExec(t, param.cases, param.runs, param.profile)
+			if param.execErr != nil {
+				runner.exec = func(string, ...string) ([]gotest.Event, error) {
+					return nil, param.execErr
+				}
+			}
+
+			// When
+			cases, err := runner.Cases(param.run)
+			if err == nil {
+				assert.Equal(t, param.expectCases, cases)
+				var profiles map[string]Profile
+				profiles, err = runner.Run(cases...)
+				if err == nil {
+					builder := &strings.Builder{}
+					err = Analyze(profiles).Report(builder, true)
+					assert.Equal(t, param.expectReport, builder.String())
+				}
+			}
+
+			// Then
+			assert.Equal(t, param.expectError, err)
+		})
+}
@@ -0,0 +1,269 @@
+// Package gotest contains a small collection of helpful functions to execute
+// `go test` and analyze the test events produced via `go test -json`. It is
+// currently not part of the public interface and must be consider as highly
+// instable.
+package gotest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Actions of test events as provided by `go test -json`.
+const (
+	// ActionRun signals that a test has started running.
+	ActionRun = "run"
+	// ActionPause signals that a test has been paused.
+	ActionPause = "pause"
+	// ActionCont signals that a test has continued running.
+	ActionCont = "cont"
+	// ActionPass signals that a test has passed.
+	ActionPass = "pass"
+	// ActionBench signals that a benchmark printed log output.
+	ActionBench = "bench"
+	// ActionFail signals that a test or package has failed.
+	ActionFail = "fail"
+	// ActionOutput signals that a test printed output.
+	ActionOutput = "output"
+	// ActionSkip signals that a test was skipped.
+	ActionSkip = "skip"
+	// ActionBuildOutput signals that the build of a test binary printed
+	// output, e.g. compile errors.
+	ActionBuildOutput = "build-output"
+)
+
+// Event is a test event as provided by `go test -json`.
+type Event struct {
+	// Time of the test event.
+	Time time.Time `json:",omitempty"`
+	// Action of the test event.
+	Action string
+	// Package of the test event.
+	Package string `json:",omitempty"`
+	// ImportPath of the test binary of a build event.
+	ImportPath string `json:",omitempty"`
+	// Test name of the test event.
+	Test string `json:",omitempty"`
+	// Elapsed time in seconds of a finished test.
+	Elapsed float64 `json:",omitempty"`
+	// Output of the test event.
+	Output string `json:",omitempty"`
+}
+
+// Parse parses the test events provided via `go test -json` from the given
+// reader. Lines that are not containing a valid test event, e.g. build
+// errors, are converted into output events without test name.
+func Parse(reader io.Reader) ([]Event, error) {
+	events := []Event{}
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		event := Event{}
+		if err := json.Unmarshal(line, &event); err != nil ||
+			event.Action == "" {
+			event = Event{Action: ActionOutput, Output: string(line) + "\n"}
+		}
+		events = append(events, event)
+	}
+	return events, scanner.Err()
+}
+
+// Run executes `go test -json` with given arguments in the given directory and
+// returns the parsed test events. A failing test run is not reported as error,
+// but only failures to execute the command.
+func Run(dir string, args ...string) ([]Event, error) {
+	return RunEnv(dir, nil, args...)
+}
+
+// RunEnv executes `go test -json` with given arguments and given additional
+// environment variables in the given directory and returns the parsed test
+// events. A failing test run is not reported as error, but only failures to
+// execute the command.
+func RunEnv(dir string, env []string, args ...string) ([]Event, error) {
+	cmd := exec.Command("go", append([]string{"test", "-json"}, args...)...)
+	cmd.Dir = dir
+	if len(env) != 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	if err := cmd.Run(); err != nil {
+		if _, ok := err.(*exec.ExitError); !ok {
+			return nil, err
+		}
+	}
+
+	events, err := Parse(io.MultiReader(stdout, stderr))
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// Failed returns the sorted names of the failed tests in the given events.
+// If leaves is set, only the failed tests that have no failed sub-tests are
+// returned, i.e. the test cases that are actually failing.
+func Failed(events []Event, leaves bool) []string {
+	return filter(events, ActionFail, leaves)
+}
+
+// Passed returns the sorted names of the passed tests in the given events.
+// If leaves is set, only the passed tests without passed sub-tests are
+// returned.
+func Passed(events []Event, leaves bool) []string {
+	return filter(events, ActionPass, leaves)
+}
+
+// Unfinished returns the sorted names of the tests that started but never
+// finished in the given events, e.g. because the test binary timed out or
+// exited. If leaves is set, only the unfinished tests without unfinished
+// sub-tests are returned.
+func Unfinished(events []Event, leaves bool) []string {
+	started := map[string]bool{}
+	for _, event := range events {
+		if event.Test == "" {
+			continue
+		}
+		switch event.Action {
+		case ActionRun:
+			started[event.Test] = true
+		case ActionPass, ActionFail, ActionSkip:
+			delete(started, event.Test)
+		}
+	}
+
+	names := make([]string, 0, len(started))
+	for name := range started {
+		names = append(names, name)
+	}
+	return sorted(names, leaves)
+}
+
+// PackageFailed returns whether the test run in the given events failed on
+// package level without a build failure. This includes failures that are not
+// reported as test failures, e.g. timeouts, `os.Exit` calls, and panics in
+// go-routines.
+func PackageFailed(events []Event) bool {
+	for _, event := range events {
+		if event.Test == "" && event.Action == ActionFail {
+			return !BuildFailed(events)
+		}
+	}
+	return false
+}
+
+// filter returns the sorted names of the tests with given final action.
+func filter(events []Event, action string, leaves bool) []string {
+	names := []string{}
+	for _, event := range events {
+		if event.Action == action && event.Test != "" {
+			names = append(names, event.Test)
+		}
+	}
+	return sorted(names, leaves)
+}
+
+// sorted sorts the given test names. If leaves is set, only the test names
+// without sub-test names are returned.
+func sorted(names []string, leaves bool) []string {
+	sort.Strings(names)
+
+	if !leaves {
+		return names
+	}
+
+	parents := map[string]bool{}
+	for _, name := range names {
+		for i := range name {
+			if name[i] == '/' {
+				parents[name[:i]] = true
+			}
+		}
+	}
+
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		if !parents[name] {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+// BuildFailed returns whether the test run in the given events failed due to
+// a build failure, i.e. the package failed without running any test.
+func BuildFailed(events []Event) bool {
+	tests, failed := false, false
+	for _, event := range events {
+		switch {
+		case event.Test != "":
+			tests = true
+		case event.Action == ActionFail:
+			failed = true
+		case event.Action == ActionOutput &&
+			strings.Contains(event.Output, "[build failed]"):
+			return true
+		}
+	}
+	return failed && !tests
+}
+
+// Output returns the concatenated output of the given test and its sub-tests
+// in the given events. If the test name is empty, the complete output is
+// returned.
+func Output(events []Event, test string) string {
+	builder := strings.Builder{}
+	for _, event := range events {
+		if event.Action == ActionOutput && (test == "" ||
+			event.Test == test || strings.HasPrefix(event.Test, test+"/")) {
+			builder.WriteString(event.Output)
+		}
+	}
+	return builder.String()
+}
+
+// RunPattern creates a `-run` pattern that exactly matches the tests with the
+// given names including the path of parent tests. The names are expected as
+// reported by `go test`, i.e. with spaces replaced by underscores. Since the
+// pattern is matched per level, the pattern of tests with different parent
+// tests also matches the tests combining their levels.
+func RunPattern(names ...string) string {
+	levels, found := [][]string{}, []map[string]bool{}
+	for _, name := range names {
+		for i, part := range strings.Split(name, "/") {
+			if i == len(levels) {
+				levels = append(levels, []string{})
+				found = append(found, map[string]bool{})
+			}
+			if part = regexp.QuoteMeta(part); !found[i][part] {
+				levels[i], found[i][part] = append(levels[i], part), true
+			}
+		}
+	}
+
+	parts := make([]string, 0, len(levels))
+	for _, level := range levels {
+		if len(level) == 1 {
+			parts = append(parts, "^"+level[0]+"$")
+		} else {
+			parts = append(parts, "^("+strings.Join(level, "|")+")$")
+		}
+	}
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,300 @@
+package gotest_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tkrop/go-testing/internal/gotest"
+	"github.com/tkrop/go-testing/test"
+)
+
+const testEvents = `{"Action":"run","Package":"pkg","Test":"TestA"}
+{"Action":"run","Package":"pkg","Test":"TestA/case_1"}
+{"Action":"output","Package":"pkg","Test":"TestA/case_1","Output":"fail\n"}
+{"Action":"fail","Package":"pkg","Test":"TestA/case_1","Elapsed":0.1}
+{"Action":"run","Package":"pkg","Test":"TestA/case_2"}
+{"Action":"pass","Package":"pkg","Test":"TestA/case_2","Elapsed":0.1}
+{"Action":"fail","Package":"pkg","Test":"TestA","Elapsed":0.2}
+{"Action":"run","Package":"pkg","Test":"TestB"}
+{"Action":"pass","Package":"pkg","Test":"TestB","Elapsed":0.1}
+
+{"Action":"fail","Package":"pkg","Elapsed":0.3}
+`
+
+const testBuildFailed = `# pkg
+./file.go:1:1: syntax error
+{"Action":"output","Package":"pkg","Output":"FAIL\tpkg [build failed]\n"}
+{"Action":"fail","Package":"pkg","Elapsed":0}
+`
+
+const testTimeout = `{"Action":"run","Package":"pkg","Test":"TestA"}
+{"Action":"pass","Package":"pkg","Test":"TestA","Elapsed":0.1}
+{"Action":"run","Package":"pkg","Test":"TestB"}
+{"Action":"run","Package":"pkg","Test":"TestB/case_1"}
+{"Action":"output","Package":"pkg","Output":"panic: test timed out after 1s\n"}
+{"Action":"fail","Package":"pkg","Elapsed":1.0}
+`
+
+const testSiblings = `{"Action":"run","Package":"pkg","Test":"TestA"}
+{"Action":"run","Package":"pkg","Test":"TestA/a"}
+{"Action":"run","Package":"pkg","Test":"TestA/a/b"}
+{"Action":"fail","Package":"pkg","Test":"TestA/a/b","Elapsed":0.1}
+{"Action":"fail","Package":"pkg","Test":"TestA/a","Elapsed":0.1}
+{"Action":"run","Package":"pkg","Test":"TestA/a#01"}
+{"Action":"fail","Package":"pkg","Test":"TestA/a#01","Elapsed":0.1}
+{"Action":"run","Package":"pkg","Test":"TestA/a-2"}
+{"Action":"fail","Package":"pkg","Test":"TestA/a-2","Elapsed":0.1}
+{"Action":"fail","Package":"pkg","Test":"TestA","Elapsed":0.3}
+{"Action":"fail","Package":"pkg","Elapsed":0.3}
+`
+
+func parse(t test.Test, input string) []gotest.Event {
+	events, err := gotest.Parse(strings.NewReader(input))
+	require.NoError(t, err)
+	return events
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	// When
+	events, err := gotest.Parse(strings.NewReader(testBuildFailed))
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, []gotest.Event{
+		{Action: gotest.ActionOutput, Output: "# pkg\n"},
+		{Action: gotest.ActionOutput, Output: "./file.go:1:1: syntax error\n"},
+		{
+			Action: gotest.ActionOutput, Package: "pkg",
+			Output: "FAIL\tpkg [build failed]\n",
+		},
+		{Action: gotest.ActionFail, Package: "pkg"},
+	}, events)
+}
+
+func TestParseBuildOutput(t *testing.T) {
+	t.Parallel()
+
+	// When
+	events, err := gotest.Parse(strings.NewReader(
+		`{"ImportPath":"pkg [pkg.test]","Action":"build-output",` +
+			`"Output":"./file.go:1:1: syntax error\n"}`))
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, []gotest.Event{{
+		Action: gotest.ActionBuildOutput, ImportPath: "pkg [pkg.test]",
+		Output: "./file.go:1:1: syntax error\n",
+	}}, events)
+}
+
+type FilterParams struct {
+	input  string
+	filter func([]gotest.Event, bool) []string
+	leaves bool
+	expect []string
+}
+
+var testFilterParams = map[string]FilterParams{
+	"failed": {
+		input:  testEvents,
+		filter: gotest.Failed,
+		expect: []string{"TestA", "TestA/case_1"},
+	},
+	"failed leaves": {
+		input:  testEvents,
+		filter: gotest.Failed,
+		leaves: true,
+		expect: []string{"TestA/case_1"},
+	},
+	"passed": {
+		input:  testEvents,
+		filter: gotest.Passed,
+		expect: []string{"TestA/case_2", "TestB"},
+	},
+	"passed leaves": {
+		input:  testEvents,
+		filter: gotest.Passed,
+		leaves: true,
+		expect: []string{"TestA/case_2", "TestB"},
+	},
+	"build failed": {
+		input:  testBuildFailed,
+		filter: gotest.Failed,
+		leaves: true,
+		expect: []string{},
+	},
+	"timeout failed": {
+		input:  testTimeout,
+		filter: gotest.Failed,
+		expect: []string{},
+	},
+	"unfinished": {
+		input:  testTimeout,
+		filter: gotest.Unfinished,
+		expect: []string{"TestB", "TestB/case_1"},
+	},
+	"unfinished leaves": {
+		input:  testTimeout,
+		filter: gotest.Unfinished,
+		leaves: true,
+		expect: []string{"TestB/case_1"},
+	},
+	"failed leaves siblings": {
+		input:  testSiblings,
+		filter: gotest.Failed,
+		leaves: true,
+		expect: []string{"TestA/a#01", "TestA/a-2", "TestA/a/b"},
+	},
+	"unfinished none": {
+		input:  testEvents,
+		filter: gotest.Unfinished,
+		expect: []string{},
+	},
+}
+
+func TestFilter(t *testing.T) {
+	test.Map(t, testFilterParams).
+		Run(func(t test.Test, param FilterParams) {
+			// Given
+			events := parse(t, param.input)
+
+			// When
+			names := param.filter(events, param.leaves)
+
+			// Then
+			assert.Equal(t, param.expect, names)
+		})
+}
+
+type BuildFailedParams struct {
+	input  string
+	expect bool
+}
+
+var testBuildFailedParams = map[string]BuildFailedParams{
+	"test failed": {
+		input:  testEvents,
+		expect: false,
+	},
+	"build failed": {
+		input:  testBuildFailed,
+		expect: true,
+	},
+	"setup failed": {
+		input:  `{"Action":"fail","Package":"pkg","Elapsed":0}`,
+		expect: true,
+	},
+	"no tests": {
+		input:  `{"Action":"pass","Package":"pkg","Elapsed":0}`,
+		expect: false,
+	},
+	"timeout": {
+		input:  testTimeout,
+		expect: false,
+	},
+}
+
+func TestBuildFailed(t *testing.T) {
+	test.Map(t, testBuildFailedParams).
+		Run(func(t test.Test, param BuildFailedParams) {
+			// Given
+			events := parse(t, param.input)
+
+			// When
+			failed := gotest.BuildFailed(events)
+
+			// Then
+			assert.Equal(t, param.expect, failed)
+		})
+}
+
+type PackageFailedParams struct {
+	input  string
+	expect bool
+}
+
+var testPackageFailedParams = map[string]PackageFailedParams{
+	"test failed": {
+		input:  testEvents,
+		expect: true,
+	},
+	"timeout": {
+		input:  testTimeout,
+		expect: true,
+	},
+	"build failed": {
+		input:  testBuildFailed,
+		expect: false,
+	},
+	"passed": {
+		input: `{"Action":"run","Package":"pkg","Test":"TestA"}
+{"Action":"pass","Package":"pkg","Test":"TestA","Elapsed":0.1}
+{"Action":"pass","Package":"pkg","Elapsed":0.1}`,
+		expect: false,
+	},
+}
+
+func TestPackageFailed(t *testing.T) {
+	test.Map(t, testPackageFailedParams).
+		Run(func(t test.Test, param PackageFailedParams) {
+			// Given
+			events := parse(t, param.input)
+
+			// When
+			failed := gotest.PackageFailed(events)
+
+			// Then
+			assert.Equal(t, param.expect, failed)
+		})
+}
+
+func TestOutput(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	events := parse(t, testEvents+testBuildFailed)
+
+	// Then
+	assert.Equal(t, "fail\n", gotest.Output(events, "TestA"))
+	assert.Equal(t, "", gotest.Output(events, "TestB"))
+	assert.Equal(t, "fail\n# pkg\n./file.go:1:1: syntax error\n"+
+		"FAIL\tpkg [build failed]\n", gotest.Output(events, ""))
+}
+
+func TestRunPattern(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, `^TestA$/^case_\(1\)$`,
+		gotest.RunPattern("TestA/case_(1)"))
+	assert.Equal(t, `^TestA$/^(case_\(1\)|case_2)$`,
+		gotest.RunPattern("TestA/case_(1)", "TestA/case_2"))
+	assert.Equal(t, `^(TestA|TestB)$/^case$`,
+		gotest.RunPattern("TestA/case", "TestB"))
+}
+
+func TestRunError(t *testing.T) {
+	t.Parallel()
+
+	// When
+	events, err := gotest.Run("/not/existing/dir")
+
+	// Then
+	assert.Error(t, err)
+	assert.Nil(t, events)
+}
+
+func TestRunEnvError(t *testing.T) {
+	t.Parallel()
+
+	// When
+	events, err := gotest.RunEnv("/not/existing/dir", []string{"KEY=value"})
+
+	// Then
+	assert.Error(t, err)
+	assert.Nil(t, events)
+}
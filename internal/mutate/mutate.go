@@ -0,0 +1,289 @@
+// Package mutate contains a small mutation testing engine that applies source
+// mutations to the functions of a package, runs the package tests against
+// each mutant, and reports the surviving mutants. It is currently not part of
+// the public interface and must be consider as highly instable.
+package mutate
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"strconv"
+)
+
+// Kind is the kind of a source mutation.
+type Kind string
+
+// Kinds of supported source mutations.
+const (
+	// Operator mutation flipping a binary operator.
+	Operator Kind = "operator"
+	// Condition mutation negating a condition.
+	Condition Kind = "condition"
+	// Removal mutation removing a call statement.
+	Removal Kind = "removal"
+	// Constant mutation changing a constant value.
+	Constant Kind = "constant"
+)
+
+// operators is the mapping of binary operators to their flipped operators.
+var operators = map[token.Token]token.Token{
+	token.EQL:  token.NEQ,
+	token.NEQ:  token.EQL,
+	token.LSS:  token.GEQ,
+	token.GEQ:  token.LSS,
+	token.GTR:  token.LEQ,
+	token.LEQ:  token.GTR,
+	token.ADD:  token.SUB,
+	token.SUB:  token.ADD,
+	token.MUL:  token.QUO,
+	token.QUO:  token.MUL,
+	token.LAND: token.LOR,
+	token.LOR:  token.LAND,
+}
+
+// Mutant is a single source mutation of a function in a source file.
+type Mutant struct {
+	// File the source file containing the mutation.
+	File *File
+	// Pos the source position of the mutation.
+	Pos token.Position
+	// Func the name of the function containing the mutation.
+	Func string
+	// Kind the kind of the mutation.
+	Kind Kind
+	// Desc the description of the mutation.
+	Desc string
+
+	// apply the function applying the mutation to the syntax tree.
+	apply func()
+	// revert the function reverting the mutation in the syntax tree.
+	revert func()
+}
+
+// String returns a readable description of the mutant.
+func (m *Mutant) String() string {
+	return fmt.Sprintf("%s: %s: %s", m.Pos, m.Func, m.Desc)
+}
+
+// Source returns the source of the file with the mutation applied. The syntax
+// tree of the file is reverted after creating the source.
+func (m *Mutant) Source() ([]byte, error) {
+	m.apply()
+	defer m.revert()
+	return m.File.Source()
+}
+
+// File is a parsed source file providing the available mutants.
+type File struct {
+	// Path the path of the source file.
+	Path string
+	// Mutants the mutants available in the source file.
+	Mutants []*Mutant
+
+	fset *token.FileSet
+	file *ast.File
+}
+
+// Parse parses the source file with given path and source content and finds
+// all available mutants in the function bodies of the source file. If the
+// given source content is nil, the source is read from the path.
+func Parse(path string, src []byte) (*File, error) {
+	var source any
+	if src != nil {
+		source = src
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, source, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &File{Path: path, fset: fset, file: file}
+	for _, decl := range file.Decls {
+		if fdecl, ok := decl.(*ast.FuncDecl); ok && fdecl.Body != nil {
+			f.inspect(funcName(fdecl), fdecl.Body)
+		}
+	}
+	return f, nil
+}
+
+// Source returns the formatted source of the file as it is.
+func (f *File) Source() ([]byte, error) {
+	buffer := &bytes.Buffer{}
+	if err := format.Node(buffer, f.fset, f.file); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// inspect finds all mutants in the given function body.
+func (f *File) inspect(name string, body *ast.BlockStmt) {
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.FuncLit:
+			f.inspect(name, node.Body)
+			return false
+		case *ast.BinaryExpr:
+			f.operator(name, node)
+		case *ast.IfStmt:
+			f.condition(name, &node.Cond)
+		case *ast.ForStmt:
+			if node.Cond != nil {
+				f.condition(name, &node.Cond)
+			}
+		case *ast.BlockStmt:
+			f.removal(name, node.List)
+		case *ast.CaseClause:
+			f.removal(name, node.Body)
+		case *ast.CommClause:
+			f.removal(name, node.Body)
+		case *ast.BasicLit:
+			f.constant(name, node)
+		case *ast.Ident:
+			f.boolean(name, node)
+		}
+		return true
+	})
+}
+
+// add adds a new mutant to the file.
+func (f *File) add(
+	name string, pos token.Pos, kind Kind, desc string,
+	apply, revert func(),
+) {
+	f.Mutants = append(f.Mutants, &Mutant{
+		File: f, Pos: f.fset.Position(pos), Func: name,
+		Kind: kind, Desc: desc, apply: apply, revert: revert,
+	})
+}
+
+// operator adds a mutant flipping the operator of the binary expression.
+func (f *File) operator(name string, expr *ast.BinaryExpr) {
+	op, ok := operators[expr.Op]
+	if !ok {
+		return
+	}
+	orig := expr.Op
+	f.add(name, expr.OpPos, Operator,
+		fmt.Sprintf("flip %s to %s", orig, op),
+		func() { expr.Op = op }, func() { expr.Op = orig })
+}
+
+// condition adds a mutant negating the given condition.
+func (f *File) condition(name string, cond *ast.Expr) {
+	orig := *cond
+	negated := &ast.UnaryExpr{
+		OpPos: orig.Pos(), Op: token.NOT,
+		X: &ast.ParenExpr{Lparen: orig.Pos(), X: orig, Rparen: orig.End()},
+	}
+	f.add(name, orig.Pos(), Condition, "negate condition",
+		func() { *cond = negated }, func() { *cond = orig })
+}
+
+// removal adds mutants removing the call statements in the statement list.
+func (f *File) removal(name string, list []ast.Stmt) {
+	for i, stmt := range list {
+		expr, ok := stmt.(*ast.ExprStmt)
+		if !ok {
+			continue
+		}
+		call, ok := expr.X.(*ast.CallExpr)
+		if !ok {
+			continue
+		}
+
+		i, empty := i, &ast.EmptyStmt{Semicolon: stmt.Pos(), Implicit: true}
+		f.add(name, stmt.Pos(), Removal,
+			fmt.Sprintf("remove call to %s", callName(call.Fun)),
+			func() { list[i] = empty }, func() { list[i] = stmt })
+	}
+}
+
+// constant adds a mutant changing the integer constant of the literal.
+func (f *File) constant(name string, lit *ast.BasicLit) {
+	if lit.Kind != token.INT {
+		return
+	}
+	value, err := strconv.ParseInt(lit.Value, 0, 64)
+	if err != nil {
+		return
+	}
+
+	orig, changed := lit.Value, strconv.FormatInt(value+1, 10)
+	f.add(name, lit.Pos(), Constant,
+		fmt.Sprintf("change constant %s to %s", orig, changed),
+		func() { lit.Value = changed }, func() { lit.Value = orig })
+}
+
+// boolean adds a mutant flipping the boolean constant of the identifier.
+func (f *File) boolean(name string, ident *ast.Ident) {
+	var changed string
+	switch ident.Name {
+	case "true":
+		changed = "false"
+	case "false":
+		changed = "true"
+	default:
+		return
+	}
+
+	orig := ident.Name
+	f.add(name, ident.Pos(), Constant,
+		fmt.Sprintf("change constant %s to %s", orig, changed),
+		func() { ident.Name = changed }, func() { ident.Name = orig })
+}
+
+// funcName returns the name of the function declaration including the
+// receiver type for methods.
+func funcName(fdecl *ast.FuncDecl) string {
+	if fdecl.Recv == nil || len(fdecl.Recv.List) == 0 {
+		return fdecl.Name.Name
+	}
+
+	rtype := fdecl.Recv.List[0].Type
+	if star, ok := rtype.(*ast.StarExpr); ok {
+		return "(*" + typeName(star.X) + ")." + fdecl.Name.Name
+	}
+	return typeName(rtype) + "." + fdecl.Name.Name
+}
+
+// typeName returns the name of the receiver type ignoring type parameters.
+func typeName(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		return expr.Name
+	case *ast.IndexExpr:
+		return typeName(expr.X)
+	case *ast.IndexListExpr:
+		return typeName(expr.X)
+	default:
+		return "?"
+	}
+}
+
+// callName returns a readable name of the called function.
+func callName(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		return expr.Name
+	case *ast.SelectorExpr:
+		return callName(expr.X) + "." + expr.Sel.Name
+	case *ast.IndexExpr:
+		return callName(expr.X)
+	case *ast.IndexListExpr:
+		return callName(expr.X)
+	case *ast.CallExpr:
+		return callName(expr.Fun) + "()"
+	case *ast.ParenExpr:
+		return callName(expr.X)
+	case *ast.StarExpr:
+		return callName(expr.X)
+	default:
+		return "func"
+	}
+}
@@ -0,0 +1,134 @@
+package mutate_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tkrop/go-testing/internal/mutate"
+	"github.com/tkrop/go-testing/test"
+)
+
+const testSource = `package pkg
+
+import "fmt"
+
+var global = 1 + 2
+
+type Type[T any] struct{}
+
+func (*Type[T]) Call(x int) bool {
+	if x == 0 {
+		return true
+	}
+	for i := 0; i < x; i++ {
+		fmt.Println(i)
+	}
+	return false
+}
+
+func Func(x int) func() int {
+	return func() int {
+		switch x {
+		case 1:
+			fmt.Println("x")
+		}
+		return x * 2
+	}
+}
+`
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	// When
+	file, err := mutate.Parse("file.go", []byte(testSource))
+
+	// Then
+	require.NoError(t, err)
+	mutants := make([]string, 0, len(file.Mutants))
+	for _, mutant := range file.Mutants {
+		mutants = append(mutants, string(mutant.Kind)+": "+mutant.String())
+	}
+	assert.Equal(t, []string{
+		"condition: file.go:10:5: (*Type).Call: negate condition",
+		"operator: file.go:10:7: (*Type).Call: flip == to !=",
+		"constant: file.go:10:10: (*Type).Call: change constant 0 to 1",
+		"constant: file.go:11:10: (*Type).Call: change constant true to false",
+		"condition: file.go:13:14: (*Type).Call: negate condition",
+		"constant: file.go:13:11: (*Type).Call: change constant 0 to 1",
+		"operator: file.go:13:16: (*Type).Call: flip < to >=",
+		"removal: file.go:14:3: (*Type).Call: remove call to fmt.Println",
+		"constant: file.go:16:9: (*Type).Call: change constant false to true",
+		"removal: file.go:23:4: Func: remove call to fmt.Println",
+		"constant: file.go:22:8: Func: change constant 1 to 2",
+		"operator: file.go:25:12: Func: flip * to /",
+		"constant: file.go:25:14: Func: change constant 2 to 3",
+	}, mutants)
+}
+
+func TestParseError(t *testing.T) {
+	t.Parallel()
+
+	// When
+	file, err := mutate.Parse("file.go", []byte("package"))
+
+	// Then
+	assert.Error(t, err)
+	assert.Nil(t, file)
+}
+
+type SourceParams struct {
+	index  int
+	expect string
+}
+
+var testSourceParams = map[string]SourceParams{
+	"condition": {
+		index:  0,
+		expect: "\tif !(x == 0) {\n",
+	},
+	"operator": {
+		index:  1,
+		expect: "\tif x != 0 {\n",
+	},
+	"constant": {
+		index:  2,
+		expect: "\tif x == 1 {\n",
+	},
+	"boolean": {
+		index:  3,
+		expect: "\t\treturn false\n",
+	},
+	"removal": {
+		index:  7,
+		expect: "\tfor i := 0; i < x; i++ {\n\n\t}\n",
+	},
+}
+
+func TestSource(t *testing.T) {
+	test.Map(t, testSourceParams).
+		Run(func(t test.Test, param SourceParams) {
+			// Given
+			file, err := mutate.Parse("file.go", []byte(testSource))
+			require.NoError(t, err)
+			orig, err := file.Source()
+			require.NoError(t, err)
+
+			// When
+			source, err := file.Mutants[param.index].Source()
+
+			// Then
+			require.NoError(t, err)
+			assert.Contains(t, string(source), param.expect)
+			assert.NotEqual(t, string(orig), string(source))
+
+			// When
+			reverted, err := file.Source()
+
+			// Then
+			require.NoError(t, err)
+			assert.Equal(t, string(orig), string(reverted))
+		})
+}
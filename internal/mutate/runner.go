@@ -0,0 +1,241 @@
+package mutate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/build"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/tkrop/go-testing/internal/gotest"
+)
+
+// Status is the status of a mutant after running the tests.
+type Status string
+
+// Status values of mutants after running the tests.
+const (
+	// Killed status of a mutant detected by at least one failing test.
+	Killed Status = "killed"
+	// Survived status of a mutant not detected by any test.
+	Survived Status = "survived"
+	// Invalid status of a mutant that does not compile.
+	Invalid Status = "invalid"
+)
+
+// Result is the result of running the tests against a mutant.
+type Result struct {
+	// Mutant the mutant the tests were run against.
+	Mutant *Mutant
+	// Status the status of the mutant after running the tests.
+	Status Status
+	// Killers the test cases that failed and thus killed the mutant.
+	Killers []string
+}
+
+// Runner is a mutation test runner for a single package.
+type Runner struct {
+	// Dir the directory of the package under test.
+	Dir string
+	// Args the additional arguments for `go test`, e.g. `-run`.
+	Args []string
+	// Progress the optional writer to report progress of the mutation run.
+	Progress io.Writer
+
+	// exec the function executing the tests in the package directory.
+	exec func(dir string, args ...string) ([]gotest.Event, error)
+}
+
+// NewRunner creates a new mutation test runner for the package in the given
+// directory using the given additional `go test` arguments.
+func NewRunner(dir string, args ...string) *Runner {
+	return &Runner{Dir: dir, Args: args, exec: gotest.Run}
+}
+
+// Files parses the non-test source files of the package matching the build
+// context and returns them with the available mutants.
+func (r *Runner) Files() ([]*File, error) {
+	pkg, err := build.ImportDir(r.Dir, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]*File, 0, len(pkg.GoFiles))
+	for _, name := range pkg.GoFiles {
+		path, err := filepath.Abs(filepath.Join(r.Dir, name))
+		if err != nil {
+			return nil, err
+		}
+		file, err := Parse(path, nil)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// Run runs the tests of the package against all mutants of the given files
+// and returns the results. Before running the mutants the tests are run on
+// the original sources to ensure they succeed.
+func (r *Runner) Run(files ...*File) ([]*Result, error) {
+	events, err := r.exec(r.Dir, r.args()...)
+	if err != nil {
+		return nil, err
+	} else if failed := gotest.Failed(events, true); len(failed) != 0 ||
+		gotest.PackageFailed(events) || gotest.BuildFailed(events) {
+		return nil, ErrBaseline(gotest.Output(events, ""))
+	}
+
+	temp, err := os.MkdirTemp("", "mutate-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(temp)
+
+	results := []*Result{}
+	for _, file := range files {
+		for _, mutant := range file.Mutants {
+			result, err := r.mutant(temp, mutant)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, result)
+			if r.Progress != nil {
+				fmt.Fprintf(r.Progress, "%s: %s\n", result.Status, mutant)
+			}
+		}
+	}
+	return results, nil
+}
+
+// mutant runs the tests against the given mutant using an overlay for the
+// mutated source file created in the given temporary directory.
+func (r *Runner) mutant(temp string, mutant *Mutant) (*Result, error) {
+	source, err := mutant.Source()
+	if err != nil {
+		return &Result{Mutant: mutant, Status: Invalid}, nil
+	}
+
+	path := filepath.Join(temp, filepath.Base(mutant.File.Path))
+	if err := os.WriteFile(path, source, 0o600); err != nil {
+		return nil, err
+	}
+
+	overlay, err := json.Marshal(map[string]map[string]string{
+		"Replace": {mutant.File.Path: path},
+	})
+	if err != nil {
+		return nil, err
+	}
+	opath := filepath.Join(temp, "overlay.json")
+	if err := os.WriteFile(opath, overlay, 0o600); err != nil {
+		return nil, err
+	}
+
+	events, err := r.exec(r.Dir, append(r.args(), "-overlay="+opath)...)
+	if err != nil {
+		return nil, err
+	}
+
+	if gotest.BuildFailed(events) {
+		return &Result{Mutant: mutant, Status: Invalid}, nil
+	} else if killers := gotest.Failed(events, true); len(killers) != 0 ||
+		gotest.PackageFailed(events) {
+		// Timeouts, hangs, and exits abort the test binary leaving the
+		// running test cases unfinished, i.e. they are the killers.
+		killers = append(killers, gotest.Unfinished(events, true)...)
+		return &Result{Mutant: mutant, Status: Killed, Killers: killers}, nil
+	}
+	return &Result{Mutant: mutant, Status: Survived}, nil
+}
+
+// args returns the `go test` arguments used for the test runs.
+func (r *Runner) args() []string {
+	return append([]string{"-count=1"}, r.Args...)
+}
+
+// Report writes a report of the given results to the given writer. The report
+// contains a summary per function followed by the list of surviving mutants.
+// If verbose is set, the killed mutants are listed together with the test
+// cases that killed them.
+func Report(w io.Writer, results []*Result, verbose bool) error {
+	type summary struct {
+		total, killed, survived, invalid int
+	}
+
+	names := []string{}
+	summaries := map[string]*summary{}
+	for _, result := range results {
+		name := result.Mutant.Func
+		sum, ok := summaries[name]
+		if !ok {
+			sum = &summary{}
+			summaries[name] = sum
+			names = append(names, name)
+		}
+		sum.total++
+		switch result.Status {
+		case Killed:
+			sum.killed++
+		case Survived:
+			sum.survived++
+		case Invalid:
+			sum.invalid++
+		}
+	}
+	sort.Strings(names)
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "FUNCTION\tMUTANTS\tKILLED\tSURVIVED\tINVALID")
+	for _, name := range names {
+		sum := summaries[name]
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\n", name,
+			sum.total, sum.killed, sum.survived, sum.invalid)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	builder := strings.Builder{}
+	for _, result := range results {
+		if result.Status == Survived {
+			fmt.Fprintf(&builder, "\nsurvived: %s", result.Mutant)
+		}
+	}
+	if verbose {
+		for _, result := range results {
+			if result.Status == Killed {
+				fmt.Fprintf(&builder, "\nkilled: %s\n\tby %s", result.Mutant,
+					strings.Join(result.Killers, "\n\tby "))
+			}
+		}
+	}
+	if builder.Len() != 0 {
+		builder.WriteString("\n")
+	}
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+// Survivors returns the number of surviving mutants in the given results.
+func Survivors(results []*Result) int {
+	count := 0
+	for _, result := range results {
+		if result.Status == Survived {
+			count++
+		}
+	}
+	return count
+}
+
+// ErrBaseline creates an error reporting that the tests are failing on the
+// original sources.
+func ErrBaseline(output string) error {
+	return errors.New("tests failing without mutation:\n" + output)
+}
@@ -0,0 +1,219 @@
+package mutate
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tkrop/go-testing/internal/gotest"
+	"github.com/tkrop/go-testing/test"
+)
+
+const runnerSource = `package pkg
+
+func Max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+`
+
+// Test events for a passing, failing, timed out, and not compiling test runs.
+var (
+	eventsPass = []gotest.Event{
+		{Action: gotest.ActionPass, Test: "TestMax/case"},
+		{Action: gotest.ActionPass, Test: "TestMax"},
+		{Action: gotest.ActionPass},
+	}
+	eventsFail = []gotest.Event{
+		{Action: gotest.ActionOutput, Test: "TestMax/case", Output: "fail\n"},
+		{Action: gotest.ActionFail, Test: "TestMax/case"},
+		{Action: gotest.ActionFail, Test: "TestMax"},
+		{Action: gotest.ActionFail},
+	}
+	eventsTimeout = []gotest.Event{
+		{Action: gotest.ActionRun, Test: "TestMax"},
+		{Action: gotest.ActionRun, Test: "TestMax/case"},
+		{Action: gotest.ActionOutput, Output: "panic: test timed out\n"},
+		{Action: gotest.ActionFail},
+	}
+	eventsBuild = []gotest.Event{
+		{Action: gotest.ActionOutput, Output: "FAIL pkg [build failed]\n"},
+		{Action: gotest.ActionFail},
+	}
+)
+
+// newExec creates a fake test execution returning the events depending on the
+// mutated source provided via the overlay.
+func newExec(
+	t test.Test, baseline []gotest.Event, mutants map[string][]gotest.Event,
+) func(string, ...string) ([]gotest.Event, error) {
+	return func(dir string, args ...string) ([]gotest.Event, error) {
+		assert.Equal(t, "-count=1", args[0])
+		last := args[len(args)-1]
+		if !strings.HasPrefix(last, "-overlay=") {
+			return baseline, nil
+		}
+
+		data, err := os.ReadFile(strings.TrimPrefix(last, "-overlay="))
+		require.NoError(t, err)
+		overlay := map[string]map[string]string{}
+		require.NoError(t, json.Unmarshal(data, &overlay))
+		for _, path := range overlay["Replace"] {
+			source, err := os.ReadFile(path)
+			require.NoError(t, err)
+			for match, events := range mutants {
+				if strings.Contains(string(source), match) {
+					return events, nil
+				}
+			}
+		}
+		return eventsPass, nil
+	}
+}
+
+type RunnerParams struct {
+	baseline      []gotest.Event
+	mutants       map[string][]gotest.Event
+	execErr       error
+	expectError   error
+	expectStatus  []Status
+	expectKillers [][]string
+	expectReport  string
+}
+
+var testRunnerParams = map[string]RunnerParams{
+	"baseline failing": {
+		baseline:    eventsFail,
+		expectError: ErrBaseline("fail\n"),
+	},
+	"baseline build failed": {
+		baseline:    eventsBuild,
+		expectError: ErrBaseline("FAIL pkg [build failed]\n"),
+	},
+	"baseline timeout": {
+		baseline:    eventsTimeout,
+		expectError: ErrBaseline("panic: test timed out\n"),
+	},
+	"exec failing": {
+		execErr:     errors.New("exec failed"),
+		expectError: errors.New("exec failed"),
+	},
+	"mutants": {
+		baseline: eventsPass,
+		mutants: map[string][]gotest.Event{
+			"!(a > b)": eventsFail,
+			"a <= b":   eventsBuild,
+		},
+		expectStatus:  []Status{Killed, Invalid},
+		expectKillers: [][]string{{"TestMax/case"}, nil},
+		expectReport: "FUNCTION  MUTANTS  KILLED  SURVIVED  INVALID\n" +
+			"Max       2        1       0         1\n" +
+			"\nkilled: {file}:4:5: Max: negate condition" +
+			"\n\tby TestMax/case\n",
+	},
+	"survivors": {
+		baseline: eventsPass,
+		mutants: map[string][]gotest.Event{
+			"!(a > b)": eventsFail,
+		},
+		expectStatus:  []Status{Killed, Survived},
+		expectKillers: [][]string{{"TestMax/case"}, nil},
+		expectReport: "FUNCTION  MUTANTS  KILLED  SURVIVED  INVALID\n" +
+			"Max       2        1       1         0\n" +
+			"\nsurvived: {file}:4:7: Max: flip > to <=" +
+			"\nkilled: {file}:4:5: Max: negate condition" +
+			"\n\tby TestMax/case\n",
+	},
+	"mutants timeout": {
+		baseline: eventsPass,
+		mutants: map[string][]gotest.Event{
+			"!(a > b)": eventsTimeout,
+		},
+		expectStatus:  []Status{Killed, Survived},
+		expectKillers: [][]string{{"TestMax/case"}, nil},
+		expectReport: "FUNCTION  MUTANTS  KILLED  SURVIVED  INVALID\n" +
+			"Max       2        1       1         0\n" +
+			"\nsurvived: {file}:4:7: Max: flip > to <=" +
+			"\nkilled: {file}:4:5: Max: negate condition" +
+			"\n\tby TestMax/case\n",
+	},
+}
+
+func TestRunner(t *testing.T) {
+	test.Map(t, testRunnerParams).
+		Run(func(t test.Test, param RunnerParams) {
+			// Given
+			dir, err := os.MkdirTemp("", "mutate-test-")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "max.go")
+			require.NoError(t, os.WriteFile(path, []byte(runnerSource), 0o600))
+
+			runner := NewRunner(dir)
+			runner.exec = newExec(t, param.baseline, param.mutants)
+			if param.execErr != nil {
+				runner.exec = func(string, ...string) ([]gotest.Event, error) {
+					return nil, param.execErr
+				}
+			}
+			files, err := runner.Files()
+			require.NoError(t, err)
+
+			// When
+			results, err := runner.Run(files...)
+
+			// Then
+			assert.Equal(t, param.expectError, err)
+			if param.expectError != nil {
+				return
+			}
+
+			status := []Status{}
+			killers := [][]string{}
+			for _, result := range results {
+				status = append(status, result.Status)
+				killers = append(killers, result.Killers)
+			}
+			assert.Equal(t, param.expectStatus, status)
+			assert.Equal(t, param.expectKillers, killers)
+
+			// When
+			builder := &strings.Builder{}
+			err = Report(builder, results, true)
+
+			// Then
+			require.NoError(t, err)
+			assert.Equal(t, strings.ReplaceAll(param.expectReport,
+				"{file}", path), builder.String())
+		})
+}
+
+func TestRunnerFilesError(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	runner := NewRunner("/not/existing/dir")
+
+	// When
+	files, err := runner.Files()
+
+	// Then
+	assert.Error(t, err)
+	assert.Nil(t, files)
+}
+
+func TestSurvivors(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 1, Survivors([]*Result{
+		{Status: Killed}, {Status: Survived}, {Status: Invalid},
+	}))
+}
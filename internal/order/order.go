@@ -0,0 +1,191 @@
+// Package order contains a small engine to detect test order dependencies. It
+// runs each test case of a package in isolation, runs the complete package
+// tests repeatedly with shuffled test and test case order, and reports the
+// test cases whose outcome changes depending on the test cases that ran before
+// them. It is currently not part of the public interface and must be consider
+// as highly instable.
+package order
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Outcome is the outcome of a test case in a single test run.
+type Outcome struct {
+	// Seed the shuffle seed of the test run, not used for isolated runs.
+	Seed int64
+	// Passed whether the test case passed.
+	Passed bool
+	// Before the test cases that started before the test case.
+	Before []string
+}
+
+// String returns a readable representation of the outcome.
+func (o *Outcome) String() string {
+	if o.Passed {
+		return "pass"
+	}
+	return "fail"
+}
+
+// Case is the collected outcomes of a test case.
+type Case struct {
+	// Name the name of the test case.
+	Name string
+	// Isolated the outcome of the test case run in isolation.
+	Isolated *Outcome
+	// Runs the outcomes of the test case in the shuffled test runs.
+	Runs []*Outcome
+	// Subsets the outcomes of the test case rerun in isolation together with
+	// each suspect using the seed of the first shuffled test run changing the
+	// outcome. The test cases started before only contain the suspect.
+	Subsets []*Outcome
+}
+
+// Dependent returns whether the outcome of the test case changed depending on
+// the test cases that ran before.
+func (c *Case) Dependent() bool {
+	return len(c.Changed()) != 0
+}
+
+// Failed returns the seeds of the shuffled test runs the test case failed.
+func (c *Case) Failed() []int64 {
+	seeds := []int64{}
+	for _, run := range c.Runs {
+		if !run.Passed {
+			seeds = append(seeds, run.Seed)
+		}
+	}
+	return seeds
+}
+
+// Changed returns the seeds of the shuffled test runs the outcome of the
+// test case differed from the outcome in isolation.
+func (c *Case) Changed() []int64 {
+	seeds := []int64{}
+	for _, run := range c.Runs {
+		if run.Passed != c.Isolated.Passed {
+			seeds = append(seeds, run.Seed)
+		}
+	}
+	return seeds
+}
+
+// Suspects returns the test cases suspected to cause the order dependency.
+// If the test case passes in isolation, the suspects are the test cases that
+// ran before the test case in all failing runs but in none of the passing
+// runs. If the test case fails in isolation, the suspects are the test cases
+// that ran before the test case in all passing runs but in none of the
+// failing runs.
+func (c *Case) Suspects() []string {
+	common, others := map[string]int{}, map[string]bool{}
+	count := 0
+	for _, run := range c.Runs {
+		if run.Passed == c.Isolated.Passed {
+			for _, name := range run.Before {
+				others[name] = true
+			}
+			continue
+		}
+		count++
+		for _, name := range run.Before {
+			common[name]++
+		}
+	}
+
+	suspects := []string{}
+	for name, found := range common {
+		if found == count && !others[name] {
+			suspects = append(suspects, name)
+		}
+	}
+	sort.Strings(suspects)
+	return suspects
+}
+
+// Confirmed returns the suspects confirmed to cause the order dependency, i.e.
+// the suspects that changed the outcome of the test case when rerun in
+// isolation together with the test case.
+func (c *Case) Confirmed() []string {
+	confirmed := []string{}
+	for _, subset := range c.Subsets {
+		if subset.Passed != c.Isolated.Passed {
+			confirmed = append(confirmed, subset.Before...)
+		}
+	}
+	sort.Strings(confirmed)
+	return confirmed
+}
+
+// Report writes a report of the given test cases to the given writer. The
+// report contains a summary per test case followed by the list of order
+// dependent test cases with the seeds changing the outcome and the suspected
+// test cases causing the dependency, marking the suspects confirmed by a rerun
+// in isolation.
+func Report(w io.Writer, cases []*Case) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "CASE\tISOLATED\tRUNS\tFAILED")
+	for _, tcase := range cases {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\n", tcase.Name, tcase.Isolated,
+			len(tcase.Runs), len(tcase.Failed()))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	builder := strings.Builder{}
+	for _, tcase := range cases {
+		if !tcase.Dependent() {
+			continue
+		}
+
+		seeds := []string{}
+		for _, seed := range tcase.Changed() {
+			seeds = append(seeds, strconv.FormatInt(seed, 10))
+		}
+		fmt.Fprintf(&builder, "\norder dependent: %s (isolated: %s, "+
+			"changed with seeds: %s)\n", tcase.Name, tcase.Isolated,
+			strings.Join(seeds, ", "))
+
+		relation := "fails after"
+		if !tcase.Isolated.Passed {
+			relation = "passes only after"
+		}
+		confirmed := map[string]bool{}
+		for _, suspect := range tcase.Confirmed() {
+			confirmed[suspect] = true
+		}
+		for _, suspect := range tcase.Suspects() {
+			if confirmed[suspect] {
+				fmt.Fprintf(&builder, "\t%s %s (confirmed)\n",
+					relation, suspect)
+			} else {
+				fmt.Fprintf(&builder, "\t%s %s\n", relation, suspect)
+			}
+		}
+	}
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+// Dependents returns the number of order dependent test cases.
+func Dependents(cases []*Case) int {
+	count := 0
+	for _, tcase := range cases {
+		if tcase.Dependent() {
+			count++
+		}
+	}
+	return count
+}
+
+// ErrBuild creates an error reporting that the tests failed to build.
+func ErrBuild(output string) error {
+	return errors.New("tests failed to build:\n" + output)
+}
@@ -0,0 +1,148 @@
+package order_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tkrop/go-testing/internal/order"
+	"github.com/tkrop/go-testing/test"
+)
+
+// Test cases with isolated and shuffled outcomes.
+var (
+	caseStable = &order.Case{
+		Name:     "TestA/stable",
+		Isolated: &order.Outcome{Passed: true},
+		Runs: []*order.Outcome{
+			{Seed: 1, Passed: true, Before: []string{"TestA/other"}},
+			{Seed: 2, Passed: true},
+		},
+	}
+	casePolluted = &order.Case{
+		Name:     "TestA/polluted",
+		Isolated: &order.Outcome{Passed: true},
+		Runs: []*order.Outcome{
+			{Seed: 1, Passed: false, Before: []string{"TestA/other", "TestA/writer"}},
+			{Seed: 2, Passed: true, Before: []string{"TestA/other"}},
+			{Seed: 3, Passed: false, Before: []string{"TestA/writer"}},
+		},
+		Subsets: []*order.Outcome{
+			{Seed: 1, Passed: false, Before: []string{"TestA/writer"}},
+		},
+	}
+	caseDependent = &order.Case{
+		Name:     "TestA/dependent",
+		Isolated: &order.Outcome{Passed: false},
+		Runs: []*order.Outcome{
+			{Seed: 1, Passed: true, Before: []string{"TestA/setup", "TestA/other"}},
+			{Seed: 2, Passed: false, Before: []string{"TestA/other"}},
+		},
+		Subsets: []*order.Outcome{
+			{Seed: 1, Passed: false, Before: []string{"TestA/setup"}},
+		},
+	}
+)
+
+type CaseParams struct {
+	tcase           *order.Case
+	expectDependent bool
+	expectFailed    []int64
+	expectChanged   []int64
+	expectSuspects  []string
+	expectConfirmed []string
+}
+
+var testCaseParams = map[string]CaseParams{
+	"stable": {
+		tcase:           caseStable,
+		expectFailed:    []int64{},
+		expectChanged:   []int64{},
+		expectSuspects:  []string{},
+		expectConfirmed: []string{},
+	},
+	"polluted": {
+		tcase:           casePolluted,
+		expectDependent: true,
+		expectFailed:    []int64{1, 3},
+		expectChanged:   []int64{1, 3},
+		expectSuspects:  []string{"TestA/writer"},
+		expectConfirmed: []string{"TestA/writer"},
+	},
+	"dependent": {
+		tcase:           caseDependent,
+		expectDependent: true,
+		expectFailed:    []int64{2},
+		expectChanged:   []int64{1},
+		expectSuspects:  []string{"TestA/setup"},
+		expectConfirmed: []string{},
+	},
+}
+
+func TestCase(t *testing.T) {
+	test.Map(t, testCaseParams).
+		Run(func(t test.Test, param CaseParams) {
+			// When
+			dependent := param.tcase.Dependent()
+			failed := param.tcase.Failed()
+			changed := param.tcase.Changed()
+			suspects := param.tcase.Suspects()
+			confirmed := param.tcase.Confirmed()
+
+			// Then
+			assert.Equal(t, param.expectDependent, dependent)
+			assert.Equal(t, param.expectFailed, failed)
+			assert.Equal(t, param.expectChanged, changed)
+			assert.Equal(t, param.expectSuspects, suspects)
+			assert.Equal(t, param.expectConfirmed, confirmed)
+		})
+}
+
+type ReportParams struct {
+	cases            []*order.Case
+	expectDependents int
+	expectReport     string
+}
+
+var testReportParams = map[string]ReportParams{
+	"empty": {
+		expectReport: "CASE  ISOLATED  RUNS  FAILED\n",
+	},
+	"stable": {
+		cases: []*order.Case{caseStable},
+		expectReport: "CASE          ISOLATED  RUNS  FAILED\n" +
+			"TestA/stable  pass      2     0\n",
+	},
+	"dependent": {
+		cases:            []*order.Case{caseDependent, casePolluted, caseStable},
+		expectDependents: 2,
+		expectReport: "CASE             ISOLATED  RUNS  FAILED\n" +
+			"TestA/dependent  fail      2     1\n" +
+			"TestA/polluted   pass      3     2\n" +
+			"TestA/stable     pass      2     0\n" +
+			"\norder dependent: TestA/dependent " +
+			"(isolated: fail, changed with seeds: 1)\n" +
+			"\tpasses only after TestA/setup\n" +
+			"\norder dependent: TestA/polluted " +
+			"(isolated: pass, changed with seeds: 1, 3)\n" +
+			"\tfails after TestA/writer (confirmed)\n",
+	},
+}
+
+func TestReport(t *testing.T) {
+	test.Map(t, testReportParams).
+		Run(func(t test.Test, param ReportParams) {
+			// Given
+			builder := &strings.Builder{}
+
+			// When
+			err := order.Report(builder, param.cases)
+
+			// Then
+			assert.NoError(t, err)
+			assert.Equal(t, param.expectReport, builder.String())
+			assert.Equal(t, param.expectDependents,
+				order.Dependents(param.cases))
+		})
+}
@@ -0,0 +1,202 @@
+package order
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/tkrop/go-testing/internal/gotest"
+	"github.com/tkrop/go-testing/internal/shuffle"
+)
+
+// Runner is a runner detecting test order dependencies of a package.
+type Runner struct {
+	// Dir the directory of the package under test.
+	Dir string
+	// Args the additional arguments for `go test`, e.g. `-timeout`.
+	Args []string
+	// Progress the optional writer to report progress of the test runs.
+	Progress io.Writer
+
+	// exec the function executing the tests in the package directory.
+	exec func(dir string, env []string, args ...string) ([]gotest.Event, error)
+}
+
+// NewRunner creates a new order dependency runner for the package in the
+// given directory using the given additional `go test` arguments.
+func NewRunner(dir string, args ...string) *Runner {
+	return &Runner{Dir: dir, Args: args, exec: gotest.RunEnv}
+}
+
+// Cases runs the tests of the package matching the given run pattern once and
+// returns the names of all test cases, i.e. the tests and sub-tests without
+// further sub-tests. If the pattern is empty, all tests are run.
+func (r *Runner) Cases(run string) ([]string, error) {
+	events, err := r.exec(r.Dir, nil, r.args(run)...)
+	if err != nil {
+		return nil, err
+	} else if gotest.BuildFailed(events) {
+		return nil, ErrBuild(gotest.Output(events, ""))
+	}
+
+	cases := append(append(gotest.Passed(events, true),
+		gotest.Failed(events, true)...), gotest.Unfinished(events, true)...)
+	sort.Strings(cases)
+	return cases, nil
+}
+
+// Run runs each of the given test cases in isolation followed by the given
+// number of shuffled test runs of the given test cases using the seeds
+// starting with the given seed. Afterwards, each order dependent test case is
+// rerun in isolation together with each of its suspects using the seed of the
+// first shuffled test run that changed the outcome, to confirm the suspects.
+// It returns the collected outcomes of the test cases sorted by name.
+func (r *Runner) Run(
+	run string, seed int64, runs int, names ...string,
+) ([]*Case, error) {
+	cases := make(map[string]*Case, len(names))
+	for _, name := range names {
+		events, err := r.exec(r.Dir, nil,
+			r.args(gotest.RunPattern(name))...)
+		if err != nil {
+			return nil, err
+		}
+		cases[name] = &Case{
+			Name: name, Isolated: &Outcome{
+				Passed: passed(events, name) && !gotest.PackageFailed(events),
+			},
+		}
+		r.progress("isolated %s: %s", name, cases[name].Isolated)
+	}
+
+	for index := 0; index < runs; index++ {
+		seed := seed + int64(index)
+		events, err := r.shuffled(run, seed)
+		if err != nil {
+			return nil, err
+		}
+
+		before := starts(events, cases)
+		for _, tcase := range cases {
+			if _, ok := before[tcase.Name]; !ok {
+				continue
+			}
+			tcase.Runs = append(tcase.Runs, &Outcome{
+				Seed: seed, Passed: passed(events, tcase.Name),
+				Before: before[tcase.Name],
+			})
+		}
+		r.progress("shuffled run with seed %d finished", seed)
+	}
+
+	result := make([]*Case, 0, len(cases))
+	for _, tcase := range cases {
+		result = append(result, tcase)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	for _, tcase := range result {
+		if err := r.subsets(tcase, cases); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// subsets reruns the given test case in isolation together with each of its
+// suspects using the seed of the first shuffled test run that changed the
+// outcome, if the test case is order dependent. The relative order of the test
+// cases is kept, since the shuffled order only depends on the seed.
+func (r *Runner) subsets(tcase *Case, cases map[string]*Case) error {
+	if !tcase.Dependent() {
+		return nil
+	}
+
+	seed := tcase.Changed()[0]
+	for _, suspect := range tcase.Suspects() {
+		events, err := r.shuffled(gotest.RunPattern(suspect, tcase.Name), seed)
+		if err != nil {
+			return err
+		}
+
+		outcome := &Outcome{
+			Seed: seed, Before: []string{},
+			Passed: passed(events, tcase.Name) &&
+				!gotest.PackageFailed(events),
+		}
+		for _, name := range starts(events, cases)[tcase.Name] {
+			if name == suspect {
+				outcome.Before = append(outcome.Before, suspect)
+			}
+		}
+		tcase.Subsets = append(tcase.Subsets, outcome)
+		r.progress("subset %s after %s with seed %d: %s",
+			tcase.Name, suspect, seed, outcome)
+	}
+	return nil
+}
+
+// shuffled runs the tests matching the given run pattern in shuffled test and
+// test case order using the given seed.
+func (r *Runner) shuffled(run string, seed int64) ([]gotest.Event, error) {
+	env := []string{shuffle.Env + "=" + strconv.FormatInt(seed, 10)}
+	events, err := r.exec(r.Dir, env, append(r.args(run),
+		"-shuffle="+strconv.FormatInt(seed, 10))...)
+	if err != nil {
+		return nil, err
+	} else if gotest.BuildFailed(events) {
+		return nil, ErrBuild(gotest.Output(events, ""))
+	}
+	return events, nil
+}
+
+// args returns the `go test` arguments used for the test runs.
+func (r *Runner) args(run string) []string {
+	args := append([]string{"-count=1"}, r.Args...)
+	if run != "" {
+		args = append(args, "-run="+run)
+	}
+	return args
+}
+
+// progress reports the progress of the test runs.
+func (r *Runner) progress(format string, args ...any) {
+	if r.Progress != nil {
+		fmt.Fprintf(r.Progress, format+"\n", args...)
+	}
+}
+
+// passed returns whether the test case with given name passed in the given
+// test events.
+func passed(events []gotest.Event, name string) bool {
+	for _, event := range events {
+		if event.Test == name {
+			switch event.Action {
+			case gotest.ActionPass, gotest.ActionSkip:
+				return true
+			case gotest.ActionFail:
+				return false
+			}
+		}
+	}
+	return false
+}
+
+// starts returns the names of the test cases that started before each of the
+// given test cases in the given test events.
+func starts(events []gotest.Event, cases map[string]*Case) map[string][]string {
+	started := []string{}
+	before := map[string][]string{}
+	for _, event := range events {
+		if event.Action != gotest.ActionRun {
+			continue
+		} else if _, ok := cases[event.Test]; ok {
+			before[event.Test] = append([]string{}, started...)
+			started = append(started, event.Test)
+		}
+	}
+	return before
+}
@@ -0,0 +1,240 @@
+package order
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tkrop/go-testing/internal/gotest"
+	"github.com/tkrop/go-testing/internal/shuffle"
+	"github.com/tkrop/go-testing/test"
+)
+
+// Test events for the discovery, isolated, and shuffled test runs.
+var (
+	eventsCases = []gotest.Event{
+		{Action: gotest.ActionRun, Test: "TestA"},
+		{Action: gotest.ActionRun, Test: "TestA/reader"},
+		{Action: gotest.ActionRun, Test: "TestA/writer"},
+		{Action: gotest.ActionPass, Test: "TestA/reader"},
+		{Action: gotest.ActionPass, Test: "TestA/writer"},
+		{Action: gotest.ActionPass, Test: "TestA"},
+		{Action: gotest.ActionPass},
+	}
+	eventsReader = []gotest.Event{
+		{Action: gotest.ActionRun, Test: "TestA/reader"},
+		{Action: gotest.ActionPass, Test: "TestA/reader"},
+	}
+	eventsWriter = []gotest.Event{
+		{Action: gotest.ActionRun, Test: "TestA/writer"},
+		{Action: gotest.ActionPass, Test: "TestA/writer"},
+	}
+	eventsPolluted = []gotest.Event{
+		{Action: gotest.ActionRun, Test: "TestA"},
+		{Action: gotest.ActionRun, Test: "TestA/writer"},
+		{Action: gotest.ActionRun, Test: "TestA/reader"},
+		{Action: gotest.ActionPass, Test: "TestA/writer"},
+		{Action: gotest.ActionOutput, Test: "TestA/reader", Output: "fail\n"},
+		{Action: gotest.ActionFail, Test: "TestA/reader"},
+		{Action: gotest.ActionFail, Test: "TestA"},
+		{Action: gotest.ActionFail},
+	}
+	eventsTimeout = []gotest.Event{
+		{Action: gotest.ActionRun, Test: "TestA"},
+		{Action: gotest.ActionRun, Test: "TestA/reader"},
+		{Action: gotest.ActionRun, Test: "TestA/writer"},
+		{Action: gotest.ActionPass, Test: "TestA/reader"},
+		{Action: gotest.ActionOutput, Output: "panic: test timed out\n"},
+		{Action: gotest.ActionFail},
+	}
+	eventsBuild = []gotest.Event{
+		{Action: gotest.ActionOutput, Output: "FAIL pkg [build failed]\n"},
+		{Action: gotest.ActionFail},
+	}
+)
+
+// newExec creates a fake test execution returning the given events for the
+// discovery run, the isolated test case events for the isolated runs, the
+// given events per seed for the shuffled runs, and the given events per run
+// pattern for the shuffled subset runs.
+func newExec(
+	t test.Test, cases []gotest.Event, runs map[string][]gotest.Event,
+	subsets map[string][]gotest.Event,
+) func(string, []string, ...string) ([]gotest.Event, error) {
+	return func(
+		dir string, env []string, args ...string,
+	) ([]gotest.Event, error) {
+		assert.Equal(t, "-count=1", args[0])
+		last := args[len(args)-1]
+		switch {
+		case strings.HasPrefix(last, "-shuffle="):
+			seed := strings.TrimPrefix(last, "-shuffle=")
+			assert.Equal(t, []string{shuffle.Env + "=" + seed}, env)
+			run := strings.TrimPrefix(args[len(args)-2], "-run=")
+			if events, ok := subsets[run]; ok {
+				return events, nil
+			}
+			return runs[seed], nil
+		case last == "-run="+gotest.RunPattern("TestA/reader"):
+			return eventsReader, nil
+		case last == "-run="+gotest.RunPattern("TestA/writer"):
+			return eventsWriter, nil
+		}
+		assert.Nil(t, env)
+		return cases, nil
+	}
+}
+
+type RunnerParams struct {
+	run          string
+	seed         int64
+	runs         map[string][]gotest.Event
+	subsets      map[string][]gotest.Event
+	cases        []gotest.Event
+	execErr      error
+	expectCases  []string
+	expectError  error
+	expectReport string
+}
+
+var testRunnerParams = map[string]RunnerParams{
+	"exec failing": {
+		execErr:     errors.New("exec failed"),
+		expectError: errors.New("exec failed"),
+	},
+	"cases build failed": {
+		cases:       eventsBuild,
+		expectError: ErrBuild("FAIL pkg [build failed]\n"),
+	},
+	"shuffled build failed": {
+		seed:        1,
+		cases:       eventsCases,
+		runs:        map[string][]gotest.Event{"1": eventsBuild},
+		expectCases: []string{"TestA/reader", "TestA/writer"},
+		expectError: ErrBuild("FAIL pkg [build failed]\n"),
+	},
+	"stable": {
+		run:   "TestA",
+		seed:  1,
+		cases: eventsCases,
+		runs: map[string][]gotest.Event{
+			"1": eventsCases, "2": eventsCases,
+		},
+		expectCases: []string{"TestA/reader", "TestA/writer"},
+		expectReport: "CASE          ISOLATED  RUNS  FAILED\n" +
+			"TestA/reader  pass      2     0\n" +
+			"TestA/writer  pass      2     0\n",
+	},
+	"cases timeout": {
+		seed:        1,
+		cases:       eventsTimeout,
+		runs:        map[string][]gotest.Event{"1": eventsCases},
+		expectCases: []string{"TestA/reader", "TestA/writer"},
+		expectReport: "CASE          ISOLATED  RUNS  FAILED\n" +
+			"TestA/reader  pass      1     0\n" +
+			"TestA/writer  pass      1     0\n",
+	},
+	"order dependent": {
+		seed:  7,
+		cases: eventsCases,
+		runs: map[string][]gotest.Event{
+			"7": eventsCases, "8": eventsPolluted,
+		},
+		subsets: map[string][]gotest.Event{
+			"^TestA$/^(writer|reader)$": eventsCases,
+		},
+		expectCases: []string{"TestA/reader", "TestA/writer"},
+		expectReport: "CASE          ISOLATED  RUNS  FAILED\n" +
+			"TestA/reader  pass      2     1\n" +
+			"TestA/writer  pass      2     0\n" +
+			"\norder dependent: TestA/reader " +
+			"(isolated: pass, changed with seeds: 8)\n" +
+			"\tfails after TestA/writer\n",
+	},
+	"order dependent confirmed": {
+		seed:  7,
+		cases: eventsCases,
+		runs: map[string][]gotest.Event{
+			"7": eventsCases, "8": eventsPolluted,
+		},
+		subsets: map[string][]gotest.Event{
+			"^TestA$/^(writer|reader)$": eventsPolluted,
+		},
+		expectCases: []string{"TestA/reader", "TestA/writer"},
+		expectReport: "CASE          ISOLATED  RUNS  FAILED\n" +
+			"TestA/reader  pass      2     1\n" +
+			"TestA/writer  pass      2     0\n" +
+			"\norder dependent: TestA/reader " +
+			"(isolated: pass, changed with seeds: 8)\n" +
+			"\tfails after TestA/writer (confirmed)\n",
+	},
+	"subset build failed": {
+		seed:  7,
+		cases: eventsCases,
+		runs: map[string][]gotest.Event{
+			"7": eventsCases, "8": eventsPolluted,
+		},
+		subsets: map[string][]gotest.Event{
+			"^TestA$/^(writer|reader)$": eventsBuild,
+		},
+		expectCases: []string{"TestA/reader", "TestA/writer"},
+		expectError: ErrBuild("FAIL pkg [build failed]\n"),
+	},
+}
+
+func TestRunner(t *testing.T) {
+	test.Map(t, testRunnerParams).
+		Run(func(t test.Test, param RunnerParams) {
+			// Given
+			runner := NewRunner(".")
+			runner.exec = newExec(t, param.cases, param.runs, param.subsets)
+			if param.execErr != nil {
+				runner.exec = func(
+					string, []string, ...string,
+				) ([]gotest.Event, error) {
+					return nil, param.execErr
+				}
+			}
+
+			// When
+			cases, err := runner.Cases(param.run)
+			if err == nil {
+				assert.Equal(t, param.expectCases, cases)
+				var result []*Case
+				result, err = runner.Run(param.run, param.seed, 2, cases...)
+				if err == nil {
+					builder := &strings.Builder{}
+					err = Report(builder, result)
+					assert.Equal(t, param.expectReport, builder.String())
+				}
+			}
+
+			// Then
+			assert.Equal(t, param.expectError, err)
+		})
+}
+
+func TestRunnerProgress(t *testing.T) {
+	// Given
+	builder := &strings.Builder{}
+	runner := NewRunner(".")
+	runner.Progress = builder
+	runner.exec = newExec(t, eventsCases, map[string][]gotest.Event{
+		"3": eventsPolluted,
+	}, map[string][]gotest.Event{
+		"^TestA$/^(writer|reader)$": eventsPolluted,
+	})
+
+	// When
+	_, err := runner.Run("", 3, 1, "TestA/reader", "TestA/writer")
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, "isolated TestA/reader: pass\n"+
+		"isolated TestA/writer: pass\n"+
+		"shuffled run with seed 3 finished\n"+
+		"subset TestA/reader after TestA/writer with seed 3: fail\n",
+		builder.String())
+}
@@ -17,12 +17,56 @@ type (
 	Value = reflect.Value
 	// Type alias for `reflect.Type`.
 	Type = reflect.Type
+	// Kind alias for `reflect.Kind`.
+	Kind = reflect.Kind
 )
 
 // Aliases for constant values.
 const (
 	// Func alias for `reflect.Func`.
 	Func = reflect.Func
+	// Pointer alias for `reflect.Pointer`.
+	Pointer = reflect.Pointer
+	// Interface alias for `reflect.Interface`.
+	Interface = reflect.Interface
+	// Struct alias for `reflect.Struct`.
+	Struct = reflect.Struct
+	// Slice alias for `reflect.Slice`.
+	Slice = reflect.Slice
+	// Array alias for `reflect.Array`.
+	Array = reflect.Array
+	// Map alias for `reflect.Map`.
+	Map = reflect.Map
+	// Int alias for `reflect.Int`.
+	Int = reflect.Int
+	// Int8 alias for `reflect.Int8`.
+	Int8 = reflect.Int8
+	// Int16 alias for `reflect.Int16`.
+	Int16 = reflect.Int16
+	// Int32 alias for `reflect.Int32`.
+	Int32 = reflect.Int32
+	// Int64 alias for `reflect.Int64`.
+	Int64 = reflect.Int64
+	// Uint alias for `reflect.Uint`.
+	Uint = reflect.Uint
+	// Uint8 alias for `reflect.Uint8`.
+	Uint8 = reflect.Uint8
+	// Uint16 alias for `reflect.Uint16`.
+	Uint16 = reflect.Uint16
+	// Uint32 alias for `reflect.Uint32`.
+	Uint32 = reflect.Uint32
+	// Uint64 alias for `reflect.Uint64`.
+	Uint64 = reflect.Uint64
+	// Float32 alias for `reflect.Float32`.
+	Float32 = reflect.Float32
+	// Float64 alias for `reflect.Float64`.
+	Float64 = reflect.Float64
+	// String alias for `reflect.String`.
+	String = reflect.String
+	// Chan alias for `reflect.Chan`.
+	Chan = reflect.Chan
+	// UnsafePointer alias for `reflect.UnsafePointer`.
+	UnsafePointer = reflect.UnsafePointer
 )
 
 // Aliases for function values.
@@ -31,6 +75,8 @@ var (
 	TypeOf = reflect.TypeOf
 	// ValueOf alias for `reflect.ValueOf`.
 	ValueOf = reflect.ValueOf
+	// DeepEqual alias for `reflect.DeepEqual`.
+	DeepEqual = reflect.DeepEqual
 )
 
 // FindArgOf find the first argument with one of the given field names matching
@@ -85,6 +131,64 @@ func FieldArgOf(v reflect.Value, i int) any {
 	return value
 }
 
+// MapArgsOf returns a copy of the given parameter struct or pointer to a
+// parameter struct, where all field arguments accepted by the given mapping
+// function are replaced by the mapped arguments, that must be assignable to
+// the fields. If no field argument is accepted, the given parameter is
+// returned without copying, i.e. a pointer parameter is returned unchanged.
+// Other parameter types are returned unchanged.
+func MapArgsOf[P any](param P, mapping func(arg any) (any, bool)) P {
+	v := reflect.ValueOf(param)
+	pointer := v.Kind() == reflect.Pointer && !v.IsNil() &&
+		v.Elem().Kind() == reflect.Struct
+	switch {
+	case pointer:
+		v = v.Elem()
+	case v.Kind() != reflect.Struct:
+		return param
+	case reflect.ValueOf(&param).Elem().Kind() == reflect.Struct:
+		// The parameter struct is already an addressable copy.
+		v = reflect.ValueOf(&param).Elem()
+	default:
+		// Make a copy to circumvent access restrictions.
+		vr := reflect.New(v.Type()).Elem()
+		vr.Set(v)
+		v = vr
+	}
+
+	args := map[int]any{}
+	for i := 0; i < v.NumField(); i++ {
+		if arg, ok := mapping(fieldOf(v, i).Interface()); ok {
+			args[i] = arg
+		}
+	}
+	if len(args) == 0 {
+		return param
+	}
+
+	if pointer {
+		// Make a copy to keep the given parameter struct unchanged.
+		vr := reflect.New(v.Type()).Elem()
+		vr.Set(v)
+		v = vr
+	}
+	for i, arg := range args {
+		fieldOf(v, i).Set(reflect.ValueOf(arg))
+	}
+
+	if pointer {
+		return v.Addr().Interface().(P)
+	}
+	return v.Interface().(P)
+}
+
+// fieldOf returns the settable `i`th field of the given addressable struct
+// value circumventing access restrictions.
+func fieldOf(v reflect.Value, i int) reflect.Value {
+	vf := v.Field(i)
+	return reflect.NewAt(vf.Type(), unsafe.Pointer(vf.UnsafeAddr())).Elem()
+}
+
 // ArgOf returns the argument of the given value.
 func ArgOf(v reflect.Value) any {
 	if !v.IsValid() {
@@ -145,6 +249,79 @@ func ArgsOf(values ...reflect.Value) []any {
 	return args
 }
 
+// ArgsIn returns the arguments slice for the given input values of a call to
+// a function of the given type. In contrast to `ArgsOf` the variadic argument
+// slice of a variadic function is expanded into single arguments.
+func ArgsIn(ftype reflect.Type, values ...reflect.Value) []any {
+	num := len(values)
+	if !ftype.IsVariadic() || num == 0 {
+		return ArgsOf(values...)
+	}
+
+	vargs := values[num-1]
+	args := make([]any, 0, num-1+vargs.Len())
+	args = append(args, ArgsOf(values[:num-1]...)...)
+	for i := 0; i < vargs.Len(); i++ {
+		args = append(args, ArgOf(vargs.Index(i)))
+	}
+
+	if len(args) == 0 {
+		return nil
+	}
+	return args
+}
+
+// Assign assigns the given value to the target referenced by the given
+// argument. If the argument is a pointer, the value is assigned to the
+// referenced element; if it is a slice, the value slice elements are copied
+// into the slice; and if it is a map, the value map entries are added to the
+// map. If the value cannot be assigned the function panics.
+func Assign(arg any, value any) {
+	target := reflect.ValueOf(arg)
+	switch target.Kind() {
+	case reflect.Pointer:
+		if !target.IsNil() {
+			elem := target.Elem()
+			elem.Set(valueOf(elem.Type(), value, arg))
+			return
+		}
+	case reflect.Slice:
+		source := valueOf(target.Type(), value, arg)
+		reflect.Copy(target, source)
+		return
+	case reflect.Map:
+		if !target.IsNil() {
+			source := valueOf(target.Type(), value, arg)
+			iter := source.MapRange()
+			for iter.Next() {
+				target.SetMapIndex(iter.Key(), iter.Value())
+			}
+			return
+		}
+	}
+	panic(ErrNotAssignable(arg, value))
+}
+
+// valueOf returns the reflection value of the given value assignable to the
+// given type. If the value is not assignable the function panics.
+func valueOf(t reflect.Type, value any, arg any) reflect.Value {
+	if value == nil {
+		return reflect.Zero(t)
+	}
+	v := reflect.ValueOf(value)
+	if !v.Type().AssignableTo(t) {
+		panic(ErrNotAssignable(arg, value))
+	}
+	return v
+}
+
+// ErrNotAssignable creates a new error reporting that the given value is not
+// assignable to the target referenced by the given argument.
+func ErrNotAssignable(arg any, value any) error {
+	return fmt.Errorf("value [%v] of type %T not assignable to %T",
+		value, value, arg)
+}
+
 // ValuesIn returns the reflection values matching the input arguments of the
 // given function.
 func ValuesIn(ftype reflect.Type, args ...any) []reflect.Value {
@@ -267,8 +444,5 @@ func BaseFuncOf(mtype reflect.Type, in, out int) reflect.Type {
 func MakeFuncOf(
 	mtype reflect.Type, call func([]reflect.Value) []reflect.Value,
 ) any {
-	mvalue := reflect.New(mtype).Elem()
-	mvalue.Set(reflect.MakeFunc(mtype, call))
-
-	return mvalue.Interface()
+	return reflect.MakeFunc(mtype, call).Interface()
 }
@@ -2,6 +2,7 @@ package reflect_test
 
 import (
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/tkrop/go-testing/internal/reflect"
@@ -203,6 +204,106 @@ func TestFindArgOf(t *testing.T) {
 		})
 }
 
+type MapArgsOfParams struct {
+	value   any
+	mapping func(arg any) (any, bool)
+	expect  any
+}
+
+// upper maps string arguments to upper case.
+func upper(arg any) (any, bool) {
+	if value, ok := arg.(string); ok {
+		return strings.ToUpper(value), true
+	}
+	return nil, false
+}
+
+var testMapArgsOfParams = map[string]MapArgsOfParams{
+	"no struct": {
+		value:   "value",
+		mapping: upper,
+		expect:  "value",
+	},
+	"nil": {
+		value:   nil,
+		mapping: upper,
+		expect:  nil,
+	},
+	"unexported field": {
+		value:   StringParams{value: "value"},
+		mapping: upper,
+		expect:  StringParams{value: "VALUE"},
+	},
+	"exported field": {
+		value:   ExportParams{Value: "value"},
+		mapping: upper,
+		expect:  ExportParams{Value: "VALUE"},
+	},
+	"other field": {
+		value:   IntParams{value: 1},
+		mapping: upper,
+		expect:  IntParams{value: 1},
+	},
+	"nested field": {
+		value: StructParams{value: BoolParams{value: true}},
+		mapping: func(arg any) (any, bool) {
+			if _, ok := arg.(BoolParams); ok {
+				return BoolParams{value: false}, true
+			}
+			return nil, false
+		},
+		expect: StructParams{value: BoolParams{value: false}},
+	},
+	"pointer field": {
+		value:   stringParams,
+		mapping: upper,
+		expect:  &StringParams{value: "VALUE"},
+	},
+	"pointer other field": {
+		value:   intParams,
+		mapping: upper,
+		expect:  intParams,
+	},
+	"nil pointer": {
+		value:   (*StringParams)(nil),
+		mapping: upper,
+		expect:  (*StringParams)(nil),
+	},
+}
+
+// Pointer parameter structs expected to stay unchanged.
+var (
+	stringParams = &StringParams{value: "value"}
+	intParams    = &IntParams{value: 1}
+)
+
+func TestMapArgsOf(t *testing.T) {
+	test.Map(t, testMapArgsOfParams).
+		Run(func(t test.Test, param MapArgsOfParams) {
+			// When
+			result := reflect.MapArgsOf(param.value, param.mapping)
+
+			// Then
+			assert.Equal(t, param.expect, result)
+			assert.Equal(t, &StringParams{value: "value"}, stringParams)
+			if param.expect == intParams {
+				assert.Same(t, intParams, result)
+			}
+		})
+}
+
+func TestMapArgsOfTyped(t *testing.T) {
+	// Given
+	param := StringParams{value: "value"}
+
+	// When
+	result := reflect.MapArgsOf(param, upper)
+
+	// Then
+	assert.Equal(t, StringParams{value: "VALUE"}, result)
+	assert.Equal(t, StringParams{value: "value"}, param)
+}
+
 type ArgOfParams struct {
 	value  reflect.Value
 	expect any
@@ -778,3 +879,147 @@ func TestMakeFuncOf(t *testing.T) {
 			assert.Equal(t, param.result, result)
 		})
 }
+
+type ArgsInParams struct {
+	call   any
+	args   []any
+	expect []any
+}
+
+var testArgsInParams = map[string]ArgsInParams{
+	"none": {
+		call:   func() {},
+		args:   []any{},
+		expect: nil,
+	},
+	"plain": {
+		call:   func(int, string) {},
+		args:   []any{1, "value"},
+		expect: []any{1, "value"},
+	},
+	"variadic-empty": {
+		call:   func(int, ...string) {},
+		args:   []any{1},
+		expect: []any{1},
+	},
+	"variadic-only-empty": {
+		call:   func(...string) {},
+		args:   []any{},
+		expect: nil,
+	},
+	"variadic": {
+		call:   func(int, ...string) {},
+		args:   []any{1, "a", "b"},
+		expect: []any{1, "a", "b"},
+	},
+}
+
+func TestArgsIn(t *testing.T) {
+	test.Map(t, testArgsInParams).
+		Run(func(t test.Test, param ArgsInParams) {
+			// Given
+			ftype := reflect.TypeOf(param.call)
+			var values []reflect.Value
+			call := reflect.ValueOf(reflect.MakeFuncOf(ftype,
+				func(in []reflect.Value) []reflect.Value {
+					values = in
+					return nil
+				}))
+			call.Call(reflect.ValuesIn(ftype, param.args...))
+
+			// When
+			args := reflect.ArgsIn(ftype, values...)
+
+			// Then
+			assert.Equal(t, param.expect, args)
+		})
+}
+
+type AssignParams struct {
+	setup       mock.SetupFunc
+	arg         any
+	value       any
+	expect      test.Expect
+	expectValue any
+}
+
+var testAssignParams = map[string]AssignParams{
+	"pointer": {
+		arg:         new(string),
+		value:       "value",
+		expectValue: &teststring,
+		expect:      test.Success,
+	},
+	"pointer-nil-value": {
+		arg:         &ExportParams{Value: "value"},
+		value:       nil,
+		expectValue: &ExportParams{},
+		expect:      test.Success,
+	},
+	"pointer-interface": {
+		arg:         new(any),
+		value:       1,
+		expectValue: func() *any { var v any = 1; return &v }(),
+		expect:      test.Success,
+	},
+	"slice": {
+		arg:         make([]string, 1),
+		value:       []string{"value"},
+		expectValue: testslice,
+		expect:      test.Success,
+	},
+	"slice-shorter": {
+		arg:         []string{"a", "b"},
+		value:       []string{"value"},
+		expectValue: []string{"value", "b"},
+		expect:      test.Success,
+	},
+	"map": {
+		arg:         map[string]string{},
+		value:       testmap,
+		expectValue: testmap,
+		expect:      test.Success,
+	},
+
+	"pointer-nil": {
+		setup: test.Panic(reflect.ErrNotAssignable(
+			(*string)(nil), "value")),
+		arg:   (*string)(nil),
+		value: "value",
+	},
+	"pointer-type": {
+		setup: test.Panic(reflect.ErrNotAssignable(new(int), "value")),
+		arg:   new(int),
+		value: "value",
+	},
+	"slice-type": {
+		setup: test.Panic(reflect.ErrNotAssignable(testslice, []int{1})),
+		arg:   testslice,
+		value: []int{1},
+	},
+	"map-nil": {
+		setup: test.Panic(reflect.ErrNotAssignable(
+			map[string]string(nil), testmap)),
+		arg:   map[string]string(nil),
+		value: testmap,
+	},
+	"value": {
+		setup: test.Panic(reflect.ErrNotAssignable(testint, 2)),
+		arg:   testint,
+		value: 2,
+	},
+}
+
+func TestAssign(t *testing.T) {
+	test.Map(t, testAssignParams).
+		Run(func(t test.Test, param AssignParams) {
+			// Given
+			mock.NewMock(t).Expect(param.setup)
+
+			// When
+			reflect.Assign(param.arg, param.value)
+
+			// Then
+			assert.Equal(t, param.expectValue, param.arg)
+		})
+}
@@ -0,0 +1,161 @@
+package report
+
+import (
+	htmltemplate "html/template"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// funcs contains the helper functions used by the report templates.
+var funcs = map[string]any{
+	"cell":     cell,
+	"status":   status,
+	"expect":   expect,
+	"messages": messages,
+	"join":     strings.Join,
+}
+
+// markdown is the template to render the test report in Markdown.
+var markdown = template.Must(template.New("markdown").Funcs(funcs).Parse(
+	`# Test report
+
+| Passed | Failed | Skipped | Inverted |
+|-------:|-------:|--------:|---------:|
+| {{.Summary "pass"}} | {{.Summary "fail"}} | {{.Summary "skip"}} | {{.Inverted}} |
+{{- range .Packages}}
+
+## {{.Name}} ({{status .Status}}, {{printf "%.2fs" .Elapsed}})
+{{- if .Output}}
+
+` + "```" + `text
+{{join .Output "\n"}}
+` + "```" + `
+{{- end}}
+{{- range .Tables}}
+
+### {{.Name}} ({{status .Status}})
+{{- if .Cases}}
+
+| Case | Status | Expectation | Mock diagnostics |
+|------|--------|-------------|------------------|
+{{- range .Cases}}
+| {{cell .Name}} | {{status .Status}} | {{expect .}} | {{cell (messages (.Filter "mock"))}} |
+{{- end}}
+{{- end}}
+{{- with .Case}}{{if .Messages}}
+
+` + "```" + `text
+{{messages .Messages}}
+` + "```" + `
+{{- end}}{{end}}
+{{- $table := .Name}}
+{{- range .Cases}}{{if .Messages}}
+
+#### {{$table}}/{{.Name}}
+
+` + "```" + `text
+{{messages .Messages}}
+` + "```" + `
+{{- end}}{{end}}
+{{- end}}
+{{- end}}
+`))
+
+// html is the template to render the test report in HTML.
+var html = htmltemplate.Must(htmltemplate.New("html").Funcs(funcs).Parse(
+	`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Test report</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 2px 8px; text-align: left; vertical-align: top; }
+pre { background: #f4f4f4; padding: 4px; margin: 0; white-space: pre-wrap; }
+.pass { color: #2a7a2a; }
+.fail { color: #b00020; font-weight: bold; }
+.skip, .run { color: #8a6d00; }
+tr.inverted { background: #fff3cd; }
+</style>
+</head>
+<body>
+<h1>Test report</h1>
+<table>
+<tr><th>Passed</th><th>Failed</th><th>Skipped</th><th>Inverted</th></tr>
+<tr><td>{{.Summary "pass"}}</td><td>{{.Summary "fail"}}</td><td>{{.Summary "skip"}}</td><td>{{.Inverted}}</td></tr>
+</table>
+{{- range .Packages}}
+<h2>{{.Name}} <span class="{{.Status}}">{{.Status}}</span> {{printf "%.2fs" .Elapsed}}</h2>
+{{- if .Output}}
+<pre>{{join .Output "\n"}}</pre>
+{{- end}}
+{{- range .Tables}}
+<h3>{{.Name}} <span class="{{.Status}}">{{.Status}}</span></h3>
+{{- with .Case}}{{if .Messages}}
+<pre>{{messages .Messages}}</pre>
+{{- end}}{{end}}
+{{- if .Cases}}
+<table>
+<tr><th>Case</th><th>Status</th><th>Expectation</th><th>Mock diagnostics</th><th>Messages</th></tr>
+{{- range .Cases}}
+<tr{{if .Inverted}} class="inverted"{{end}}><td>{{.Name}}</td><td class="{{.Status}}">{{.Status}}</td><td>{{with .Expectation}}expected {{.}}{{end}}</td><td>{{with .Filter "mock"}}<pre>{{messages .}}</pre>{{end}}</td><td>{{with .Messages}}<pre>{{messages .}}</pre>{{end}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- end}}
+{{- end}}
+</body>
+</html>
+`))
+
+// Markdown writes the test report in Markdown to the given writer.
+func (r *Report) Markdown(w io.Writer) error {
+	return markdown.Execute(w, r)
+}
+
+// HTML writes the test report in HTML to the given writer.
+func (r *Report) HTML(w io.Writer) error {
+	return html.Execute(w, r)
+}
+
+// status returns the Markdown representation of the given status emphasizing
+// failures.
+func status(status Status) string {
+	if status == Failed {
+		return "**" + string(status) + "**"
+	}
+	return string(status)
+}
+
+// expect returns the Markdown representation of the unmet expectation of the
+// given test case emphasizing unmet inverted expectations.
+func expect(tcase *Case) string {
+	if tcase.Inverted() {
+		return "**expected failure**"
+	} else if expect := tcase.Expectation(); expect != "" {
+		return "expected " + expect
+	}
+	return ""
+}
+
+// messages returns the concatenated text of the given messages including the
+// source location.
+func messages(msgs []*Message) string {
+	lines := make([]string, 0, len(msgs))
+	for _, msg := range msgs {
+		if msg.Source != "" {
+			lines = append(lines, msg.Source+": "+msg.Text)
+		} else {
+			lines = append(lines, msg.Text)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// cell escapes the given text to be used in a Markdown table cell.
+func cell(text string) string {
+	text = strings.ReplaceAll(text, "|", "\\|")
+	return strings.ReplaceAll(text, "\n", "<br>")
+}
@@ -0,0 +1,135 @@
+package report_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tkrop/go-testing/internal/report"
+)
+
+// expectMarkdown is the expected Markdown report of the test events using
+// triple single quotes as replacement for the code fences.
+const expectMarkdown = `# Test report
+
+| Passed | Failed | Skipped | Inverted |
+|-------:|-------:|--------:|---------:|
+| 1 | 2 | 1 | 1 |
+
+## other (**fail**, 0.00s)
+
+'''text
+./file.go:1:1: syntax error
+'''
+
+## pkg (**fail**, 0.50s)
+
+'''text
+panic: test timed out
+'''
+
+### TestA (**fail**)
+
+| Case | Status | Expectation | Mock diagnostics |
+|------|--------|-------------|------------------|
+| failure | **fail** | **expected failure** |  |
+| mock | **fail** | expected success | controller.go:231: Unexpected call to *mock.MockIFace.CallA([]) at file.go:12 because:<br>there are no expected calls of the method "CallA" for that receiver |
+| success | pass |  |  |
+
+#### TestA/failure
+
+'''text
+testing.go:249: Expected test to fail but it succeeded: TestA/failure
+'''
+
+#### TestA/mock
+
+'''text
+controller.go:231: Unexpected call to *mock.MockIFace.CallA([]) at file.go:12 because:
+there are no expected calls of the method "CallA" for that receiver
+testing.go:249: Expected test to succeed but it failed: TestA/mock
+'''
+
+#### TestA/success
+
+'''text
+file_test.go:10: log message
+'''
+
+### TestB (skip)
+
+'''text
+plain output
+file_test.go:20: continued | message
+'''
+
+### TestC (run)
+`
+
+func TestMarkdown(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	result := report.Build(parse(t, testEvents))
+	builder := &strings.Builder{}
+
+	// When
+	err := result.Markdown(builder)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, strings.ReplaceAll(expectMarkdown, "'''", "```"),
+		builder.String())
+}
+
+func TestMarkdownEscape(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	result := &report.Report{Packages: []*report.Package{{
+		Name: "pkg", Status: report.Passed,
+		Tables: []*report.Table{{
+			Case: report.Case{Name: "TestA", Status: report.Passed},
+			Cases: []*report.Case{{
+				Name: "a|b", Status: report.Passed,
+				Messages: []*report.Message{{
+					Kind: report.Mock, Text: "x|y\nz",
+				}},
+			}},
+		}},
+	}}}
+	builder := &strings.Builder{}
+
+	// When
+	err := result.Markdown(builder)
+
+	// Then
+	require.NoError(t, err)
+	assert.Contains(t, builder.String(), "| a\\|b | pass |  | x\\|y<br>z |\n")
+}
+
+func TestHTML(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	result := report.Build(parse(t, testEvents))
+	builder := &strings.Builder{}
+
+	// When
+	err := result.HTML(builder)
+
+	// Then
+	require.NoError(t, err)
+	html := builder.String()
+	assert.Contains(t, html, "<tr><td>1</td><td>2</td><td>1</td><td>1</td></tr>")
+	assert.Contains(t, html, "<h2>pkg <span class=\"fail\">fail</span> 0.50s</h2>")
+	assert.Contains(t, html, "<tr class=\"inverted\"><td>failure</td>"+
+		"<td class=\"fail\">fail</td><td>expected failure</td>")
+	assert.Contains(t, html, "<tr><td>mock</td><td class=\"fail\">fail</td>"+
+		"<td>expected success</td><td><pre>controller.go:231: Unexpected call")
+	assert.Contains(t, html, "method &#34;CallA&#34; for that receiver")
+	assert.Contains(t, html, "<pre>plain output\nfile_test.go:20: continued")
+	assert.True(t, strings.HasSuffix(html, "</body>\n</html>\n"))
+}
@@ -0,0 +1,334 @@
+// Package report contains a small engine to build a structured test report
+// from the test events provided via `go test -json`. The report groups the
+// test output by package, test table, and test case, classifies messages into
+// normal logs and mock diagnostics, and evaluates the structured result records
+// of unmet expectations reported by the isolated `test.Tester`. It is currently
+// not part of the public interface and must be consider as highly instable.
+package report
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/tkrop/go-testing/internal/gotest"
+	"github.com/tkrop/go-testing/internal/result"
+)
+
+// Status is the final status of a test.
+type Status string
+
+// Status values of tests.
+const (
+	// Passed status of a test that has passed.
+	Passed Status = "pass"
+	// Failed status of a test that has failed.
+	Failed Status = "fail"
+	// Skipped status of a test that was skipped.
+	Skipped Status = "skip"
+	// Running status of a test that never finished, e.g. due to a timeout.
+	Running Status = "run"
+)
+
+// Kind is the kind of a test message.
+type Kind string
+
+// Kinds of test messages.
+const (
+	// Log kind of a normal test log message.
+	Log Kind = "log"
+	// Mock kind of a mock diagnostic message reported by `gomock`.
+	Mock Kind = "mock"
+)
+
+// mockMarkers contains the markers of mock diagnostic messages.
+var mockMarkers = []string{
+	"Unexpected call to",
+	"missing call(s) to",
+	"aborting test due to missing call(s)",
+	"Controller.Finish was called more than once",
+	"gomock:",
+}
+
+var (
+	// frameRegex matches the framing lines of the test output.
+	frameRegex = regexp.MustCompile(
+		`^\s*(=== (RUN|PAUSE|CONT|NAME)\s|--- (PASS|FAIL|SKIP):\s|` +
+			`(PASS|FAIL)$|ok\s|FAIL\s)`)
+	// sourceRegex matches the start of a test message with source location.
+	sourceRegex = regexp.MustCompile(`^\s+([\w./-]+\.go:\d+):(?: (.*))?$`)
+)
+
+// Message is a single message reported by a test.
+type Message struct {
+	// Kind the kind of the message.
+	Kind Kind
+	// Source the source location of the message, if available.
+	Source string
+	// Text the text of the message.
+	Text string
+}
+
+// Case is a test case, i.e. a sub-test of a test table.
+type Case struct {
+	// Name the name of the test case relative to the test table.
+	Name string
+	// Status the final status of the test case.
+	Status Status
+	// Elapsed the elapsed time in seconds.
+	Elapsed float64
+	// Messages the messages reported by the test case.
+	Messages []*Message
+	// Result the result record of an unmet expectation reported by the test
+	// case, if any.
+	Result *result.Result
+}
+
+// Expectation returns the unmet expectation of the test case, i.e. `failure`
+// or `success`, or an empty string, if the expectation was met.
+func (c *Case) Expectation() string {
+	if c.Result != nil && c.Result.Unmet() {
+		return c.Result.Expect
+	}
+	return ""
+}
+
+// Inverted returns whether the test case has an unmet inverted expectation,
+// i.e. the test case was expected to fail but succeeded.
+func (c *Case) Inverted() bool {
+	return c.Expectation() == result.Failure
+}
+
+// Filter returns the messages of the test case with the given kind.
+func (c *Case) Filter(kind Kind) []*Message {
+	msgs := []*Message{}
+	for _, msg := range c.Messages {
+		if msg.Kind == kind {
+			msgs = append(msgs, msg)
+		}
+	}
+	return msgs
+}
+
+// Table is a test table, i.e. a top-level test function with its test cases.
+type Table struct {
+	Case
+	// Cases the test cases of the test table sorted by name.
+	Cases []*Case
+}
+
+// Package is a tested package with its test tables.
+type Package struct {
+	// Name the name of the package.
+	Name string
+	// Status the final status of the package.
+	Status Status
+	// Elapsed the elapsed time in seconds.
+	Elapsed float64
+	// Output the output of the package not belonging to any test.
+	Output []string
+	// Tables the test tables of the package sorted by name.
+	Tables []*Table
+}
+
+// Report is a test report of a test run.
+type Report struct {
+	// Packages the tested packages sorted by name.
+	Packages []*Package
+}
+
+// Build builds the test report from the given test events.
+func Build(events []gotest.Event) *Report {
+	builder := newBuilder()
+	for _, event := range events {
+		builder.add(event)
+	}
+	return builder.report()
+}
+
+// Summary returns the number of tests cases with the given status, counting
+// test tables without test cases as single test case.
+func (r *Report) Summary(status Status) int {
+	count := 0
+	for _, pkg := range r.Packages {
+		for _, table := range pkg.Tables {
+			if len(table.Cases) == 0 && table.Status == status {
+				count++
+			}
+			for _, tcase := range table.Cases {
+				if tcase.Status == status {
+					count++
+				}
+			}
+		}
+	}
+	return count
+}
+
+// Inverted returns the number of test cases with unmet inverted expectation,
+// i.e. test cases that were expected to fail but succeeded.
+func (r *Report) Inverted() int {
+	count := 0
+	for _, pkg := range r.Packages {
+		for _, table := range pkg.Tables {
+			if table.Inverted() {
+				count++
+			}
+			for _, tcase := range table.Cases {
+				if tcase.Inverted() {
+					count++
+				}
+			}
+		}
+	}
+	return count
+}
+
+// builder is the stateful test report builder.
+type builder struct {
+	packages map[string]*Package
+	tables   map[string]*Table
+	cases    map[string]*Case
+	current  map[*Case]*Message
+}
+
+// newBuilder creates a new test report builder.
+func newBuilder() *builder {
+	return &builder{
+		packages: map[string]*Package{},
+		tables:   map[string]*Table{},
+		cases:    map[string]*Case{},
+		current:  map[*Case]*Message{},
+	}
+}
+
+// add adds the given test event to the test report.
+func (b *builder) add(event gotest.Event) {
+	if event.Action == gotest.ActionBuildOutput {
+		name, _, _ := strings.Cut(event.ImportPath, " ")
+		pkg := b.pkg(name)
+		pkg.Output = append(pkg.Output, strings.TrimRight(event.Output, "\n"))
+		return
+	}
+
+	pkg := b.pkg(event.Package)
+	if event.Test == "" {
+		switch event.Action {
+		case gotest.ActionOutput:
+			if !frameRegex.MatchString(event.Output) {
+				pkg.Output = append(pkg.Output,
+					strings.TrimRight(event.Output, "\n"))
+			}
+		case gotest.ActionPass, gotest.ActionFail, gotest.ActionSkip:
+			pkg.Status, pkg.Elapsed = Status(event.Action), event.Elapsed
+		}
+		return
+	}
+
+	tcase := b.test(pkg, event.Test)
+	switch event.Action {
+	case gotest.ActionOutput:
+		b.output(tcase, event.Output)
+	case gotest.ActionPass, gotest.ActionFail, gotest.ActionSkip:
+		tcase.Status, tcase.Elapsed = Status(event.Action), event.Elapsed
+	}
+}
+
+// pkg resolves the package with given name creating it on demand.
+func (b *builder) pkg(name string) *Package {
+	pkg, ok := b.packages[name]
+	if !ok {
+		pkg = &Package{Name: name, Status: Running}
+		b.packages[name] = pkg
+	}
+	return pkg
+}
+
+// test resolves the test case with given name creating the test table and the
+// test case on demand. For top-level tests, the test table case is returned.
+func (b *builder) test(pkg *Package, name string) *Case {
+	tname, cname, _ := strings.Cut(name, "/")
+	key := pkg.Name + " " + tname
+	table, ok := b.tables[key]
+	if !ok {
+		table = &Table{Case: Case{Name: tname, Status: Running}}
+		b.tables[key] = table
+		pkg.Tables = append(pkg.Tables, table)
+	}
+	if cname == "" {
+		return &table.Case
+	}
+
+	key = pkg.Name + " " + name
+	tcase, ok := b.cases[key]
+	if !ok {
+		tcase = &Case{Name: cname, Status: Running}
+		b.cases[key] = tcase
+		table.Cases = append(table.Cases, tcase)
+	}
+	return tcase
+}
+
+// output adds the given output line to the messages of the test case. Lines
+// without source location are appended to the previous message, while result
+// records are evaluated as result of the test case.
+func (b *builder) output(tcase *Case, output string) {
+	line := strings.TrimRight(output, " \t\n")
+	if strings.TrimSpace(line) == "" || frameRegex.MatchString(line) {
+		return
+	}
+
+	if match := sourceRegex.FindStringSubmatch(line); match != nil {
+		if record := result.Parse(match[2]); record != nil {
+			tcase.Result = record
+			delete(b.current, tcase)
+			return
+		}
+		msg := &Message{Kind: kindOf(match[2]), Source: match[1], Text: match[2]}
+		tcase.Messages = append(tcase.Messages, msg)
+		b.current[tcase] = msg
+	} else if msg, ok := b.current[tcase]; ok {
+		if msg.Text != "" {
+			msg.Text += "\n"
+		}
+		msg.Text += strings.TrimSpace(line)
+		if msg.Kind == Log {
+			msg.Kind = kindOf(msg.Text)
+		}
+	} else {
+		msg := &Message{Kind: kindOf(line), Text: strings.TrimSpace(line)}
+		tcase.Messages = append(tcase.Messages, msg)
+		b.current[tcase] = msg
+	}
+}
+
+// report creates the sorted test report.
+func (b *builder) report() *Report {
+	report := &Report{Packages: make([]*Package, 0, len(b.packages))}
+	for _, pkg := range b.packages {
+		sort.Slice(pkg.Tables, func(i, j int) bool {
+			return pkg.Tables[i].Name < pkg.Tables[j].Name
+		})
+		for _, table := range pkg.Tables {
+			cases := table.Cases
+			sort.Slice(cases, func(i, j int) bool {
+				return cases[i].Name < cases[j].Name
+			})
+		}
+		report.Packages = append(report.Packages, pkg)
+	}
+	sort.Slice(report.Packages, func(i, j int) bool {
+		return report.Packages[i].Name < report.Packages[j].Name
+	})
+	return report
+}
+
+// kindOf returns the kind of the given message text.
+func kindOf(text string) Kind {
+	for _, marker := range mockMarkers {
+		if strings.Contains(text, marker) {
+			return Mock
+		}
+	}
+	return Log
+}
@@ -0,0 +1,168 @@
+package report_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tkrop/go-testing/internal/gotest"
+	"github.com/tkrop/go-testing/internal/report"
+	"github.com/tkrop/go-testing/internal/result"
+	"github.com/tkrop/go-testing/test"
+)
+
+const testEvents = `{"Action":"run","Package":"pkg","Test":"TestA"}
+{"Action":"output","Package":"pkg","Test":"TestA","Output":"=== RUN   TestA\n"}
+{"Action":"run","Package":"pkg","Test":"TestA/success"}
+{"Action":"output","Package":"pkg","Test":"TestA/success","Output":"=== RUN   TestA/success\n"}
+{"Action":"output","Package":"pkg","Test":"TestA/success","Output":"    file_test.go:10: log message\n"}
+{"Action":"pass","Package":"pkg","Test":"TestA/success","Elapsed":0.1}
+{"Action":"run","Package":"pkg","Test":"TestA/failure"}
+{"Action":"output","Package":"pkg","Test":"TestA/failure","Output":"    testing.go:312: test-result: {\"expect\":\"failure\",\"failed\":false}\n"}
+{"Action":"output","Package":"pkg","Test":"TestA/failure","Output":"    testing.go:249: Expected test to fail but it succeeded: TestA/failure\n"}
+{"Action":"output","Package":"pkg","Test":"TestA/failure","Output":"--- FAIL: TestA/failure (0.10s)\n"}
+{"Action":"fail","Package":"pkg","Test":"TestA/failure","Elapsed":0.1}
+{"Action":"run","Package":"pkg","Test":"TestA/mock"}
+{"Action":"output","Package":"pkg","Test":"TestA/mock","Output":"    controller.go:231: Unexpected call to *mock.MockIFace.CallA([]) at file.go:12 because: \n"}
+{"Action":"output","Package":"pkg","Test":"TestA/mock","Output":"        there are no expected calls of the method \"CallA\" for that receiver\n"}
+{"Action":"output","Package":"pkg","Test":"TestA/mock","Output":"    testing.go:307: test-result: {\"expect\":\"success\",\"failed\":true}\n"}
+{"Action":"output","Package":"pkg","Test":"TestA/mock","Output":"    testing.go:249: Expected test to succeed but it failed: TestA/mock\n"}
+{"Action":"fail","Package":"pkg","Test":"TestA/mock","Elapsed":0.1}
+{"Action":"fail","Package":"pkg","Test":"TestA","Elapsed":0.3}
+{"Action":"run","Package":"pkg","Test":"TestB"}
+{"Action":"output","Package":"pkg","Test":"TestB","Output":"plain output\n"}
+{"Action":"output","Package":"pkg","Test":"TestB","Output":"    file_test.go:20: \n"}
+{"Action":"output","Package":"pkg","Test":"TestB","Output":"        continued | message\n"}
+{"Action":"skip","Package":"pkg","Test":"TestB","Elapsed":0}
+{"Action":"run","Package":"pkg","Test":"TestC"}
+{"Action":"output","Package":"pkg","Output":"FAIL\n"}
+{"Action":"output","Package":"pkg","Output":"panic: test timed out\n"}
+{"Action":"fail","Package":"pkg","Elapsed":0.5}
+{"ImportPath":"other [other.test]","Action":"build-output","Output":"./file.go:1:1: syntax error\n"}
+{"Action":"output","Package":"other","Output":"FAIL\tother [build failed]\n"}
+{"Action":"fail","Package":"other","Elapsed":0}
+`
+
+// parse parses the given test events.
+func parse(t test.Test, input string) []gotest.Event {
+	events, err := gotest.Parse(strings.NewReader(input))
+	require.NoError(t, err)
+	return events
+}
+
+func TestBuild(t *testing.T) {
+	t.Parallel()
+
+	// When
+	build := report.Build(parse(t, testEvents))
+
+	// Then
+	assert.Equal(t, &report.Report{Packages: []*report.Package{{
+		Name:   "other",
+		Status: report.Failed,
+		Output: []string{"./file.go:1:1: syntax error"},
+	}, {
+		Name: "pkg", Status: report.Failed, Elapsed: 0.5,
+		Output: []string{"panic: test timed out"},
+		Tables: []*report.Table{{
+			Case: report.Case{
+				Name: "TestA", Status: report.Failed, Elapsed: 0.3,
+			},
+			Cases: []*report.Case{{
+				Name: "failure", Status: report.Failed, Elapsed: 0.1,
+				Messages: []*report.Message{{
+					Kind: report.Log, Source: "testing.go:249",
+					Text: "Expected test to fail but it succeeded: TestA/failure",
+				}},
+				Result: &result.Result{Expect: result.Failure},
+			}, {
+				Name: "mock", Status: report.Failed, Elapsed: 0.1,
+				Messages: []*report.Message{{
+					Kind: report.Mock, Source: "controller.go:231",
+					Text: "Unexpected call to *mock.MockIFace.CallA([]) " +
+						"at file.go:12 because:\nthere are no expected " +
+						"calls of the method \"CallA\" for that receiver",
+				}, {
+					Kind: report.Log, Source: "testing.go:249",
+					Text: "Expected test to succeed but it failed: TestA/mock",
+				}},
+				Result: &result.Result{Expect: result.Success, Failed: true},
+			}, {
+				Name: "success", Status: report.Passed, Elapsed: 0.1,
+				Messages: []*report.Message{{
+					Kind: report.Log, Source: "file_test.go:10",
+					Text: "log message",
+				}},
+			}},
+		}, {
+			Case: report.Case{
+				Name: "TestB", Status: report.Skipped,
+				Messages: []*report.Message{{
+					Kind: report.Log, Text: "plain output",
+				}, {
+					Kind: report.Log, Source: "file_test.go:20",
+					Text: "continued | message",
+				}},
+			},
+		}, {
+			Case: report.Case{Name: "TestC", Status: report.Running},
+		}},
+	}}}, build)
+}
+
+type ExpectationParams struct {
+	result         *result.Result
+	expectExpect   string
+	expectInverted bool
+}
+
+var testExpectationParams = map[string]ExpectationParams{
+	"no result": {},
+	"met success": {
+		result: &result.Result{Expect: result.Success},
+	},
+	"met failure": {
+		result: &result.Result{Expect: result.Failure, Failed: true},
+	},
+	"unmet failure": {
+		result:         &result.Result{Expect: result.Failure},
+		expectExpect:   result.Failure,
+		expectInverted: true,
+	},
+	"unmet success": {
+		result:       &result.Result{Expect: result.Success, Failed: true},
+		expectExpect: result.Success,
+	},
+}
+
+func TestExpectation(t *testing.T) {
+	test.Map(t, testExpectationParams).
+		Run(func(t test.Test, param ExpectationParams) {
+			// Given
+			tcase := &report.Case{Result: param.result}
+
+			// When
+			expect := tcase.Expectation()
+			inverted := tcase.Inverted()
+
+			// Then
+			assert.Equal(t, param.expectExpect, expect)
+			assert.Equal(t, param.expectInverted, inverted)
+		})
+}
+
+func TestSummary(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	build := report.Build(parse(t, testEvents))
+
+	// Then
+	assert.Equal(t, 1, build.Summary(report.Passed))
+	assert.Equal(t, 2, build.Summary(report.Failed))
+	assert.Equal(t, 1, build.Summary(report.Skipped))
+	assert.Equal(t, 1, build.Summary(report.Running))
+	assert.Equal(t, 1, build.Inverted())
+}
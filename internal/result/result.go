@@ -0,0 +1,57 @@
+// Package result contains the structured result records emitted by the
+// isolated test environment of `test.Tester` for test reports. The records
+// are logged as single marker lines, so that they are included in the test
+// events provided via `go test -json`. It is currently not part of the public
+// interface and must be consider as highly instable.
+package result
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Marker is the prefix of a logged test result record.
+const Marker = "test-result: "
+
+// Expectations of test results.
+const (
+	// Success expectation of a test expected to succeed.
+	Success = "success"
+	// Failure expectation of a test expected to fail.
+	Failure = "failure"
+)
+
+// Result is the structured result record of an isolated test.
+type Result struct {
+	// Expect the expectation of the test, i.e. `success` or `failure`.
+	Expect string `json:"expect"`
+	// Failed whether the test has failed.
+	Failed bool `json:"failed"`
+}
+
+// Unmet returns whether the expectation of the test result was unmet.
+func (r *Result) Unmet() bool {
+	return r.Failed == (r.Expect == Success)
+}
+
+// String returns the marker line of the test result record.
+func (r *Result) String() string {
+	data, _ := json.Marshal(r)
+	return Marker + string(data)
+}
+
+// Parse parses the test result record from the given marker line. If the line
+// is not a test result record, nil is returned.
+func Parse(line string) *Result {
+	if !strings.HasPrefix(line, Marker) {
+		return nil
+	}
+
+	result := &Result{}
+	if err := json.Unmarshal(
+		[]byte(strings.TrimPrefix(line, Marker)), result,
+	); err != nil {
+		return nil
+	}
+	return result
+}
@@ -0,0 +1,58 @@
+package result_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tkrop/go-testing/internal/result"
+	"github.com/tkrop/go-testing/test"
+)
+
+type ParseParams struct {
+	line        string
+	expect      *result.Result
+	expectUnmet bool
+}
+
+var testParseParams = map[string]ParseParams{
+	"no marker": {
+		line: "Expected test to fail but it succeeded: TestA",
+	},
+	"invalid record": {
+		line: result.Marker + "{invalid",
+	},
+	"met success": {
+		line:   result.Marker + `{"expect":"success","failed":false}`,
+		expect: &result.Result{Expect: result.Success},
+	},
+	"unmet success": {
+		line:        result.Marker + `{"expect":"success","failed":true}`,
+		expect:      &result.Result{Expect: result.Success, Failed: true},
+		expectUnmet: true,
+	},
+	"met failure": {
+		line:   result.Marker + `{"expect":"failure","failed":true}`,
+		expect: &result.Result{Expect: result.Failure, Failed: true},
+	},
+	"unmet failure": {
+		line:        result.Marker + `{"expect":"failure","failed":false}`,
+		expect:      &result.Result{Expect: result.Failure},
+		expectUnmet: true,
+	},
+}
+
+func TestParse(t *testing.T) {
+	test.Map(t, testParseParams).
+		Run(func(t test.Test, param ParseParams) {
+			// When
+			record := result.Parse(param.line)
+
+			// Then
+			assert.Equal(t, param.expect, record)
+			if record != nil {
+				assert.Equal(t, param.expectUnmet, record.Unmet())
+				assert.Equal(t, param.line, record.String())
+			}
+		})
+}
@@ -0,0 +1,9 @@
+// Package shuffle contains the shared definitions to enable shuffling of test
+// cases used by the test runners and the order dependency detection. It is
+// currently not part of the public interface and must be consider as highly
+// instable.
+package shuffle
+
+// Env is the environment variable to enable shuffling of test cases with the
+// given seed.
+const Env = "TEST_SHUFFLE"
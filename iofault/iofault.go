@@ -0,0 +1,289 @@
+// Package iofault contains a small framework for I/O fault injection, i.e.
+// readers and writers that fail after a number of bytes, perform short reads
+// and writes, block until released, or fail on close, to test the error
+// handling around streams. It is part of the public interface, however, we are
+// still experimenting to optimize the interface and the user experience.
+package iofault
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	gosync "sync"
+
+	"github.com/tkrop/go-testing/test"
+)
+
+// Option is an option to set up the faults and expectations of a stream.
+type Option func(*Stream)
+
+// FailAfter sets up the stream to fail with given error after the given
+// number of bytes was read or written.
+func FailAfter(n int64, err error) Option {
+	return func(s *Stream) {
+		s.failAt, s.failErr = n, err
+	}
+}
+
+// Short sets up the stream to transfer at most the given number of bytes per
+// read or write call. Short writes are reported using `io.ErrShortWrite`.
+func Short(n int) Option {
+	return func(s *Stream) {
+		s.short = n
+	}
+}
+
+// BlockAfter sets up the stream to block after the given number of bytes was
+// read or written until the stream is released via `Release`. Blocked writes
+// continue writing the remaining data after release. Streams are released
+// automatically on test cleanup.
+func BlockAfter(n int64) Option {
+	return func(s *Stream) {
+		s.blockAt = n
+	}
+}
+
+// CloseError sets up the stream to fail with given error on close.
+func CloseError(err error) Option {
+	return func(s *Stream) {
+		s.closeErr = err
+	}
+}
+
+// ExpectConsumed sets up the expectation that the data of the reader is
+// consumed completely. Unconsumed data is reported on test cleanup without
+// reading from the reader. For buffered readers providing `Len`, e.g.
+// `strings.Reader` or `bytes.Buffer`, the number of unconsumed bytes is
+// reported, while other readers are expected to be read until end of file.
+func ExpectConsumed() Option {
+	return func(s *Stream) {
+		s.consume = true
+	}
+}
+
+// ExpectClosed sets up the expectation that the stream is closed. Unclosed
+// streams are reported on test cleanup.
+func ExpectClosed() Option {
+	return func(s *Stream) {
+		s.close = true
+	}
+}
+
+// Stream is a fault injecting stream wrapping a reader and/or a writer.
+type Stream struct {
+	t        test.Test
+	mu       gosync.Mutex
+	reader   io.Reader
+	writer   io.Writer
+	read     int64
+	written  int64
+	eof      bool
+	closed   bool
+	short    int
+	failAt   int64
+	failErr  error
+	blockAt  int64
+	release  chan struct{}
+	once     gosync.Once
+	closeErr error
+	consume  bool
+	close    bool
+}
+
+// NewReader creates a new fault injecting reader reading from the given
+// reader.
+func NewReader(t test.Test, reader io.Reader, opts ...Option) *Stream {
+	return newStream(t, reader, nil, opts...)
+}
+
+// NewWriter creates a new fault injecting writer writing to the given writer.
+func NewWriter(t test.Test, writer io.Writer, opts ...Option) *Stream {
+	return newStream(t, nil, writer, opts...)
+}
+
+// NewReadWriteCloser creates a new fault injecting stream reading from the
+// given reader and writing to the given writer.
+func NewReadWriteCloser(
+	t test.Test, reader io.Reader, writer io.Writer, opts ...Option,
+) *Stream {
+	return newStream(t, reader, writer, opts...)
+}
+
+// newStream creates a new fault injecting stream and registers the validation
+// of the expectations on test cleanup.
+func newStream(
+	t test.Test, reader io.Reader, writer io.Writer, opts ...Option,
+) *Stream {
+	s := &Stream{
+		t: t, reader: reader, writer: writer,
+		failAt: -1, blockAt: -1, release: make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if c, ok := t.(test.Cleanuper); ok {
+		c.Cleanup(s.cleanup)
+	}
+	return s
+}
+
+// Read reads from the wrapped reader applying the set up faults.
+func (s *Stream) Read(p []byte) (int, error) {
+	if s.reader == nil {
+		return 0, ErrNotSupported("read")
+	}
+
+	n, err := s.transfer(&s.read, p, false, s.reader.Read)
+	if err == io.EOF {
+		s.mu.Lock()
+		s.eof = true
+		s.mu.Unlock()
+	}
+	return n, err
+}
+
+// Write writes to the wrapped writer applying the set up faults.
+func (s *Stream) Write(p []byte) (int, error) {
+	if s.writer == nil {
+		return 0, ErrNotSupported("write")
+	}
+	return s.transfer(&s.written, p, true, s.writer.Write)
+}
+
+// Close closes the stream returning the set up close error.
+func (s *Stream) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return s.closeErr
+}
+
+// Release releases the blocked reads and writes of the stream.
+func (s *Stream) Release() {
+	s.once.Do(func() { close(s.release) })
+}
+
+// BytesRead returns the number of bytes read from the stream.
+func (s *Stream) BytesRead() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.read
+}
+
+// BytesWritten returns the number of bytes written to the stream.
+func (s *Stream) BytesWritten() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.written
+}
+
+// transfer transfers the given data using the given operation applying the
+// set up short transfer, blocking, and failure faults.
+func (s *Stream) transfer(
+	count *int64, p []byte, write bool, op func([]byte) (int, error),
+) (int, error) {
+	s.mu.Lock()
+	limit, done := int64(len(p)), *count
+	if s.short > 0 && int64(s.short) < limit {
+		limit = int64(s.short)
+	}
+	s.mu.Unlock()
+
+	block := int64(-1)
+	if s.blockAt >= 0 {
+		if done >= s.blockAt {
+			<-s.release
+		} else if s.blockAt-done < limit && write {
+			block = s.blockAt - done
+		} else if s.blockAt-done < limit {
+			limit = s.blockAt - done
+		}
+	}
+
+	var failErr error
+	if s.failAt >= 0 {
+		if done >= s.failAt {
+			return 0, s.failErr
+		} else if s.failAt-done < limit {
+			limit, failErr = s.failAt-done, s.failErr
+		}
+	}
+
+	n, err := s.apply(count, p[:limit], block, op)
+	if err != nil {
+		return n, err
+	} else if write && failErr != nil {
+		return n, failErr
+	} else if write && n < len(p) {
+		return n, io.ErrShortWrite
+	}
+	return n, nil
+}
+
+// apply applies the given operation on the given data counting the bytes
+// transferred. If the given block position is inside the data, the operation
+// is applied up to the block position and continued with the remaining data
+// after the stream was released, i.e. writes are never reported short because
+// of blocking.
+func (s *Stream) apply(
+	count *int64, p []byte, block int64, op func([]byte) (int, error),
+) (int, error) {
+	if block < 0 || block >= int64(len(p)) {
+		n, err := op(p)
+		s.mu.Lock()
+		*count += int64(n)
+		s.mu.Unlock()
+		return n, err
+	}
+
+	n, err := s.apply(count, p[:block], -1, op)
+	if err != nil || int64(n) < block {
+		return n, err
+	}
+	<-s.release
+	m, err := s.apply(count, p[block:], -1, op)
+	return n + m, err
+}
+
+// cleanup releases the stream and validates the set up expectations.
+func (s *Stream) cleanup() {
+	s.Release()
+
+	s.mu.Lock()
+	consume := s.consume && !s.eof && s.reader != nil
+	closed := s.closed
+	s.mu.Unlock()
+
+	if consume {
+		if buffer, ok := s.reader.(interface{ Len() int }); !ok {
+			s.t.Errorf("%v", ErrIncomplete())
+		} else if n := buffer.Len(); n > 0 {
+			s.t.Errorf("%v", ErrUnconsumed(int64(n)))
+		}
+	}
+	if s.close && !closed {
+		s.t.Errorf("%v", ErrUnclosed())
+	}
+}
+
+// ErrNotSupported creates an error reporting an operation not supported by
+// the stream.
+func ErrNotSupported(op string) error {
+	return fmt.Errorf("operation not supported [op=%s]", op)
+}
+
+// ErrUnconsumed creates an error reporting unconsumed data of a stream.
+func ErrUnconsumed(n int64) error {
+	return fmt.Errorf("unconsumed stream data [bytes=%d]", n)
+}
+
+// ErrIncomplete creates an error reporting a stream not read until end of file.
+func ErrIncomplete() error {
+	return errors.New("stream not read until end of file")
+}
+
+// ErrUnclosed creates an error reporting an unclosed stream.
+func ErrUnclosed() error {
+	return errors.New("stream not closed")
+}
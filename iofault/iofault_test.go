@@ -0,0 +1,324 @@
+package iofault_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tkrop/go-testing/iofault"
+	"github.com/tkrop/go-testing/mock"
+	"github.com/tkrop/go-testing/test"
+)
+
+// readAll reads all data from the stream.
+func readAll(s *iofault.Stream) (string, error) {
+	data, err := io.ReadAll(s)
+	return string(data), err
+}
+
+// readEach reads the data from the stream using the given buffer size and
+// joins the results of each read using `|`.
+func readEach(size int) func(s *iofault.Stream) (string, error) {
+	return func(s *iofault.Stream) (string, error) {
+		reads := []string{}
+		buffer := make([]byte, size)
+		for {
+			n, err := s.Read(buffer)
+			if n > 0 {
+				reads = append(reads, string(buffer[:n]))
+			}
+			if err != nil {
+				return strings.Join(reads, "|"), err
+			}
+		}
+	}
+}
+
+// write writes the given data to the stream.
+func write(data ...string) func(s *iofault.Stream) (string, error) {
+	return func(s *iofault.Stream) (string, error) {
+		for _, data := range data {
+			if _, err := s.Write([]byte(data)); err != nil {
+				return "", err
+			}
+		}
+		return "", nil
+	}
+}
+
+type StreamParams struct {
+	setup         mock.SetupFunc
+	input         string
+	opts          []iofault.Option
+	call          func(s *iofault.Stream) (string, error)
+	expectResult  string
+	expectError   error
+	expectWritten string
+	expect        test.Expect
+}
+
+var testStreamParams = map[string]StreamParams{
+	"read all": {
+		input:        "hello",
+		call:         readAll,
+		expectResult: "hello",
+		expect:       test.Success,
+	},
+	"read fail after": {
+		input:        "hello",
+		opts:         []iofault.Option{iofault.FailAfter(3, assert.AnError)},
+		call:         readEach(5),
+		expectResult: "hel",
+		expectError:  assert.AnError,
+		expect:       test.Success,
+	},
+	"read fail immediately": {
+		input:       "hello",
+		opts:        []iofault.Option{iofault.FailAfter(0, assert.AnError)},
+		call:        readEach(5),
+		expectError: assert.AnError,
+		expect:      test.Success,
+	},
+	"read short": {
+		input:        "hello",
+		opts:         []iofault.Option{iofault.Short(2)},
+		call:         readEach(5),
+		expectResult: "he|ll|o",
+		expectError:  io.EOF,
+		expect:       test.Success,
+	},
+	"read short fail after": {
+		input: "hello",
+		opts: []iofault.Option{
+			iofault.Short(2), iofault.FailAfter(3, assert.AnError),
+		},
+		call:         readEach(5),
+		expectResult: "he|l",
+		expectError:  assert.AnError,
+		expect:       test.Success,
+	},
+	"write not supported": {
+		input:       "hello",
+		call:        write("hello"),
+		expectError: iofault.ErrNotSupported("write"),
+		expect:      test.Success,
+	},
+	"write all": {
+		call:          write("hello", " world"),
+		expectWritten: "hello world",
+		expect:        test.Success,
+	},
+	"write fail after": {
+		opts:          []iofault.Option{iofault.FailAfter(3, assert.AnError)},
+		call:          write("hello"),
+		expectError:   assert.AnError,
+		expectWritten: "hel",
+		expect:        test.Success,
+	},
+	"write fail after second": {
+		opts:          []iofault.Option{iofault.FailAfter(5, assert.AnError)},
+		call:          write("hello", "world"),
+		expectError:   assert.AnError,
+		expectWritten: "hello",
+		expect:        test.Success,
+	},
+	"write short": {
+		opts:          []iofault.Option{iofault.Short(2)},
+		call:          write("hello"),
+		expectError:   io.ErrShortWrite,
+		expectWritten: "he",
+		expect:        test.Success,
+	},
+	"close error": {
+		opts: []iofault.Option{iofault.CloseError(assert.AnError)},
+		call: func(s *iofault.Stream) (string, error) {
+			return "", s.Close()
+		},
+		expectError: assert.AnError,
+		expect:      test.Success,
+	},
+	"expect consumed": {
+		input:        "hello",
+		opts:         []iofault.Option{iofault.ExpectConsumed()},
+		call:         readAll,
+		expectResult: "hello",
+		expect:       test.Success,
+	},
+	"expect consumed failure": {
+		setup: test.Errorf("%v", iofault.ErrUnconsumed(3)),
+		input: "hello",
+		opts:  []iofault.Option{iofault.ExpectConsumed(), iofault.Short(2)},
+		call: func(s *iofault.Stream) (string, error) {
+			buffer := make([]byte, 5)
+			n, err := s.Read(buffer)
+			return string(buffer[:n]), err
+		},
+		expectResult: "he",
+	},
+	"expect closed": {
+		opts: []iofault.Option{iofault.ExpectClosed()},
+		call: func(s *iofault.Stream) (string, error) {
+			return "", s.Close()
+		},
+		expect: test.Success,
+	},
+	"expect closed failure": {
+		setup:         test.Errorf("%v", iofault.ErrUnclosed()),
+		opts:          []iofault.Option{iofault.ExpectClosed()},
+		call:          write("hello"),
+		expectWritten: "hello",
+	},
+}
+
+func TestStream(t *testing.T) {
+	test.Map(t, testStreamParams).
+		Run(func(t test.Test, param StreamParams) {
+			// Given
+			if param.setup != nil {
+				mock.NewMock(t).Expect(param.setup)
+			}
+			buffer := &bytes.Buffer{}
+			var stream *iofault.Stream
+			if param.input != "" {
+				stream = iofault.NewReader(t,
+					strings.NewReader(param.input), param.opts...)
+			} else {
+				stream = iofault.NewWriter(t, buffer, param.opts...)
+			}
+
+			// When
+			result, err := param.call(stream)
+
+			// Then
+			assert.Equal(t, param.expectResult, result)
+			assert.Equal(t, param.expectError, err)
+			assert.Equal(t, param.expectWritten, buffer.String())
+			assert.Equal(t, int64(len(param.expectWritten)),
+				stream.BytesWritten())
+		})
+}
+
+func TestReadWriteCloser(t *testing.T) {
+	test.Run(test.Success, func(t test.Test) {
+		// Given
+		buffer := &bytes.Buffer{}
+		stream := iofault.NewReadWriteCloser(t, strings.NewReader("ping"),
+			buffer, iofault.ExpectConsumed(), iofault.ExpectClosed())
+
+		// When
+		data, err := io.ReadAll(stream)
+		assert.NoError(t, err)
+		_, err = stream.Write(append(data, '-', 'p', 'o', 'n', 'g'))
+		assert.NoError(t, err)
+
+		// Then
+		assert.NoError(t, stream.Close())
+		assert.Equal(t, "ping-pong", buffer.String())
+		assert.Equal(t, int64(4), stream.BytesRead())
+		assert.Equal(t, int64(9), stream.BytesWritten())
+	})(t)
+}
+
+func TestExpectConsumedBlocking(t *testing.T) {
+	test.Run(test.Failure, func(t test.Test) {
+		// Given
+		mock.NewMock(t).Expect(test.Errorf("%v", iofault.ErrIncomplete()))
+		reader, writer := io.Pipe()
+		t.(*test.Tester).Cleanup(func() { writer.Close() })
+
+		// When
+		stream := iofault.NewReader(t, reader, iofault.ExpectConsumed())
+
+		// Then
+		assert.Equal(t, int64(0), stream.BytesRead())
+	})(t)
+}
+
+func TestBlockAfter(t *testing.T) {
+	test.Run(test.Success, func(t test.Test) {
+		// Given
+		stream := iofault.NewReader(t, strings.NewReader("hello"),
+			iofault.BlockAfter(2))
+		done := make(chan string)
+
+		// When
+		go func() {
+			data, _ := io.ReadAll(stream)
+			done <- string(data)
+		}()
+
+		// Then
+		assert.Eventually(t, func() bool {
+			return stream.BytesRead() == 2
+		}, time.Second, time.Millisecond)
+		select {
+		case <-done:
+			assert.Fail(t, "stream not blocked")
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		// When
+		stream.Release()
+
+		// Then
+		assert.Equal(t, "hello", <-done)
+	})(t)
+}
+
+func TestBlockAfterWrite(t *testing.T) {
+	test.Run(test.Success, func(t test.Test) {
+		// Given
+		buffer := &bytes.Buffer{}
+		stream := iofault.NewWriter(t, buffer, iofault.BlockAfter(2))
+		type result struct {
+			n   int
+			err error
+		}
+		done := make(chan result)
+
+		// When
+		go func() {
+			n, err := stream.Write([]byte("hello"))
+			done <- result{n: n, err: err}
+		}()
+
+		// Then
+		assert.Eventually(t, func() bool {
+			return stream.BytesWritten() == 2
+		}, time.Second, time.Millisecond)
+		select {
+		case <-done:
+			assert.Fail(t, "stream not blocked")
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		// When
+		stream.Release()
+
+		// Then
+		assert.Equal(t, result{n: 5}, <-done)
+		assert.Equal(t, int64(5), stream.BytesWritten())
+		assert.Equal(t, "hello", buffer.String())
+	})(t)
+}
+
+func TestBlockAfterCleanup(t *testing.T) {
+	// Given
+	done := make(chan error)
+	t.Cleanup(func() {
+		assert.NoError(t, <-done)
+	})
+
+	// When
+	test.Run(test.Success, func(t test.Test) {
+		stream := iofault.NewWriter(t, io.Discard, iofault.BlockAfter(0))
+		go func() {
+			_, err := stream.Write([]byte("hello"))
+			done <- err
+		}()
+	})(t)
+}
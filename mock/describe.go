@@ -0,0 +1,61 @@
+package mock
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/tkrop/go-testing/internal/reflect"
+)
+
+// Describe describes the mock interactions of the given mock setup function
+// by running it against a detached mock controller ignoring all failures, e.g.
+// to document the mock interactions of test parameter sets. If the mock setup
+// function panics, the panic is described as invalid mock setup.
+func Describe(setup SetupFunc) (calls []string) {
+	if setup == nil {
+		return nil
+	}
+	defer func() {
+		if arg := recover(); arg != nil {
+			calls = append(calls, fmt.Sprintf("invalid mock setup: %v", arg))
+		}
+	}()
+
+	return describeCalls(reflect.ValueOf(setup(NewMock(detached{}))), calls)
+}
+
+// describeCalls collects the descriptions of the mock calls in the given mock
+// setup result, i.e. single mock calls or (nested) slices of mock calls.
+func describeCalls(value reflect.Value, calls []string) []string {
+	for value.Kind() == reflect.Interface && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	switch {
+	case value.Kind() == reflect.Slice:
+		for index := 0; index < value.Len(); index++ {
+			calls = describeCalls(value.Index(index), calls)
+		}
+	case value.IsValid() && value.CanInterface():
+		if call, ok := value.Interface().(*gomock.Call); ok {
+			desc := call.String()
+			if index := strings.LastIndex(desc, " "); index > 0 {
+				desc = desc[:index]
+			}
+			calls = append(calls, desc)
+		}
+	}
+	return calls
+}
+
+// detached is a test reporter ignoring all failures of the detached mock
+// controller used to describe mock interactions.
+type detached struct{}
+
+// Errorf ignores the failure.
+func (detached) Errorf(string, ...any) {}
+
+// Fatalf ignores the failure.
+func (detached) Fatalf(string, ...any) {}
@@ -0,0 +1,49 @@
+package mock_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tkrop/go-testing/mock"
+	"github.com/tkrop/go-testing/test"
+)
+
+type DescribeParams struct {
+	setup       mock.SetupFunc
+	expectCalls []string
+}
+
+var testDescribeParams = map[string]DescribeParams{
+	"nil setup": {},
+	"single call": {
+		setup: CallA("a"),
+		expectCalls: []string{
+			"*mock_test.MockIFace.CallA(is equal to a (string))",
+		},
+	},
+	"nested calls": {
+		setup: mock.Chain(CallA("a"), mock.Parallel(CallB("b", "c"))),
+		expectCalls: []string{
+			"*mock_test.MockIFace.CallA(is equal to a (string))",
+			"*mock_test.MockIFace.CallB(is equal to b (string))",
+		},
+	},
+	"invalid setup": {
+		setup: func(*mock.Mocks) any { panic("invalid") },
+		expectCalls: []string{
+			"invalid mock setup: invalid",
+		},
+	},
+}
+
+func TestDescribe(t *testing.T) {
+	test.Map(t, testDescribeParams).
+		Run(func(t test.Test, param DescribeParams) {
+			// When
+			calls := mock.Describe(param.setup)
+
+			// Then
+			assert.Equal(t, param.expectCalls, calls)
+		})
+}
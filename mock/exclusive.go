@@ -0,0 +1,156 @@
+package mock
+
+import (
+	"fmt"
+	"runtime"
+	gosync "sync"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/tkrop/go-testing/internal/reflect"
+)
+
+// exclusion is a mutual exclusion group of mock calls detecting overlapping
+// invocations of the mock calls belonging to the group.
+type exclusion struct {
+	// mu the mutex to protect the active invocations.
+	mu gosync.Mutex
+	// active the currently active invocations of the group in order of entry.
+	active []*invocation
+}
+
+// invocation is an invocation of a mock call in a mutual exclusion group.
+type invocation struct {
+	// fn the interface method function of the invoked mock call.
+	fn any
+	// conflicts the invocations entered while the invocation was active.
+	conflicts []*conflict
+}
+
+// conflict is an invocation of a mock call that was entered while another
+// invocation of the mutual exclusion group was active.
+type conflict struct {
+	// fn the interface method function of the conflicting mock call.
+	fn any
+	// stack the stack of the go-routine invoking the conflicting mock call.
+	stack []byte
+	// captured the channel closed after the stack was captured.
+	captured chan struct{}
+}
+
+// Exclusive creates a set of mock calls that must not be invoked concurrently,
+// i.e. if a mock call is invoked while another mock call of the set is still
+// active, the test fails reporting the stacks of both go-routines. Besides
+// the mutual exclusion, the mock calls are ordered like in `Parallel`. Nested
+// exclusive sets are joined into the outer set.
+func Exclusive(fncalls ...func(*Mocks) any) func(*Mocks) any {
+	return func(mocks *Mocks) any {
+		parent := mocks.exclusion
+		if parent == nil {
+			mocks.exclusion = &exclusion{}
+		}
+		defer func() { mocks.exclusion = parent }()
+		return Parallel(fncalls...)(mocks)
+	}
+}
+
+// Exclusive enables the mutual exclusion policy for all mocks, i.e. mock calls
+// of the same mock interface set up afterwards must not be invoked
+// concurrently. Overlapping invocations fail the test reporting the stacks of
+// both go-routines.
+func (mocks *Mocks) Exclusive() *Mocks {
+	if mocks.exclusive == nil {
+		mocks.exclusive = map[reflect.Type]*exclusion{}
+	}
+	return mocks
+}
+
+// exclusions returns the mutual exclusion groups for a mock call of the given
+// interface method function during setup, i.e. the group of the enclosing
+// exclusive set and the group of the mock interface, if the mutual exclusion
+// policy is enabled.
+func (mocks *Mocks) exclusions(fn any) []*exclusion {
+	var groups []*exclusion
+	if mocks.exclusion != nil {
+		groups = append(groups, mocks.exclusion)
+	}
+	if mocks.exclusive != nil {
+		itype := reflect.TypeOf(fn).In(0)
+		group, ok := mocks.exclusive[itype]
+		if !ok {
+			group = &exclusion{}
+			mocks.exclusive[itype] = group
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// enter enters the mutual exclusion group with an invocation of the mock call
+// of the given interface method function and returns the function to exit
+// the group again. If another invocation is active, the overlap is reported
+// to the given test reporter when the active invocation exits, so that the
+// stacks of both go-routines are only captured in case of conflicts.
+func (e *exclusion) enter(t gomock.TestReporter, fn any) func() {
+	current := &invocation{fn: fn}
+
+	e.mu.Lock()
+	var conflicted *conflict
+	if len(e.active) != 0 {
+		conflicted = &conflict{fn: fn, captured: make(chan struct{})}
+		e.active[0].conflicts = append(e.active[0].conflicts, conflicted)
+	}
+	e.active = append(e.active, current)
+	e.mu.Unlock()
+
+	if conflicted != nil {
+		conflicted.stack = stack()
+		close(conflicted.captured)
+	}
+
+	return func() {
+		e.mu.Lock()
+		e.active = remove(e.active, current)
+		conflicts := current.conflicts
+		e.mu.Unlock()
+
+		if len(conflicts) != 0 {
+			astack := stack()
+			for _, conflict := range conflicts {
+				<-conflict.captured
+				t.Errorf("%v", ErrConcurrentCall(funcName(current.fn),
+					funcName(conflict.fn), astack, conflict.stack))
+			}
+		}
+	}
+}
+
+// remove removes the given invocation from the given invocations.
+func remove(invocations []*invocation, target *invocation) []*invocation {
+	for index, invocation := range invocations {
+		if invocation == target {
+			return append(invocations[:index:index], invocations[index+1:]...)
+		}
+	}
+	return invocations
+}
+
+// stack returns the stack of the current go-routine.
+func stack() []byte {
+	buffer := make([]byte, 4096)
+	for {
+		size := runtime.Stack(buffer, false)
+		if size < len(buffer) {
+			return buffer[:size]
+		}
+		buffer = make([]byte, 2*len(buffer))
+	}
+}
+
+// ErrConcurrentCall creates an error that the mock call with given current name
+// was invoked while the mock call with given active name was still active
+// providing the stacks of both go-routines.
+func ErrConcurrentCall(active, current string, astack, cstack []byte) error {
+	return fmt.Errorf("concurrent mock calls [active=%s, current=%s]"+
+		"\n\nactive %s\ncurrent %s", active, current, astack, cstack)
+}
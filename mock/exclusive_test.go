@@ -0,0 +1,239 @@
+package mock_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tkrop/go-testing/mock"
+	"github.com/tkrop/go-testing/test"
+)
+
+// prefixMatcher is a matcher for errors starting with a given prefix.
+type prefixMatcher struct {
+	prefix string
+}
+
+// Matches executes the error prefix matching.
+func (m *prefixMatcher) Matches(x any) bool {
+	err, ok := x.(error)
+	return ok && strings.HasPrefix(err.Error(), m.prefix)
+}
+
+// String creates a string of the expectation to match.
+func (m *prefixMatcher) String() string {
+	return fmt.Sprintf("has prefix %q", m.prefix)
+}
+
+// concurrentCall creates a matcher for concurrent call errors of the mock
+// calls with given names.
+func concurrentCall(active, current string) gomock.Matcher {
+	return &prefixMatcher{prefix: strings.SplitN(mock.ErrConcurrentCall(
+		active, current, nil, nil).Error(), "\n", 2)[0]}
+}
+
+type ExclusiveParams struct {
+	policy      bool
+	setup       func(block mock.Effect) mock.SetupFunc
+	call        func(mocks *mock.Mocks)
+	overlap     bool
+	expectError gomock.Matcher
+	expect      test.Expect
+}
+
+var testExclusiveParams = map[string]ExclusiveParams{
+	"exclusive sequential calls": {
+		setup: func(block mock.Effect) mock.SetupFunc {
+			return mock.Exclusive(Decode(block, nil), Scan(nil, nil))
+		},
+		call: func(mocks *mock.Mocks) {
+			_ = mock.Get(mocks, NewMockDecoder).Scan()
+		},
+		expect: test.Success,
+	},
+	"exclusive overlapping calls": {
+		setup: func(block mock.Effect) mock.SetupFunc {
+			return mock.Exclusive(Decode(block, nil), Scan(nil, nil))
+		},
+		call: func(mocks *mock.Mocks) {
+			_ = mock.Get(mocks, NewMockDecoder).Scan()
+		},
+		overlap:     true,
+		expectError: concurrentCall("Decoder.Decode", "Decoder.Scan"),
+		expect:      test.Failure,
+	},
+	"exclusive overlapping other mocks": {
+		setup: func(block mock.Effect) mock.SetupFunc {
+			return mock.Exclusive(Decode(block, nil), CallA("a"))
+		},
+		call: func(mocks *mock.Mocks) {
+			mock.Get(mocks, NewMockIFace).CallA("a")
+		},
+		overlap:     true,
+		expectError: concurrentCall("Decoder.Decode", "IFace.CallA"),
+		expect:      test.Failure,
+	},
+	"exclusive nested overlapping calls": {
+		setup: func(block mock.Effect) mock.SetupFunc {
+			return mock.Exclusive(Decode(block, nil),
+				mock.Exclusive(Scan(nil, nil)))
+		},
+		call: func(mocks *mock.Mocks) {
+			_ = mock.Get(mocks, NewMockDecoder).Scan()
+		},
+		overlap:     true,
+		expectError: concurrentCall("Decoder.Decode", "Decoder.Scan"),
+		expect:      test.Failure,
+	},
+	"exclusive overlapping outside calls": {
+		setup: func(block mock.Effect) mock.SetupFunc {
+			return mock.Setup(mock.Exclusive(Decode(block, nil)),
+				Scan(nil, nil))
+		},
+		call: func(mocks *mock.Mocks) {
+			_ = mock.Get(mocks, NewMockDecoder).Scan()
+		},
+		overlap: true,
+		expect:  test.Success,
+	},
+	"parallel overlapping calls": {
+		setup: func(block mock.Effect) mock.SetupFunc {
+			return mock.Parallel(Decode(block, nil), Scan(nil, nil))
+		},
+		call: func(mocks *mock.Mocks) {
+			_ = mock.Get(mocks, NewMockDecoder).Scan()
+		},
+		overlap: true,
+		expect:  test.Success,
+	},
+	"policy sequential calls": {
+		policy: true,
+		setup: func(block mock.Effect) mock.SetupFunc {
+			return mock.Setup(Decode(block, nil), Scan(nil, nil))
+		},
+		call: func(mocks *mock.Mocks) {
+			_ = mock.Get(mocks, NewMockDecoder).Scan()
+		},
+		expect: test.Success,
+	},
+	"policy overlapping calls": {
+		policy: true,
+		setup: func(block mock.Effect) mock.SetupFunc {
+			return mock.Setup(Decode(block, nil), Scan(nil, nil))
+		},
+		call: func(mocks *mock.Mocks) {
+			_ = mock.Get(mocks, NewMockDecoder).Scan()
+		},
+		overlap:     true,
+		expectError: concurrentCall("Decoder.Decode", "Decoder.Scan"),
+		expect:      test.Failure,
+	},
+	"policy overlapping other mocks": {
+		policy: true,
+		setup: func(block mock.Effect) mock.SetupFunc {
+			return mock.Setup(Decode(block, nil), CallA("a"))
+		},
+		call: func(mocks *mock.Mocks) {
+			mock.Get(mocks, NewMockIFace).CallA("a")
+		},
+		overlap: true,
+		expect:  test.Success,
+	},
+}
+
+func TestExclusive(t *testing.T) {
+	test.Map(t, testExclusiveParams).
+		Run(func(t test.Test, param ExclusiveParams) {
+			// Given
+			if param.expectError != nil {
+				mock.NewMock(t).Expect(test.Errorf("%v", param.expectError))
+			}
+			block, entered, release := blocker()
+			mocks := mock.NewMock(t)
+			if param.policy {
+				mocks.Exclusive()
+			}
+			mocks.Expect(param.setup(block))
+			decoder := mock.Get(mocks, NewMockDecoder)
+
+			// When
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				_ = decoder.Decode(&ExportStruct{})
+			}()
+			<-entered
+			if !param.overlap {
+				close(release)
+				<-done
+			}
+			param.call(mocks)
+			if param.overlap {
+				close(release)
+				<-done
+			}
+
+			// Then
+			mocks.Wait()
+		})
+}
+
+// blocker creates a side effect signaling the entry of a mock call and
+// blocking the mock call until released.
+func blocker() (mock.Effect, chan struct{}, chan struct{}) {
+	entered, release := make(chan struct{}), make(chan struct{})
+	return func([]any) {
+		close(entered)
+		<-release
+	}, entered, release
+}
+
+func TestExclusiveConflicting(t *testing.T) {
+	test.Run(test.Failure, func(t test.Test) {
+		// Given
+		mock.NewMock(t).Expect(mock.Setup(
+			test.Errorf("%v", concurrentCall("Decoder.Decode", "Decoder.Scan")),
+			test.Errorf("%v", concurrentCall("Decoder.Scan", "IFace.CallA")),
+		))
+		decode, dentered, drelease := blocker()
+		scan, sentered, srelease := blocker()
+		mocks := mock.NewMock(t).Expect(mock.Exclusive(
+			Decode(decode, nil), Scan(scan, nil), CallA("a")))
+		decoder := mock.Get(mocks, NewMockDecoder)
+
+		// When
+		ddone, sdone := make(chan struct{}), make(chan struct{})
+		go func() {
+			defer close(ddone)
+			_ = decoder.Decode(&ExportStruct{})
+		}()
+		<-dentered
+		go func() {
+			defer close(sdone)
+			_ = decoder.Scan()
+		}()
+		<-sentered
+		close(drelease)
+		<-ddone
+		mock.Get(mocks, NewMockIFace).CallA("a")
+		close(srelease)
+		<-sdone
+
+		// Then
+		mocks.Wait()
+	})(t)
+}
+
+func TestErrConcurrentCall(t *testing.T) {
+	// When
+	err := mock.ErrConcurrentCall("IFace.CallA", "IFace.CallB",
+		[]byte("goroutine 1 [running]:\n"), []byte("goroutine 2 [running]:\n"))
+
+	// Then
+	assert.Equal(t, "concurrent mock calls [active=IFace.CallA, "+
+		"current=IFace.CallB]\n\nactive goroutine 1 [running]:\n\n"+
+		"current goroutine 2 [running]:\n", err.Error())
+}
@@ -0,0 +1,219 @@
+package mock_test
+
+// File contains a generic interface together with a hand written generic mock
+// following the `mockgen` patterns to validate the support of generic
+// interfaces by the mock framework.
+import (
+	"reflect"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tkrop/go-testing/mock"
+	"github.com/tkrop/go-testing/test"
+)
+
+// Repo is a generic repository interface.
+type Repo[K comparable, V any] interface {
+	Find(key K) (V, error)
+	Store(key K, value V) error
+}
+
+// MockRepo is a mock of the generic `Repo` interface.
+type MockRepo[K comparable, V any] struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepoMockRecorder[K, V]
+}
+
+// MockRepoMockRecorder is the mock recorder for `MockRepo`.
+type MockRepoMockRecorder[K comparable, V any] struct {
+	mock *MockRepo[K, V]
+}
+
+// NewMockRepo creates a new mock instance.
+func NewMockRepo[K comparable, V any](
+	ctrl *gomock.Controller,
+) *MockRepo[K, V] {
+	mock := &MockRepo[K, V]{ctrl: ctrl}
+	mock.recorder = &MockRepoMockRecorder[K, V]{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepo[K, V]) EXPECT() *MockRepoMockRecorder[K, V] {
+	return m.recorder
+}
+
+// Find mocks base method.
+func (m *MockRepo[K, V]) Find(key K) (V, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", key)
+	ret0, _ := ret[0].(V)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find.
+func (mr *MockRepoMockRecorder[K, V]) Find(key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find",
+		reflect.TypeOf((*MockRepo[K, V])(nil).Find), key)
+}
+
+// Store mocks base method.
+func (m *MockRepo[K, V]) Store(key K, value V) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Store", key, value)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Store indicates an expected call of Store.
+func (mr *MockRepoMockRecorder[K, V]) Store(key, value any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store",
+		reflect.TypeOf((*MockRepo[K, V])(nil).Store), key, value)
+}
+
+func Find[K comparable, V any](key K, value V, err error) mock.SetupFunc {
+	return func(mocks *mock.Mocks) any {
+		return mock.Get(mocks, NewMockRepo[K, V]).EXPECT().Find(key).
+			DoAndReturn(mocks.Return(Repo[K, V].Find, value, err))
+	}
+}
+
+func FindPanic[K comparable, V any](key K, reason any) mock.SetupFunc {
+	return func(mocks *mock.Mocks) any {
+		return mock.Get(mocks, NewMockRepo[K, V]).EXPECT().Find(key).
+			DoAndReturn(mocks.Panic(Repo[K, V].Find, reason))
+	}
+}
+
+func Store[K comparable, V any](key K, value V, err error) mock.SetupFunc {
+	return func(mocks *mock.Mocks) any {
+		return mock.Get(mocks, NewMockRepo[K, V]).EXPECT().Store(key, value).
+			DoAndReturn(mocks.Return(Repo[K, V].Store, err))
+	}
+}
+
+type GenericParams struct {
+	setup       mock.SetupFunc
+	call        func(test.Test, *mock.Mocks)
+	expectPanic any
+}
+
+var testGenericParams = map[string]GenericParams{
+	"find int": {
+		setup: Find("key", 1, nil),
+		call: func(t test.Test, mocks *mock.Mocks) {
+			repo := mock.Get(mocks, NewMockRepo[string, int])
+			value, err := repo.Find("key")
+			assert.Equal(t, 1, value)
+			assert.NoError(t, err)
+		},
+	},
+	"find string error": {
+		setup: Find(1, "", assert.AnError),
+		call: func(t test.Test, mocks *mock.Mocks) {
+			repo := mock.Get(mocks, NewMockRepo[int, string])
+			value, err := repo.Find(1)
+			assert.Equal(t, "", value)
+			assert.Equal(t, assert.AnError, err)
+		},
+	},
+	"find pointer": {
+		setup: Find("key", &ExportStruct{Value: "value"}, nil),
+		call: func(t test.Test, mocks *mock.Mocks) {
+			repo := mock.Get(mocks, NewMockRepo[string, *ExportStruct])
+			value, err := repo.Find("key")
+			assert.Equal(t, &ExportStruct{Value: "value"}, value)
+			assert.NoError(t, err)
+		},
+	},
+	"find interface nil": {
+		setup: Find[string, any]("key", nil, nil),
+		call: func(t test.Test, mocks *mock.Mocks) {
+			repo := mock.Get(mocks, NewMockRepo[string, any])
+			value, err := repo.Find("key")
+			assert.Nil(t, value)
+			assert.NoError(t, err)
+		},
+	},
+	"store struct": {
+		setup: Store("key", ExportStruct{Value: "value"}, nil),
+		call: func(t test.Test, mocks *mock.Mocks) {
+			repo := mock.Get(mocks, NewMockRepo[string, ExportStruct])
+			err := repo.Store("key", ExportStruct{Value: "value"})
+			assert.NoError(t, err)
+		},
+	},
+	"chain instantiations": {
+		setup: mock.Chain(
+			Store("key", 1, nil),
+			Find("key", 1, nil),
+			Store(1, "value", nil),
+			Find(1, "value", nil),
+		),
+		call: func(t test.Test, mocks *mock.Mocks) {
+			irepo := mock.Get(mocks, NewMockRepo[string, int])
+			srepo := mock.Get(mocks, NewMockRepo[int, string])
+			assert.NotSame(t, any(irepo), any(srepo))
+
+			assert.NoError(t, irepo.Store("key", 1))
+			ivalue, err := irepo.Find("key")
+			assert.Equal(t, 1, ivalue)
+			assert.NoError(t, err)
+
+			assert.NoError(t, srepo.Store(1, "value"))
+			svalue, err := srepo.Find(1)
+			assert.Equal(t, "value", svalue)
+			assert.NoError(t, err)
+		},
+	},
+	"find panic": {
+		setup: FindPanic[string, int]("key", "panic-test"),
+		call: func(t test.Test, mocks *mock.Mocks) {
+			repo := mock.Get(mocks, NewMockRepo[string, int])
+			_, _ = repo.Find("key")
+		},
+		expectPanic: "panic-test",
+	},
+}
+
+func TestGeneric(t *testing.T) {
+	test.Map(t, testGenericParams).
+		Run(func(t test.Test, param GenericParams) {
+			// Given
+			mocks := MockSetup(t, param.setup)
+			if param.expectPanic != nil {
+				defer func() {
+					assert.Equal(t, param.expectPanic, recover())
+					mocks.Wait()
+				}()
+			}
+
+			// When
+			param.call(t, mocks)
+			mocks.Wait()
+
+			// Then
+			assert.Nil(t, param.expectPanic, "not paniced")
+		})
+}
+
+func TestGenericGet(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	mocks := mock.NewMock(t)
+
+	// When
+	repo := mock.Get(mocks, NewMockRepo[string, int])
+
+	// Then
+	assert.Same(t, repo, mock.Get(mocks, NewMockRepo[string, int]))
+	assert.NotNil(t, mock.Get(mocks, NewMockRepo[string, string]))
+	assert.NotNil(t, mock.Get(mocks, NewMockRepo[int, int]))
+	assert.Same(t, repo, mock.Get(mocks, NewMockRepo[string, int]))
+}
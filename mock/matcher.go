@@ -0,0 +1,416 @@
+package mock
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/tkrop/go-testing/internal/reflect"
+)
+
+// Matcher alias for `gomock.Matcher`.
+type Matcher = gomock.Matcher
+
+// matcherOf returns the given argument as matcher. If the argument is not
+// already a matcher, it is wrapped into an equality matcher.
+func matcherOf(x any) Matcher {
+	if m, ok := x.(Matcher); ok {
+		return m
+	}
+	return gomock.Eq(x)
+}
+
+// matchersOf returns the given arguments as slice of matchers.
+func matchersOf(xs ...any) []Matcher {
+	ms := make([]Matcher, 0, len(xs))
+	for _, x := range xs {
+		ms = append(ms, matcherOf(x))
+	}
+	return ms
+}
+
+// stringsOf returns the string representations of the given matchers.
+func stringsOf(ms []Matcher) []string {
+	ss := make([]string, 0, len(ms))
+	for _, m := range ms {
+		ss = append(ss, m.String())
+	}
+	return ss
+}
+
+// allMatcher is a matcher that matches if all sub-matchers match.
+type allMatcher struct {
+	ms []Matcher
+}
+
+// All creates a new matcher that matches if all given matchers match. Values
+// that are not matchers are matched for equality.
+func All(xs ...any) Matcher {
+	return &allMatcher{ms: matchersOf(xs...)}
+}
+
+// Matches executes the conjunctive matching.
+func (m *allMatcher) Matches(x any) bool {
+	for _, matcher := range m.ms {
+		if !matcher.Matches(x) {
+			return false
+		}
+	}
+	return true
+}
+
+// String creates a string of the expectation to match.
+func (m *allMatcher) String() string {
+	return "all of (" + strings.Join(stringsOf(m.ms), "; ") + ")"
+}
+
+// anyMatcher is a matcher that matches if at least one sub-matcher matches.
+type anyMatcher struct {
+	ms []Matcher
+}
+
+// Any creates a new matcher that matches if at least one of the given matchers
+// matches. Values that are not matchers are matched for equality.
+func Any(xs ...any) Matcher {
+	return &anyMatcher{ms: matchersOf(xs...)}
+}
+
+// Matches executes the disjunctive matching.
+func (m *anyMatcher) Matches(x any) bool {
+	for _, matcher := range m.ms {
+		if matcher.Matches(x) {
+			return true
+		}
+	}
+	return false
+}
+
+// String creates a string of the expectation to match.
+func (m *anyMatcher) String() string {
+	return "any of (" + strings.Join(stringsOf(m.ms), "; ") + ")"
+}
+
+// notMatcher is a matcher that negates its sub-matcher.
+type notMatcher struct {
+	m Matcher
+}
+
+// Not creates a new matcher that matches if the given matcher does not match.
+// A value that is not a matcher is matched for inequality.
+func Not(x any) Matcher {
+	return &notMatcher{m: matcherOf(x)}
+}
+
+// Matches executes the negated matching.
+func (m *notMatcher) Matches(x any) bool {
+	return !m.m.Matches(x)
+}
+
+// String creates a string of the expectation to match.
+func (m *notMatcher) String() string {
+	return "not (" + m.m.String() + ")"
+}
+
+// jsonMatcher is a matcher for JSON-equivalent documents.
+type jsonMatcher struct {
+	x     any
+	value any
+	err   error
+}
+
+// JSONEq creates a new matcher that matches `[]byte`, `string`, and
+// `json.RawMessage` arguments containing a JSON document that is equivalent
+// to the given JSON document, i.e. key order and formatting are ignored. If
+// the given document is no `[]byte` or `string`, it is marshaled to JSON.
+func JSONEq(x any) Matcher {
+	value, err := jsonOf(x, true)
+	return &jsonMatcher{x: x, value: value, err: err}
+}
+
+// Matches executes the JSON equivalence matching.
+func (m *jsonMatcher) Matches(x any) bool {
+	if m.err != nil {
+		return false
+	}
+	value, err := jsonOf(x, false)
+	if err != nil {
+		return false
+	}
+	return reflect.DeepEqual(m.value, value)
+}
+
+// String creates a string of the expectation to match.
+func (m *jsonMatcher) String() string {
+	if m.err != nil {
+		return fmt.Sprintf("is JSON equivalent to invalid %v [%v]", m.x, m.err)
+	}
+	return fmt.Sprintf("is JSON equivalent to %s", stringOf(m.x))
+}
+
+// jsonOf unmarshals the given JSON document into a generic value. If marshal
+// is set, arguments that are no JSON documents are marshaled first.
+func jsonOf(x any, marshal bool) (any, error) {
+	var data []byte
+	switch x := x.(type) {
+	case []byte:
+		data = x
+	case json.RawMessage:
+		data = x
+	case string:
+		data = []byte(x)
+	default:
+		if !marshal {
+			return nil, ErrNoJSON(x)
+		}
+		bytes, err := json.Marshal(x)
+		if err != nil {
+			return nil, err
+		}
+		data = bytes
+	}
+
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// stringOf returns a readable string of the given value.
+func stringOf(x any) string {
+	switch x := x.(type) {
+	case []byte:
+		return string(x)
+	case json.RawMessage:
+		return string(x)
+	case string:
+		return x
+	default:
+		if bytes, err := json.Marshal(x); err == nil {
+			return string(bytes)
+		}
+		return fmt.Sprintf("%v", x)
+	}
+}
+
+// fieldsMatcher is a matcher for partial struct matching.
+type fieldsMatcher struct {
+	names    []string
+	matchers map[string]Matcher
+}
+
+// Fields creates a new matcher that matches structs and pointers to structs
+// by only comparing the listed fields. The field values can be matchers or
+// plain values that are matched for equality. Unlisted fields are ignored.
+func Fields(fields map[string]any) Matcher {
+	m := &fieldsMatcher{
+		names:    make([]string, 0, len(fields)),
+		matchers: make(map[string]Matcher, len(fields)),
+	}
+	for name, x := range fields {
+		m.names = append(m.names, name)
+		m.matchers[name] = matcherOf(x)
+	}
+	sort.Strings(m.names)
+	return m
+}
+
+// Matches executes the partial struct matching.
+func (m *fieldsMatcher) Matches(x any) bool {
+	v := reflect.ValueOf(x)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return false
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return false
+	}
+
+	for _, name := range m.names {
+		field, ok := v.Type().FieldByName(name)
+		if !ok || len(field.Index) != 1 {
+			return false
+		} else if !m.matchers[name].Matches(
+			reflect.FieldArgOf(v, field.Index[0])) {
+			return false
+		}
+	}
+	return true
+}
+
+// String creates a string of the expectation to match.
+func (m *fieldsMatcher) String() string {
+	fields := make([]string, 0, len(m.names))
+	for _, name := range m.names {
+		fields = append(fields, name+" "+m.matchers[name].String())
+	}
+	return "has fields (" + strings.Join(fields, "; ") + ")"
+}
+
+// timeMatcher is a matcher for time values within a tolerance.
+type timeMatcher struct {
+	time  time.Time
+	delta time.Duration
+}
+
+// Within creates a new matcher that matches time values (`time.Time` and
+// `*time.Time`) that differ at most by the given tolerance from the expected
+// time.
+func Within(expect time.Time, delta time.Duration) Matcher {
+	return &timeMatcher{time: expect, delta: delta}
+}
+
+// Matches executes the time tolerance matching.
+func (m *timeMatcher) Matches(x any) bool {
+	var actual time.Time
+	switch x := x.(type) {
+	case time.Time:
+		actual = x
+	case *time.Time:
+		if x == nil {
+			return false
+		}
+		actual = *x
+	default:
+		return false
+	}
+
+	diff := actual.Sub(m.time)
+	return -m.delta <= diff && diff <= m.delta
+}
+
+// String creates a string of the expectation to match.
+func (m *timeMatcher) String() string {
+	return fmt.Sprintf("is within %v of %v", m.delta, m.time)
+}
+
+// floatMatcher is a matcher for approximated numeric values.
+type floatMatcher struct {
+	value float64
+	delta float64
+}
+
+// Approx creates a new matcher that matches numeric values of any kind that
+// differ at most by the given delta from the expected value.
+func Approx(expect, delta float64) Matcher {
+	return &floatMatcher{value: expect, delta: delta}
+}
+
+// Matches executes the approximated numeric matching.
+func (m *floatMatcher) Matches(x any) bool {
+	actual, ok := floatOf(x)
+	if !ok || math.IsNaN(actual) {
+		return false
+	}
+	return math.Abs(actual-m.value) <= m.delta
+}
+
+// String creates a string of the expectation to match.
+func (m *floatMatcher) String() string {
+	return fmt.Sprintf("is approximately %v (+/-%v)", m.value, m.delta)
+}
+
+// floatOf returns the float value of the given numeric argument.
+func floatOf(x any) (float64, bool) {
+	v := reflect.ValueOf(x)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16,
+		reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16,
+		reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+// containsMatcher is a matcher for slices containing elements in any order.
+type containsMatcher struct {
+	ms []Matcher
+}
+
+// Contains creates a new matcher that matches slices and arrays containing
+// all the given elements in any order. Each element of the slice can only be
+// used to match exactly one expected element. The elements can be matchers or
+// plain values that are matched for equality.
+func Contains(elems ...any) Matcher {
+	return &containsMatcher{ms: matchersOf(elems...)}
+}
+
+// Matches executes the unordered slice containment matching.
+func (m *containsMatcher) Matches(x any) bool {
+	v := reflect.ValueOf(x)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return false
+	}
+
+	elems := make([]any, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		elems = append(elems, reflect.ArgOf(v.Index(i)))
+	}
+	return m.assign(elems, make([]bool, len(elems)), 0)
+}
+
+// assign tries to assign the remaining expected elements starting at given
+// index to unused actual elements using backtracking.
+func (m *containsMatcher) assign(elems []any, used []bool, index int) bool {
+	if index == len(m.ms) {
+		return true
+	}
+	for i, elem := range elems {
+		if !used[i] && m.ms[index].Matches(elem) {
+			used[i] = true
+			if m.assign(elems, used, index+1) {
+				return true
+			}
+			used[i] = false
+		}
+	}
+	return false
+}
+
+// String creates a string of the expectation to match.
+func (m *containsMatcher) String() string {
+	return "contains in any order (" +
+		strings.Join(stringsOf(m.ms), "; ") + ")"
+}
+
+// protoMatcher is a matcher for protobuf message equality.
+type protoMatcher struct {
+	msg proto.Message
+}
+
+// ProtoEq creates a new matcher that matches protobuf messages that are equal
+// to the given message according to `proto.Equal`.
+func ProtoEq(msg proto.Message) Matcher {
+	return &protoMatcher{msg: msg}
+}
+
+// Matches executes the protobuf message equality matching.
+func (m *protoMatcher) Matches(x any) bool {
+	if msg, ok := x.(proto.Message); ok {
+		return proto.Equal(m.msg, msg)
+	}
+	return false
+}
+
+// String creates a string of the expectation to match.
+func (m *protoMatcher) String() string {
+	return fmt.Sprintf("is proto equal to %v (%T)", m.msg, m.msg)
+}
+
+// ErrNoJSON creates an error that the given argument is not a JSON document.
+func ErrNoJSON(x any) error {
+	return fmt.Errorf("type [%T] is not a JSON document", x)
+}
@@ -0,0 +1,343 @@
+package mock_test
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/tkrop/go-testing/mock"
+	"github.com/tkrop/go-testing/test"
+)
+
+type MatcherStruct struct {
+	Name  string
+	Count int
+	time  time.Time
+}
+
+var (
+	// matcherTime is the base time used for time matching.
+	matcherTime = time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	// matcherTimeNil is a nil time pointer used for time matching.
+	matcherTimeNil *time.Time
+	// matcherStructNil is a nil struct pointer used for field matching.
+	matcherStructNil *MatcherStruct
+)
+
+type MatcherParams struct {
+	matcher gomock.Matcher
+	value   any
+	expect  bool
+}
+
+var testMatcherParams = map[string]MatcherParams{
+	"all match": {
+		matcher: mock.All(gomock.Not(1), 2),
+		value:   2,
+		expect:  true,
+	},
+	"all mismatch": {
+		matcher: mock.All(gomock.Any(), 2),
+		value:   1,
+		expect:  false,
+	},
+	"any match": {
+		matcher: mock.Any(1, 2),
+		value:   2,
+		expect:  true,
+	},
+	"any mismatch": {
+		matcher: mock.Any(1, 2),
+		value:   3,
+		expect:  false,
+	},
+	"not match": {
+		matcher: mock.Not(1),
+		value:   2,
+		expect:  true,
+	},
+	"not mismatch": {
+		matcher: mock.Not(mock.Any(1, 2)),
+		value:   2,
+		expect:  false,
+	},
+
+	"json string": {
+		matcher: mock.JSONEq(`{"a": 1, "b": [true, null]}`),
+		value:   `{"b":[true,null],"a":1}`,
+		expect:  true,
+	},
+	"json bytes": {
+		matcher: mock.JSONEq([]byte(`{"a": 1}`)),
+		value:   []byte(`{ "a" : 1 }`),
+		expect:  true,
+	},
+	"json raw message": {
+		matcher: mock.JSONEq(map[string]any{"a": 1}),
+		value:   json.RawMessage(`{"a":1}`),
+		expect:  true,
+	},
+	"json mismatch": {
+		matcher: mock.JSONEq(`{"a": 1}`),
+		value:   `{"a": 2}`,
+		expect:  false,
+	},
+	"json invalid value": {
+		matcher: mock.JSONEq(`{"a": 1}`),
+		value:   `{"a": 1`,
+		expect:  false,
+	},
+	"json invalid type": {
+		matcher: mock.JSONEq(`{"a": 1}`),
+		value:   1,
+		expect:  false,
+	},
+	"json invalid expect": {
+		matcher: mock.JSONEq(`{"a": 1`),
+		value:   `{"a": 1}`,
+		expect:  false,
+	},
+	"json invalid marshal": {
+		matcher: mock.JSONEq(math.NaN()),
+		value:   `1`,
+		expect:  false,
+	},
+
+	"fields struct": {
+		matcher: mock.Fields(map[string]any{"Name": "name"}),
+		value:   MatcherStruct{Name: "name", Count: 1},
+		expect:  true,
+	},
+	"fields pointer": {
+		matcher: mock.Fields(map[string]any{
+			"Name":  "name",
+			"Count": mock.Approx(1, 0),
+		}),
+		value:  &MatcherStruct{Name: "name", Count: 1},
+		expect: true,
+	},
+	"fields unexported": {
+		matcher: mock.Fields(map[string]any{
+			"time": mock.Within(matcherTime, time.Second),
+		}),
+		value:  MatcherStruct{time: matcherTime.Add(time.Second)},
+		expect: true,
+	},
+	"fields mismatch": {
+		matcher: mock.Fields(map[string]any{"Name": "other"}),
+		value:   MatcherStruct{Name: "name"},
+		expect:  false,
+	},
+	"fields missing": {
+		matcher: mock.Fields(map[string]any{"Missing": "name"}),
+		value:   MatcherStruct{Name: "name"},
+		expect:  false,
+	},
+	"fields nil pointer": {
+		matcher: mock.Fields(map[string]any{"Name": "name"}),
+		value:   matcherStructNil,
+		expect:  false,
+	},
+	"fields no struct": {
+		matcher: mock.Fields(map[string]any{"Name": "name"}),
+		value:   "name",
+		expect:  false,
+	},
+
+	"within before": {
+		matcher: mock.Within(matcherTime, time.Second),
+		value:   matcherTime.Add(-time.Second),
+		expect:  true,
+	},
+	"within after": {
+		matcher: mock.Within(matcherTime, time.Second),
+		value:   matcherTime.Add(time.Second),
+		expect:  true,
+	},
+	"within pointer": {
+		matcher: mock.Within(matcherTime, time.Second),
+		value:   &matcherTime,
+		expect:  true,
+	},
+	"within too early": {
+		matcher: mock.Within(matcherTime, time.Second),
+		value:   matcherTime.Add(-time.Second - 1),
+		expect:  false,
+	},
+	"within too late": {
+		matcher: mock.Within(matcherTime, time.Second),
+		value:   matcherTime.Add(time.Second + 1),
+		expect:  false,
+	},
+	"within nil pointer": {
+		matcher: mock.Within(matcherTime, time.Second),
+		value:   matcherTimeNil,
+		expect:  false,
+	},
+	"within no time": {
+		matcher: mock.Within(matcherTime, time.Second),
+		value:   "time",
+		expect:  false,
+	},
+
+	"approx float64": {
+		matcher: mock.Approx(1.0, 0.01),
+		value:   1.005,
+		expect:  true,
+	},
+	"approx float32": {
+		matcher: mock.Approx(1.0, 0.01),
+		value:   float32(0.995),
+		expect:  true,
+	},
+	"approx int": {
+		matcher: mock.Approx(1.0, 0.01),
+		value:   int8(1),
+		expect:  true,
+	},
+	"approx uint": {
+		matcher: mock.Approx(1.0, 0.01),
+		value:   uint16(1),
+		expect:  true,
+	},
+	"approx mismatch": {
+		matcher: mock.Approx(1.0, 0.01),
+		value:   1.02,
+		expect:  false,
+	},
+	"approx nan": {
+		matcher: mock.Approx(1.0, 0.01),
+		value:   math.NaN(),
+		expect:  false,
+	},
+	"approx no number": {
+		matcher: mock.Approx(1.0, 0.01),
+		value:   "1.0",
+		expect:  false,
+	},
+
+	"contains all": {
+		matcher: mock.Contains(3, 1, 2),
+		value:   []int{1, 2, 3},
+		expect:  true,
+	},
+	"contains subset": {
+		matcher: mock.Contains("c", "a"),
+		value:   [3]string{"a", "b", "c"},
+		expect:  true,
+	},
+	"contains matcher": {
+		matcher: mock.Contains(gomock.Any(), 1),
+		value:   []int{1, 2},
+		expect:  true,
+	},
+	"contains duplicate": {
+		matcher: mock.Contains(1, 1),
+		value:   []int{1, 2},
+		expect:  false,
+	},
+	"contains missing": {
+		matcher: mock.Contains(1, 4),
+		value:   []int{1, 2, 3},
+		expect:  false,
+	},
+	"contains no slice": {
+		matcher: mock.Contains(1),
+		value:   1,
+		expect:  false,
+	},
+
+	"proto equal": {
+		matcher: mock.ProtoEq(wrapperspb.String("value")),
+		value:   wrapperspb.String("value"),
+		expect:  true,
+	},
+	"proto mismatch": {
+		matcher: mock.ProtoEq(wrapperspb.String("value")),
+		value:   wrapperspb.String("other"),
+		expect:  false,
+	},
+	"proto no message": {
+		matcher: mock.ProtoEq(wrapperspb.String("value")),
+		value:   "value",
+		expect:  false,
+	},
+}
+
+func TestMatcher(t *testing.T) {
+	test.Map(t, testMatcherParams).
+		Run(func(t test.Test, param MatcherParams) {
+			// When
+			result := param.matcher.Matches(param.value)
+
+			// Then
+			assert.Equal(t, param.expect, result)
+		})
+}
+
+type MatcherStringParams struct {
+	matcher gomock.Matcher
+	expect  string
+}
+
+var testMatcherStringParams = map[string]MatcherStringParams{
+	"all": {
+		matcher: mock.All(1, gomock.Any()),
+		expect:  "all of (is equal to 1 (int); is anything)",
+	},
+	"any": {
+		matcher: mock.Any(1, 2),
+		expect:  "any of (is equal to 1 (int); is equal to 2 (int))",
+	},
+	"not": {
+		matcher: mock.Not(1),
+		expect:  "not (is equal to 1 (int))",
+	},
+	"json": {
+		matcher: mock.JSONEq(map[string]any{"a": 1}),
+		expect:  `is JSON equivalent to {"a":1}`,
+	},
+	"json invalid": {
+		matcher: mock.JSONEq(`{"a"`),
+		expect: `is JSON equivalent to invalid {"a" ` +
+			`[unexpected end of JSON input]`,
+	},
+	"fields": {
+		matcher: mock.Fields(map[string]any{"b": 2, "a": "x"}),
+		expect: "has fields (a is equal to x (string); " +
+			"b is equal to 2 (int))",
+	},
+	"within": {
+		matcher: mock.Within(matcherTime, time.Second),
+		expect:  "is within 1s of 2023-01-01 12:00:00 +0000 UTC",
+	},
+	"approx": {
+		matcher: mock.Approx(1.5, 0.1),
+		expect:  "is approximately 1.5 (+/-0.1)",
+	},
+	"contains": {
+		matcher: mock.Contains(1, 2),
+		expect: "contains in any order (is equal to 1 (int); " +
+			"is equal to 2 (int))",
+	},
+	"proto": {
+		matcher: mock.ProtoEq(wrapperspb.Bool(true)),
+		expect:  "is proto equal to value:true (*wrapperspb.BoolValue)",
+	},
+}
+
+func TestMatcherString(t *testing.T) {
+	test.Map(t, testMatcherStringParams).
+		Run(func(t test.Test, param MatcherStringParams) {
+			// When
+			result := param.matcher.String()
+
+			// Then
+			assert.Equal(t, param.expect, result)
+		})
+}
@@ -6,6 +6,11 @@ package mock
 
 import (
 	"fmt"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	gosync "sync"
 
 	"github.com/golang/mock/gomock"
 
@@ -81,6 +86,20 @@ type Mocks struct {
 	wg sync.WaitGroup
 	// The map of mock singletons.
 	mocks map[reflect.Type]any
+	// The optional tracers of mock interactions.
+	tracers []Tracer
+	// The mutual exclusion group of the active exclusive setup.
+	exclusion *exclusion
+	// The mutual exclusion groups per mock interface, if enabled.
+	exclusive map[reflect.Type]*exclusion
+}
+
+// Tracer is a tracer receiving the mock interactions in order of occurrence.
+// It is used to record the interactions of a test for comparison with a
+// golden interaction trace.
+type Tracer interface {
+	// Trace receives the description of a mock interaction.
+	Trace(entry string)
 }
 
 // NewMock creates a new mock handler using given test reporter (`*testing.T`).
@@ -100,6 +119,20 @@ func (mocks *Mocks) Expect(fncalls SetupFunc) *Mocks {
 	return mocks
 }
 
+// T returns the test reporter of the mock handler, e.g. to resolve test case
+// specific values in mock setup functions.
+func (mocks *Mocks) T() gomock.TestReporter {
+	return mocks.ctrl.T
+}
+
+// Tracer adds a tracer receiving the mock interactions created via the
+// notification functions `Return`, `ReturnWith`, and `Panic`. If multiple
+// tracers are added, each tracer receives all mock interactions.
+func (mocks *Mocks) Tracer(tracer Tracer) *Mocks {
+	mocks.tracers = append(mocks.tracers, tracer)
+	return mocks
+}
+
 // syncWith used to synchronize the waitgroup of the mock setup with the wait
 // group of the given test reporter. This function is called automatically on
 // mock creation and therefore does not need to be called on the same reporter
@@ -154,43 +187,211 @@ func (mocks *Mocks) Wait() {
 // `DoAndReturn` to signal that a mock call setup was consumed returning the
 // given arguments as result.
 func (mocks *Mocks) Return(fn any, args ...any) any {
-	ftype := reflect.TypeOf(fn)
-	btype := reflect.BaseFuncOf(ftype, 1, 0)
-	return mocks.notify(btype, nil, args...)
+	btype := baseFuncOf(fn)
+	return mocks.notify(btype, fn, func() string { return traceArgs(args) },
+		mocks.exclusions(fn), nil, reflect.ValuesOut(btype, args...))
+}
+
+// ReturnWith is a convenience method providing a notification function for
+// `Do` or `DoAndReturn` to signal that a mock call setup was consumed applying
+// the given side effect on the actual call arguments before returning the
+// given arguments as result. This allows e.g. to set out-parameters via
+// `mock.SetArg`.
+func (mocks *Mocks) ReturnWith(fn any, effect Effect, args ...any) any {
+	btype := baseFuncOf(fn)
+	return mocks.notify(btype, fn, func() string { return traceArgs(args) },
+		mocks.exclusions(fn), func(in []reflect.Value) {
+			if effect != nil {
+				effect(reflect.ArgsIn(btype, in...))
+			}
+		}, reflect.ValuesOut(btype, args...))
 }
 
 // Panic is a convenience method providing a notification function for `Do` or
 // `DoAndReturn` to signal that a mock call setup was consumed while panicing
 // with given reason.
 func (mocks *Mocks) Panic(fn any, reason any) any {
+	return mocks.notify(baseFuncOf(fn), fn, func() string {
+		return fmt.Sprintf("panic(%v)", reason)
+	}, mocks.exclusions(fn), func([]reflect.Value) { panic(reason) }, nil)
+}
+
+// funcTypes is the cache of base function types derived from the interface
+// method functions provided to create notification functions.
+var funcTypes gosync.Map
+
+// baseFuncOf returns the base function type of the given interface method
+// function, i.e. the function type without the receiver argument. Since the
+// same interface method functions are used for a high number of mock call
+// setups, the derived function types are cached.
+func baseFuncOf(fn any) reflect.Type {
 	ftype := reflect.TypeOf(fn)
+	if btype, ok := funcTypes.Load(ftype); ok {
+		return btype.(reflect.Type)
+	}
 	btype := reflect.BaseFuncOf(ftype, 1, 0)
-	return mocks.notify(btype, func() { panic(reason) })
+	funcTypes.Store(ftype, btype)
+	return btype
+}
+
+// funcName returns the short name of the given interface method function,
+// i.e. the interface type name and the method name, e.g. `IFace.CallA`.
+func funcName(fn any) string {
+	name := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()
+	name = name[strings.LastIndex(name, "/")+1:]
+	if _, method, ok := strings.Cut(name, "."); ok {
+		return method
+	}
+	return name
+}
+
+// traceArgs returns the trace description of the given arguments.
+func traceArgs(args []any) string {
+	return "(" + traceValues(args) + ")"
+}
+
+// traceValues returns the trace description of the given values.
+func traceValues(values []any) string {
+	texts := make([]string, 0, len(values))
+	for _, value := range values {
+		texts = append(texts, traceValue(reflect.ValueOf(value),
+			map[uintptr]bool{}))
+	}
+	return strings.Join(texts, ", ")
+}
+
+// traceValue returns the trace description of the given value. To create
+// stable traces, pointers are described by the referenced values instead of
+// addresses, strings are quoted, and map entries are sorted. Pointers already
+// visited are described as `&<cycle>` to terminate cyclic references.
+func traceValue(value reflect.Value, visited map[uintptr]bool) string {
+	if !value.IsValid() || ((value.Kind() == reflect.Pointer ||
+		value.Kind() == reflect.Interface) && value.IsNil()) {
+		return "<nil>"
+	} else if value.CanInterface() {
+		switch value := value.Interface().(type) {
+		case error:
+			return value.Error()
+		case fmt.Stringer:
+			return value.String()
+		}
+	}
+
+	switch value.Kind() {
+	case reflect.Pointer:
+		if visited[value.Pointer()] {
+			return "&<cycle>"
+		}
+		visited[value.Pointer()] = true
+		defer delete(visited, value.Pointer())
+		return "&" + traceValue(value.Elem(), visited)
+	case reflect.Interface:
+		return traceValue(value.Elem(), visited)
+	case reflect.String:
+		return strconv.Quote(value.String())
+	case reflect.Struct:
+		texts := make([]string, 0, value.NumField())
+		for index := 0; index < value.NumField(); index++ {
+			texts = append(texts, traceValue(value.Field(index), visited))
+		}
+		return "{" + strings.Join(texts, " ") + "}"
+	case reflect.Slice, reflect.Array:
+		texts := make([]string, 0, value.Len())
+		for index := 0; index < value.Len(); index++ {
+			texts = append(texts, traceValue(value.Index(index), visited))
+		}
+		return "[" + strings.Join(texts, " ") + "]"
+	case reflect.Map:
+		texts := make([]string, 0, value.Len())
+		for iter := value.MapRange(); iter.Next(); {
+			texts = append(texts, traceValue(iter.Key(), visited)+":"+
+				traceValue(iter.Value(), visited))
+		}
+		sort.Strings(texts)
+		return "map[" + strings.Join(texts, " ") + "]"
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return value.Type().String()
+	}
+	return fmt.Sprintf("%v", value)
 }
 
 // notify is a generic method for providing a customized notification function
 // of given function call type with given custom call behavior and given return
-// arguments for usage in `Do` or `DoAndReturn`.
+// values for usage in `Do` or `DoAndReturn`. The return values are expected
+// to be created and validated once during setup to be reused on each call. If
+// tracers are set up, the call is traced using the name of the given method
+// function and the given result description, which are both only created on
+// demand. While the call is active, it is registered in the given mutual
+// exclusion groups to detect overlapping invocations.
 func (mocks *Mocks) notify(
-	ftype reflect.Type, call func(), args ...any,
+	ftype reflect.Type, fn any, result func() string, groups []*exclusion,
+	call func([]reflect.Value), values []reflect.Value,
 ) any {
 	mocks.wg.Add(1)
 
 	notify := reflect.MakeFuncOf(ftype,
-		func([]reflect.Value) []reflect.Value {
+		func(in []reflect.Value) []reflect.Value {
 			mocks.ctrl.T.Helper()
 
 			defer mocks.wg.Done()
+			for _, group := range groups {
+				defer group.enter(mocks.ctrl.T, fn)()
+			}
+			if len(mocks.tracers) != 0 {
+				mocks.trace(funcName(fn), in, result())
+			}
 			if call != nil {
-				call()
+				call(in)
 			}
 
-			return reflect.ValuesOut(ftype, args...)
+			return values
 		})
 
 	return notify
 }
 
+// trace traces the mock call of the method with given name, given actual
+// arguments, and given result description using all tracers.
+func (mocks *Mocks) trace(name string, in []reflect.Value, result string) {
+	args := make([]any, 0, len(in))
+	for _, arg := range in {
+		args = append(args, arg.Interface())
+	}
+	entry := name + "(" + traceValues(args) + ") => " + result
+	for _, tracer := range mocks.tracers {
+		tracer.Trace(entry)
+	}
+}
+
+// Effect is a side effect function that is applied to the actual arguments of
+// a mock call by a notification function created via `ReturnWith`. Variadic
+// arguments are provided expanded as single arguments.
+type Effect func(args []any)
+
+// SetArg creates a side effect that assigns the given value to the target of
+// the argument with given index. If the argument is a pointer, the value is
+// assigned to the referenced element; if it is a slice, the value slice is
+// copied into the argument slice; and if it is a map, the value map entries
+// are added to the argument map.
+func SetArg(index int, value any) Effect {
+	return func(args []any) {
+		if index < 0 || index >= len(args) {
+			panic(ErrArgIndex(index, len(args)))
+		}
+		reflect.Assign(args[index], value)
+	}
+}
+
+// Effects creates a side effect that applies all given side effects in the
+// given order.
+func Effects(effects ...Effect) Effect {
+	return func(args []any) {
+		for _, effect := range effects {
+			effect(args)
+		}
+	}
+}
+
 // TODO: Reconsider approach - complex signature. Test setup look as follows:
 //
 //	func CallBX(input string, output string) mock.SetupFunc {
@@ -219,7 +420,9 @@ func (mocks *Mocks) notify(
 // }
 
 // Get resolves the actual mock from the mock handler by providing the
-// constructor function generated by `gomock` to create a new mock.
+// constructor function generated by `gomock` to create a new mock. For generic
+// mocks the instantiated constructor, e.g. `NewMockRepo[K, V]`, must be
+// provided, creating a distinct mock singleton per type instantiation.
 func Get[T any](mocks *Mocks, creator func(*Controller) *T) *T {
 	ctype := reflect.TypeOf(creator)
 	mock, ok := mocks.mocks[ctype]
@@ -486,6 +689,12 @@ func ErrDetachMode(mode DetachMode) error {
 	return fmt.Errorf("detach mode [%v] is not supported", mode)
 }
 
+// ErrArgIndex creates an error that the given argument index is not
+// available in the actual arguments of a mock call.
+func ErrArgIndex(index, args int) error {
+	return fmt.Errorf("argument index [%d] out of range [0:%d]", index, args)
+}
+
 // ErrDetachNotAllowed creates an error that detach.
 func ErrDetachNotAllowed(mode DetachMode) error {
 	return fmt.Errorf("detach [%v] not supported in sub", mode)
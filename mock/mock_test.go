@@ -22,6 +22,11 @@ type IFace interface {
 	CallB(string) string
 }
 
+type Decoder interface {
+	Decode(into any) error
+	Scan(dest ...any) error
+}
+
 func CallA(input string) mock.SetupFunc {
 	return func(mocks *mock.Mocks) any {
 		return mock.Get(mocks, NewMockIFace).EXPECT().
@@ -36,6 +41,20 @@ func CallB(input string, output string) mock.SetupFunc {
 	}
 }
 
+func Decode(effect mock.Effect, err error) mock.SetupFunc {
+	return func(mocks *mock.Mocks) any {
+		return mock.Get(mocks, NewMockDecoder).EXPECT().Decode(gomock.Any()).
+			DoAndReturn(mocks.ReturnWith(Decoder.Decode, effect, err))
+	}
+}
+
+func Scan(effect mock.Effect, err error) mock.SetupFunc {
+	return func(mocks *mock.Mocks) any {
+		return mock.Get(mocks, NewMockDecoder).EXPECT().Scan(gomock.Any()).
+			DoAndReturn(mocks.ReturnWith(Decoder.Scan, effect, err))
+	}
+}
+
 // func CallBX(input string, output string) mock.SetupFunc {
 // 	return mock.Mock(NewMockIFace, func(mock *MockIFace) *gomock.Call {
 // 		return mock.EXPECT().CallB(input).Return(output)
@@ -628,3 +647,319 @@ func TestFailures(t *testing.T) {
 			mocks.Wait()
 		})
 }
+
+type ReturnWithParams struct {
+	setup       mock.SetupFunc
+	call        func(Decoder) error
+	expectError error
+	expectPanic error
+	expectValue any
+}
+
+var testReturnWithParams = map[string]ReturnWithParams{
+	"decode nothing": {
+		setup: Decode(nil, nil),
+		call: func(decoder Decoder) error {
+			return decoder.Decode(&ExportStruct{})
+		},
+		expectValue: &ExportStruct{},
+	},
+	"decode error": {
+		setup: Decode(nil, assert.AnError),
+		call: func(decoder Decoder) error {
+			return decoder.Decode(&ExportStruct{})
+		},
+		expectError: assert.AnError,
+		expectValue: &ExportStruct{},
+	},
+	"decode pointer": {
+		setup: Decode(mock.SetArg(0, ExportStruct{Value: "value"}), nil),
+		call: func(decoder Decoder) error {
+			return decoder.Decode(&ExportStruct{})
+		},
+		expectValue: &ExportStruct{Value: "value"},
+	},
+	"decode pointer nil": {
+		setup: Decode(mock.SetArg(0, nil), nil),
+		call: func(decoder Decoder) error {
+			return decoder.Decode(&ExportStruct{Value: "value"})
+		},
+		expectValue: &ExportStruct{},
+	},
+	"decode slice": {
+		setup: Decode(mock.SetArg(0, []string{"a", "b"}), nil),
+		call: func(decoder Decoder) error {
+			return decoder.Decode(make([]string, 2))
+		},
+		expectValue: []string{"a", "b"},
+	},
+	"decode map": {
+		setup: Decode(mock.SetArg(0, map[string]int{"a": 1}), nil),
+		call: func(decoder Decoder) error {
+			return decoder.Decode(map[string]int{"b": 2})
+		},
+		expectValue: map[string]int{"a": 1, "b": 2},
+	},
+	"scan variadic": {
+		setup: Scan(mock.Effects(
+			mock.SetArg(0, "value"),
+			mock.SetArg(1, 2),
+		), nil),
+		call: func(decoder Decoder) error {
+			return decoder.Scan(new(string), new(int))
+		},
+		expectValue: []any{"value", 2},
+	},
+	"scan index out of range": {
+		setup: Scan(mock.SetArg(2, "value"), nil),
+		call: func(decoder Decoder) error {
+			return decoder.Scan(new(string), new(int))
+		},
+		expectPanic: mock.ErrArgIndex(2, 2),
+	},
+	"scan not assignable": {
+		setup: Scan(mock.SetArg(1, "value"), nil),
+		call: func(decoder Decoder) error {
+			return decoder.Scan(new(string), new(int))
+		},
+		expectPanic: reflect.ErrNotAssignable(new(int), "value"),
+	},
+	"decode no reference": {
+		setup: Decode(mock.SetArg(0, "value"), nil),
+		call: func(decoder Decoder) error {
+			return decoder.Decode("value")
+		},
+		expectPanic: reflect.ErrNotAssignable("value", "value"),
+	},
+}
+
+type ExportStruct struct {
+	Value string
+}
+
+func TestReturnWith(t *testing.T) {
+	test.Map(t, testReturnWithParams).
+		Run(func(t test.Test, param ReturnWithParams) {
+			// Given
+			mocks := MockSetup(t, param.setup)
+			decoder := mock.Get(mocks, NewMockDecoder)
+			var value any
+			call := func(into any) error {
+				value = into
+				return decoder.Decode(into)
+			}
+
+			if param.expectPanic != nil {
+				defer func() {
+					assert.Equal(t, param.expectPanic, recover())
+					mocks.Wait()
+				}()
+			}
+
+			// When
+			err := param.call(&decoderSpy{Decoder: decoder, decode: call,
+				scan: func(dest ...any) error {
+					err := decoder.Scan(dest...)
+					values := make([]any, 0, len(dest))
+					for _, arg := range dest {
+						values = append(values,
+							reflect.ValueOf(arg).Elem().Interface())
+					}
+					value = values
+					return err
+				}})
+			mocks.Wait()
+
+			// Then
+			assert.Nil(t, param.expectPanic, "not paniced")
+			assert.Equal(t, param.expectError, err)
+			assert.Equal(t, param.expectValue, value)
+		})
+}
+
+// decoderSpy is a decoder spy to capture the decoded argument values.
+type decoderSpy struct {
+	Decoder
+	decode func(into any) error
+	scan   func(dest ...any) error
+}
+
+func (s *decoderSpy) Decode(into any) error {
+	return s.decode(into)
+}
+
+func (s *decoderSpy) Scan(dest ...any) error {
+	return s.scan(dest...)
+}
+
+// tracer is a simple tracer recording the traced mock interactions.
+type tracer struct {
+	entries []string
+}
+
+func (t *tracer) Trace(entry string) {
+	t.entries = append(t.entries, entry)
+}
+
+type TracerParams struct {
+	setup       mock.SetupFunc
+	call        func(mocks *mock.Mocks)
+	expectTrace []string
+}
+
+// cycle is a cyclic structure to test tracing of cyclic references.
+type cycle struct {
+	next *cycle
+}
+
+var testTracerParams = map[string]TracerParams{
+	"no calls": {},
+	"call-a": {
+		setup: CallA("a"),
+		call: func(mocks *mock.Mocks) {
+			mock.Get(mocks, NewMockIFace).CallA("a")
+		},
+		expectTrace: []string{
+			`IFace.CallA("a") => ()`,
+		},
+	},
+	"call-a and call-b": {
+		setup: mock.Chain(CallA("a"), CallB("b", "c")),
+		call: func(mocks *mock.Mocks) {
+			iface := mock.Get(mocks, NewMockIFace)
+			iface.CallA("a")
+			iface.CallB("b")
+		},
+		expectTrace: []string{
+			`IFace.CallA("a") => ()`,
+			`IFace.CallB("b") => ("c")`,
+		},
+	},
+	"pointer args": {
+		setup: Decode(nil, nil),
+		call: func(mocks *mock.Mocks) {
+			value := 1
+			_ = mock.Get(mocks, NewMockDecoder).Decode(&struct {
+				name  *string
+				value *int
+			}{value: &value})
+		},
+		expectTrace: []string{
+			`Decoder.Decode(&{<nil> &1}) => (<nil>)`,
+		},
+	},
+	"collection args": {
+		setup: Decode(nil, nil),
+		call: func(mocks *mock.Mocks) {
+			value := "a"
+			_ = mock.Get(mocks, NewMockDecoder).Decode([]any{
+				map[string]*string{"b": &value, "a": nil}, [1]int{1},
+			})
+		},
+		expectTrace: []string{
+			`Decoder.Decode([map["a":<nil> "b":&"a"] [1]]) => (<nil>)`,
+		},
+	},
+	"cyclic args": {
+		setup: Decode(nil, nil),
+		call: func(mocks *mock.Mocks) {
+			value := &cycle{}
+			value.next = value
+			_ = mock.Get(mocks, NewMockDecoder).Decode(value)
+		},
+		expectTrace: []string{
+			`Decoder.Decode(&{&<cycle>}) => (<nil>)`,
+		},
+	},
+	"error results": {
+		setup: Decode(nil, assert.AnError),
+		call: func(mocks *mock.Mocks) {
+			_ = mock.Get(mocks, NewMockDecoder).Decode(nil)
+		},
+		expectTrace: []string{
+			`Decoder.Decode(<nil>) => (` + assert.AnError.Error() + `)`,
+		},
+	},
+}
+
+func TestTracer(t *testing.T) {
+	test.Map(t, testTracerParams).
+		Run(func(t test.Test, param TracerParams) {
+			// Given
+			tracer, other := &tracer{}, &tracer{}
+			mocks := mock.NewMock(t).Tracer(tracer).Tracer(other).
+				Expect(param.setup)
+
+			// When
+			if param.call != nil {
+				param.call(mocks)
+			}
+
+			// Then
+			mocks.Wait()
+			assert.Equal(t, param.expectTrace, tracer.entries)
+			assert.Equal(t, param.expectTrace, other.entries)
+		})
+}
+
+func TestT(t *testing.T) {
+	// Given
+	mocks := mock.NewMock(t)
+
+	// When
+	reporter := mocks.T()
+
+	// Then
+	assert.Same(t, t, reporter)
+}
+
+func BenchmarkReturn(b *testing.B) {
+	mocks := mock.NewMock(b)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		mocks.Return(IFace.CallB, "output")
+	}
+}
+
+func BenchmarkReturnCall(b *testing.B) {
+	mocks := mock.NewMock(b)
+	calls := make([]func(string) string, 0, b.N)
+	for i := 0; i < b.N; i++ {
+		calls = append(calls,
+			mocks.Return(IFace.CallB, "output").(func(string) string))
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for _, call := range calls {
+		call("input")
+	}
+}
+
+func BenchmarkPanic(b *testing.B) {
+	mocks := mock.NewMock(b)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		mocks.Panic(IFace.CallB, "panic")
+	}
+}
+
+func BenchmarkExpect(b *testing.B) {
+	mocks := mock.NewMock(b)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		mocks.Expect(CallB("input", "output"))
+	}
+
+	b.StopTimer()
+	iface := mock.Get(mocks, NewMockIFace)
+	for i := 0; i < b.N; i++ {
+		iface.CallB("input")
+	}
+}
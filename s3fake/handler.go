@@ -0,0 +1,675 @@
+package s3fake
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Namespace is the XML namespace of the S3 API responses.
+const Namespace = "http://s3.amazonaws.com/doc/2006-03-01/"
+
+// S3 error codes used by the object storage and for fault injection.
+const (
+	CodeAccessDenied       = "AccessDenied"
+	CodeBucketExists       = "BucketAlreadyOwnedByYou"
+	CodeBucketNotEmpty     = "BucketNotEmpty"
+	CodeIncompleteBody     = "IncompleteBody"
+	CodeInternalError      = "InternalError"
+	CodeInvalidArgument    = "InvalidArgument"
+	CodeInvalidPart        = "InvalidPart"
+	CodeInvalidPartOrder   = "InvalidPartOrder"
+	CodeMalformedXML       = "MalformedXML"
+	CodeMethodNotAllowed   = "MethodNotAllowed"
+	CodeNoSuchBucket       = "NoSuchBucket"
+	CodeNoSuchKey          = "NoSuchKey"
+	CodeNoSuchUpload       = "NoSuchUpload"
+	CodeNotModified        = "NotModified"
+	CodePreconditionFailed = "PreconditionFailed"
+	CodeServiceUnavailable = "ServiceUnavailable"
+	CodeSlowDown           = "SlowDown"
+)
+
+// Error is the XML error response of the S3 API.
+type Error struct {
+	XMLName  xml.Name `xml:"Error"`
+	Code     string   `xml:"Code"`
+	Message  string   `xml:"Message"`
+	Resource string   `xml:"Resource"`
+}
+
+// ListAllMyBucketsResult is the XML response listing the buckets.
+type ListAllMyBucketsResult struct {
+	XMLName xml.Name     `xml:"ListAllMyBucketsResult"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	Buckets []BucketInfo `xml:"Buckets>Bucket"`
+}
+
+// BucketInfo is the XML description of a bucket.
+type BucketInfo struct {
+	Name         string `xml:"Name"`
+	CreationDate string `xml:"CreationDate"`
+}
+
+// ListBucketResult is the XML response listing the objects of a bucket using
+// the list objects version 2 API.
+type ListBucketResult struct {
+	XMLName               xml.Name       `xml:"ListBucketResult"`
+	Xmlns                 string         `xml:"xmlns,attr"`
+	Name                  string         `xml:"Name"`
+	Prefix                string         `xml:"Prefix"`
+	Delimiter             string         `xml:"Delimiter,omitempty"`
+	MaxKeys               int            `xml:"MaxKeys"`
+	KeyCount              int            `xml:"KeyCount"`
+	IsTruncated           bool           `xml:"IsTruncated"`
+	ContinuationToken     string         `xml:"ContinuationToken,omitempty"`
+	NextContinuationToken string         `xml:"NextContinuationToken,omitempty"`
+	StartAfter            string         `xml:"StartAfter,omitempty"`
+	Contents              []ObjectInfo   `xml:"Contents"`
+	CommonPrefixes        []CommonPrefix `xml:"CommonPrefixes"`
+}
+
+// ObjectInfo is the XML description of an object.
+type ObjectInfo struct {
+	Key          string `xml:"Key"`
+	LastModified string `xml:"LastModified"`
+	ETag         string `xml:"ETag"`
+	Size         int    `xml:"Size"`
+	StorageClass string `xml:"StorageClass"`
+}
+
+// CommonPrefix is the XML description of a common key prefix.
+type CommonPrefix struct {
+	Prefix string `xml:"Prefix"`
+}
+
+// InitiateMultipartUploadResult is the XML response of an initiated multipart
+// upload.
+type InitiateMultipartUploadResult struct {
+	XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	Bucket   string   `xml:"Bucket"`
+	Key      string   `xml:"Key"`
+	UploadID string   `xml:"UploadId"`
+}
+
+// CompleteMultipartUpload is the XML request completing a multipart upload.
+type CompleteMultipartUpload struct {
+	XMLName xml.Name       `xml:"CompleteMultipartUpload"`
+	Parts   []CompletePart `xml:"Part"`
+}
+
+// CompletePart is the XML description of a part of a completed multipart
+// upload.
+type CompletePart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+// CompleteMultipartUploadResult is the XML response of a completed multipart
+// upload.
+type CompleteMultipartUploadResult struct {
+	XMLName  xml.Name `xml:"CompleteMultipartUploadResult"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	Location string   `xml:"Location"`
+	Bucket   string   `xml:"Bucket"`
+	Key      string   `xml:"Key"`
+	ETag     string   `xml:"ETag"`
+}
+
+// ServeHTTP serves the path-style S3 API requests, i.e. requests of the form
+// `/bucket/key`, of the object storage.
+func (s *Storage) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if fault := s.fault(req); fault != nil {
+		s.error(w, req, fault.Status, fault.Code, "injected fault")
+		return
+	}
+
+	name, key, _ := strings.Cut(strings.TrimPrefix(req.URL.Path, "/"), "/")
+	query := req.URL.Query()
+	switch {
+	case name == "" && req.Method == http.MethodGet:
+		s.listBuckets(w)
+	case name == "":
+		s.notAllowed(w, req)
+	case key == "":
+		switch req.Method {
+		case http.MethodPut:
+			s.createBucket(w, req, name)
+		case http.MethodDelete:
+			s.deleteBucket(w, req, name)
+		case http.MethodHead:
+			s.headBucket(w, req, name)
+		case http.MethodGet:
+			s.listObjects(w, req, name)
+		default:
+			s.notAllowed(w, req)
+		}
+	case req.Method == http.MethodPost && query.Has("uploads"):
+		s.initiateUpload(w, req, name, key)
+	case req.Method == http.MethodPost && query.Has("uploadId"):
+		s.completeUpload(w, req, name, key, query.Get("uploadId"))
+	case req.Method == http.MethodPut && query.Has("uploadId"):
+		s.uploadPart(w, req, name, key, query.Get("uploadId"),
+			query.Get("partNumber"))
+	case req.Method == http.MethodDelete && query.Has("uploadId"):
+		s.abortUpload(w, req, name, key, query.Get("uploadId"))
+	case req.Method == http.MethodPut:
+		s.putObject(w, req, name, key)
+	case req.Method == http.MethodGet || req.Method == http.MethodHead:
+		s.getObject(w, req, name, key)
+	case req.Method == http.MethodDelete:
+		s.deleteObject(w, req, name, key)
+	default:
+		s.notAllowed(w, req)
+	}
+}
+
+// listBuckets lists the buckets of the object storage.
+func (s *Storage) listBuckets(w http.ResponseWriter) {
+	s.mu.Lock()
+	result := ListAllMyBucketsResult{Xmlns: Namespace}
+	for name, b := range s.buckets {
+		result.Buckets = append(result.Buckets, BucketInfo{
+			Name: name, CreationDate: b.created.Format(time.RFC3339),
+		})
+	}
+	s.mu.Unlock()
+
+	sort.Slice(result.Buckets, func(i, j int) bool {
+		return result.Buckets[i].Name < result.Buckets[j].Name
+	})
+	s.xml(w, http.StatusOK, result)
+}
+
+// createBucket creates the bucket with given name.
+func (s *Storage) createBucket(
+	w http.ResponseWriter, req *http.Request, name string,
+) {
+	s.mu.Lock()
+	exists := s.bucket(name, false) != nil
+	if !exists {
+		s.bucket(name, true)
+	}
+	s.mu.Unlock()
+
+	if exists {
+		s.error(w, req, http.StatusConflict, CodeBucketExists,
+			"bucket already exists")
+		return
+	}
+	w.Header().Set("Location", "/"+name)
+	w.WriteHeader(http.StatusOK)
+}
+
+// deleteBucket deletes the empty bucket with given name.
+func (s *Storage) deleteBucket(
+	w http.ResponseWriter, req *http.Request, name string,
+) {
+	s.mu.Lock()
+	b := s.bucket(name, false)
+	empty := b != nil && len(b.objects) == 0
+	if empty {
+		delete(s.buckets, name)
+	}
+	s.mu.Unlock()
+
+	if b == nil {
+		s.error(w, req, http.StatusNotFound, CodeNoSuchBucket,
+			"bucket does not exist")
+	} else if !empty {
+		s.error(w, req, http.StatusConflict, CodeBucketNotEmpty,
+			"bucket not empty")
+	} else {
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// headBucket checks whether the bucket with given name exists.
+func (s *Storage) headBucket(
+	w http.ResponseWriter, req *http.Request, name string,
+) {
+	s.mu.Lock()
+	exists := s.bucket(name, false) != nil
+	s.mu.Unlock()
+
+	if !exists {
+		s.error(w, req, http.StatusNotFound, CodeNoSuchBucket,
+			"bucket does not exist")
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// listObjects lists the objects of the bucket with given name using the list
+// objects version 2 API supporting prefix, delimiter, max-keys, start-after,
+// and continuation tokens.
+func (s *Storage) listObjects(
+	w http.ResponseWriter, req *http.Request, name string,
+) {
+	query := req.URL.Query()
+	result := ListBucketResult{
+		Xmlns: Namespace, Name: name, MaxKeys: 1000,
+		Prefix:            query.Get("prefix"),
+		Delimiter:         query.Get("delimiter"),
+		ContinuationToken: query.Get("continuation-token"),
+		StartAfter:        query.Get("start-after"),
+	}
+	if value := query.Get("max-keys"); value != "" {
+		maxKeys, err := strconv.Atoi(value)
+		if err != nil || maxKeys < 0 {
+			s.error(w, req, http.StatusBadRequest, CodeInvalidArgument,
+				"invalid max-keys")
+			return
+		}
+		result.MaxKeys = maxKeys
+	}
+	start := result.StartAfter
+	if result.ContinuationToken != "" {
+		start = result.ContinuationToken
+	}
+
+	s.mu.Lock()
+	b := s.bucket(name, false)
+	if b == nil {
+		s.mu.Unlock()
+		s.error(w, req, http.StatusNotFound, CodeNoSuchBucket,
+			"bucket does not exist")
+		return
+	}
+
+	type entry struct {
+		info   *ObjectInfo
+		prefix string
+		last   string
+	}
+	entries := []*entry{}
+	for _, key := range s.keys(b) {
+		if key <= start || !strings.HasPrefix(key, result.Prefix) {
+			continue
+		}
+
+		rest := key[len(result.Prefix):]
+		if index := strings.Index(rest, result.Delimiter); result.Delimiter != "" &&
+			index >= 0 {
+			prefix := result.Prefix + rest[:index+len(result.Delimiter)]
+			if last := len(entries) - 1; last >= 0 &&
+				entries[last].prefix == prefix {
+				entries[last].last = key
+				continue
+			}
+			entries = append(entries, &entry{prefix: prefix, last: key})
+			continue
+		}
+
+		obj := b.objects[key]
+		entries = append(entries, &entry{last: key, info: &ObjectInfo{
+			Key: key, ETag: obj.etag, Size: len(obj.data),
+			LastModified: obj.modified.Format(time.RFC3339),
+			StorageClass: "STANDARD",
+		}})
+	}
+	s.mu.Unlock()
+
+	if len(entries) > result.MaxKeys {
+		entries, result.IsTruncated = entries[:result.MaxKeys], true
+		if len(entries) > 0 {
+			result.NextContinuationToken = entries[len(entries)-1].last
+		}
+	}
+	for _, entry := range entries {
+		if entry.info != nil {
+			result.Contents = append(result.Contents, *entry.info)
+		} else {
+			result.CommonPrefixes = append(result.CommonPrefixes,
+				CommonPrefix{Prefix: entry.prefix})
+		}
+	}
+	result.KeyCount = len(entries)
+	s.xml(w, http.StatusOK, result)
+}
+
+// putObject stores the object with given key in the bucket with given name
+// evaluating the conditional request headers `If-Match` and `If-None-Match`.
+func (s *Storage) putObject(
+	w http.ResponseWriter, req *http.Request, name, key string,
+) {
+	data, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.error(w, req, http.StatusBadRequest, CodeIncompleteBody, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	b := s.bucket(name, false)
+	if b == nil {
+		s.mu.Unlock()
+		s.error(w, req, http.StatusNotFound, CodeNoSuchBucket,
+			"bucket does not exist")
+		return
+	}
+
+	current := b.objects[key]
+	if status, code := preconditions(req, current, true); status != 0 {
+		s.mu.Unlock()
+		s.error(w, req, status, code, "precondition failed")
+		return
+	}
+	obj := newObject(data, objectHeader(req.Header))
+	b.objects[key] = obj
+	s.mu.Unlock()
+
+	w.Header().Set("ETag", obj.etag)
+	w.WriteHeader(http.StatusOK)
+}
+
+// getObject returns the object with given key in the bucket with given name
+// evaluating the conditional request headers `If-Match`, `If-None-Match`,
+// `If-Modified-Since`, and `If-Unmodified-Since`.
+func (s *Storage) getObject(
+	w http.ResponseWriter, req *http.Request, name, key string,
+) {
+	s.mu.Lock()
+	b := s.bucket(name, false)
+	var obj *object
+	if b != nil {
+		obj = b.objects[key]
+	}
+	s.mu.Unlock()
+
+	if b == nil {
+		s.error(w, req, http.StatusNotFound, CodeNoSuchBucket,
+			"bucket does not exist")
+		return
+	} else if obj == nil {
+		s.error(w, req, http.StatusNotFound, CodeNoSuchKey,
+			"key does not exist")
+		return
+	}
+
+	for key, values := range obj.header {
+		w.Header()[key] = values
+	}
+	w.Header().Set("ETag", obj.etag)
+	w.Header().Set("Last-Modified", obj.modified.Format(http.TimeFormat))
+	if status, code := preconditions(req, obj, false); status != 0 {
+		if status == http.StatusNotModified {
+			w.WriteHeader(status)
+		} else {
+			s.error(w, req, status, code, "precondition failed")
+		}
+		return
+	}
+
+	w.Header().Set("Content-Length", strconv.Itoa(len(obj.data)))
+	w.WriteHeader(http.StatusOK)
+	if req.Method == http.MethodGet {
+		_, _ = w.Write(obj.data)
+	}
+}
+
+// deleteObject deletes the object with given key in the bucket with given
+// name. Deleting a missing object succeeds.
+func (s *Storage) deleteObject(
+	w http.ResponseWriter, req *http.Request, name, key string,
+) {
+	s.mu.Lock()
+	b := s.bucket(name, false)
+	if b != nil {
+		delete(b.objects, key)
+	}
+	s.mu.Unlock()
+
+	if b == nil {
+		s.error(w, req, http.StatusNotFound, CodeNoSuchBucket,
+			"bucket does not exist")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// initiateUpload initiates a multipart upload of the object with given key in
+// the bucket with given name.
+func (s *Storage) initiateUpload(
+	w http.ResponseWriter, req *http.Request, name, key string,
+) {
+	s.mu.Lock()
+	b := s.bucket(name, false)
+	if b == nil {
+		s.mu.Unlock()
+		s.error(w, req, http.StatusNotFound, CodeNoSuchBucket,
+			"bucket does not exist")
+		return
+	}
+	s.uploads++
+	id := fmt.Sprintf("upload-%d", s.uploads)
+	b.uploads[id] = &upload{
+		key: key, parts: map[int]*object{},
+		header: objectHeader(req.Header),
+	}
+	s.mu.Unlock()
+
+	s.xml(w, http.StatusOK, InitiateMultipartUploadResult{
+		Xmlns: Namespace, Bucket: name, Key: key, UploadID: id,
+	})
+}
+
+// uploadPart uploads a part of the multipart upload with given identifier.
+func (s *Storage) uploadPart(
+	w http.ResponseWriter, req *http.Request, name, key, id, number string,
+) {
+	part, err := strconv.Atoi(number)
+	if err != nil || part < 1 || part > 10000 {
+		s.error(w, req, http.StatusBadRequest, CodeInvalidArgument,
+			"invalid part number")
+		return
+	}
+	data, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.error(w, req, http.StatusBadRequest, CodeIncompleteBody, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	upload := s.upload(name, key, id)
+	var obj *object
+	if upload != nil {
+		obj = newObject(data, nil)
+		upload.parts[part] = obj
+	}
+	s.mu.Unlock()
+
+	if upload == nil {
+		s.error(w, req, http.StatusNotFound, CodeNoSuchUpload,
+			"upload does not exist")
+		return
+	}
+	w.Header().Set("ETag", obj.etag)
+	w.WriteHeader(http.StatusOK)
+}
+
+// completeUpload completes the multipart upload with given identifier using
+// the parts listed in the request in ascending order.
+func (s *Storage) completeUpload(
+	w http.ResponseWriter, req *http.Request, name, key, id string,
+) {
+	request := CompleteMultipartUpload{}
+	if err := xml.NewDecoder(req.Body).Decode(&request); err != nil ||
+		len(request.Parts) == 0 {
+		s.error(w, req, http.StatusBadRequest, CodeMalformedXML,
+			"invalid complete multipart upload request")
+		return
+	}
+
+	s.mu.Lock()
+	upload := s.upload(name, key, id)
+	if upload == nil {
+		s.mu.Unlock()
+		s.error(w, req, http.StatusNotFound, CodeNoSuchUpload,
+			"upload does not exist")
+		return
+	}
+
+	data, sums := []byte{}, []byte{}
+	for index, part := range request.Parts {
+		if index > 0 && part.PartNumber <= request.Parts[index-1].PartNumber {
+			s.mu.Unlock()
+			s.error(w, req, http.StatusBadRequest, CodeInvalidPartOrder,
+				"parts not in ascending order")
+			return
+		}
+		obj, ok := upload.parts[part.PartNumber]
+		if !ok || (part.ETag != "" && part.ETag != obj.etag) {
+			s.mu.Unlock()
+			s.error(w, req, http.StatusBadRequest, CodeInvalidPart,
+				fmt.Sprintf("invalid part [number=%d]", part.PartNumber))
+			return
+		}
+		sum, _ := hex.DecodeString(strings.Trim(obj.etag, `"`))
+		data, sums = append(data, obj.data...), append(sums, sum...)
+	}
+
+	sum := md5.Sum(sums)
+	obj := newObject(data, upload.header)
+	obj.etag = fmt.Sprintf(`"%s-%d"`, hex.EncodeToString(sum[:]),
+		len(request.Parts))
+	b := s.bucket(name, false)
+	b.objects[key] = obj
+	delete(b.uploads, id)
+	s.mu.Unlock()
+
+	s.xml(w, http.StatusOK, CompleteMultipartUploadResult{
+		Xmlns: Namespace, Location: "/" + name + "/" + key,
+		Bucket: name, Key: key, ETag: obj.etag,
+	})
+}
+
+// abortUpload aborts the multipart upload with given identifier.
+func (s *Storage) abortUpload(
+	w http.ResponseWriter, req *http.Request, name, key, id string,
+) {
+	s.mu.Lock()
+	upload := s.upload(name, key, id)
+	if upload != nil {
+		delete(s.bucket(name, false).uploads, id)
+	}
+	s.mu.Unlock()
+
+	if upload == nil {
+		s.error(w, req, http.StatusNotFound, CodeNoSuchUpload,
+			"upload does not exist")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// upload returns the multipart upload with given identifier in the bucket with
+// given name or nil, if the upload does not exist or was initiated for another
+// object key.
+func (s *Storage) upload(name, key, id string) *upload {
+	if b := s.bucket(name, false); b != nil {
+		if upload, ok := b.uploads[id]; ok && upload.key == key {
+			return upload
+		}
+	}
+	return nil
+}
+
+// notAllowed writes an error response for a not supported request method.
+func (s *Storage) notAllowed(w http.ResponseWriter, req *http.Request) {
+	s.error(w, req, http.StatusMethodNotAllowed, CodeMethodNotAllowed,
+		"method not allowed")
+}
+
+// error writes a S3 XML error response with given status, code, and message.
+func (s *Storage) error(
+	w http.ResponseWriter, req *http.Request,
+	status int, code, message string,
+) {
+	if req.Method == http.MethodHead {
+		w.WriteHeader(status)
+		return
+	}
+	s.xml(w, status, Error{
+		Code: code, Message: message, Resource: req.URL.Path,
+	})
+}
+
+// xml writes the given XML response with given status.
+func (*Storage) xml(w http.ResponseWriter, status int, body any) {
+	data, _ := xml.Marshal(body)
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(xml.Header))
+	_, _ = w.Write(data)
+}
+
+// preconditions evaluates the conditional request headers for the given
+// current object and returns the failure status and error code, or zero, if
+// all preconditions are met. For writes, only entity tags are evaluated.
+func preconditions(
+	req *http.Request, current *object, write bool,
+) (int, string) {
+	if match := req.Header.Get("If-Match"); match != "" {
+		if current == nil && write {
+			return http.StatusNotFound, CodeNoSuchKey
+		} else if !matchETag(match, current) {
+			return http.StatusPreconditionFailed, CodePreconditionFailed
+		}
+	} else if since := req.Header.Get("If-Unmodified-Since"); !write &&
+		since != "" {
+		if at, err := http.ParseTime(since); err == nil &&
+			current.modified.After(at) {
+			return http.StatusPreconditionFailed, CodePreconditionFailed
+		}
+	}
+
+	if match := req.Header.Get("If-None-Match"); match != "" {
+		if matchETag(match, current) && write {
+			return http.StatusPreconditionFailed, CodePreconditionFailed
+		} else if matchETag(match, current) {
+			return http.StatusNotModified, CodeNotModified
+		}
+	} else if since := req.Header.Get("If-Modified-Since"); !write &&
+		since != "" {
+		if at, err := http.ParseTime(since); err == nil &&
+			!current.modified.After(at) {
+			return http.StatusNotModified, CodeNotModified
+		}
+	}
+	return 0, ""
+}
+
+// matchETag returns whether the given entity tag list matches the entity tag
+// of the given object. The wildcard `*` matches any existing object.
+func matchETag(list string, obj *object) bool {
+	if obj == nil {
+		return false
+	}
+	for _, etag := range strings.Split(list, ",") {
+		if etag = strings.TrimSpace(etag); etag == "*" || etag == obj.etag {
+			return true
+		}
+	}
+	return false
+}
+
+// objectHeader returns the content type and user metadata headers of the
+// given request header to be stored with an object.
+func objectHeader(header http.Header) http.Header {
+	result := http.Header{}
+	for key, values := range header {
+		if key == "Content-Type" ||
+			strings.HasPrefix(strings.ToLower(key), "x-amz-meta-") {
+			result[key] = append([]string{}, values...)
+		}
+	}
+	return result
+}
@@ -0,0 +1,621 @@
+package s3fake_test
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tkrop/go-testing/s3fake"
+	"github.com/tkrop/go-testing/test"
+)
+
+const (
+	// etagHello is the entity tag of `hello`.
+	etagHello = `"5d41402abc4b2a76b9719d911017c592"`
+	// etagWorld is the entity tag of `world`.
+	etagWorld = `"7d793037a0760186574b0282f2f435e7"`
+	// etagMultipart is the entity tag of the multipart upload of `hello`
+	// and `world`.
+	etagMultipart = `"065947336a2f2a95ba8899f3675c3be6-2"`
+)
+
+// request is a request to the object storage with the expected response.
+type request struct {
+	method       string
+	path         string
+	header       http.Header
+	body         string
+	expectStatus int
+	expectHeader http.Header
+	expectBody   any
+}
+
+// errorBody creates the expected error response body.
+func errorBody(code, message, resource string) *s3fake.Error {
+	return &s3fake.Error{Code: code, Message: message, Resource: resource}
+}
+
+// call calls the object storage with the given request and validates the
+// expected response.
+func call(t test.Test, storage *s3fake.Storage, req request) {
+	// When
+	request, err := http.NewRequest(req.method, storage.URL()+req.path,
+		strings.NewReader(req.body))
+	require.NoError(t, err)
+	for key, values := range req.header {
+		request.Header[key] = values
+	}
+	response, err := storage.Client().Do(request)
+	require.NoError(t, err)
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+
+	// Then
+	assert.Equal(t, req.expectStatus, response.StatusCode,
+		"%s %s: %s", req.method, req.path, body)
+	for key := range req.expectHeader {
+		assert.Equal(t, req.expectHeader.Get(key), response.Header.Get(key),
+			"%s %s: header %s", req.method, req.path, key)
+	}
+	switch expect := req.expectBody.(type) {
+	case nil:
+	case string:
+		assert.Equal(t, expect, string(body), "%s %s", req.method, req.path)
+	case *s3fake.Error:
+		actual := &s3fake.Error{}
+		require.NoError(t, xml.Unmarshal(body, actual))
+		actual.XMLName = xml.Name{}
+		assert.Equal(t, expect, actual, "%s %s", req.method, req.path)
+	case *s3fake.ListBucketResult:
+		actual := &s3fake.ListBucketResult{}
+		require.NoError(t, xml.Unmarshal(body, actual))
+		actual.XMLName, actual.Xmlns = xml.Name{}, ""
+		for index := range actual.Contents {
+			_, err := time.Parse(time.RFC3339,
+				actual.Contents[index].LastModified)
+			assert.NoError(t, err)
+			actual.Contents[index].LastModified = ""
+		}
+		assert.Equal(t, expect, actual, "%s %s", req.method, req.path)
+	case *s3fake.ListAllMyBucketsResult:
+		actual := &s3fake.ListAllMyBucketsResult{}
+		require.NoError(t, xml.Unmarshal(body, actual))
+		actual.XMLName, actual.Xmlns = xml.Name{}, ""
+		for index := range actual.Buckets {
+			actual.Buckets[index].CreationDate = ""
+		}
+		assert.Equal(t, expect, actual, "%s %s", req.method, req.path)
+	case *s3fake.InitiateMultipartUploadResult:
+		actual := &s3fake.InitiateMultipartUploadResult{}
+		require.NoError(t, xml.Unmarshal(body, actual))
+		actual.XMLName, actual.Xmlns = xml.Name{}, ""
+		assert.Equal(t, expect, actual, "%s %s", req.method, req.path)
+	case *s3fake.CompleteMultipartUploadResult:
+		actual := &s3fake.CompleteMultipartUploadResult{}
+		require.NoError(t, xml.Unmarshal(body, actual))
+		actual.XMLName, actual.Xmlns = xml.Name{}, ""
+		assert.Equal(t, expect, actual, "%s %s", req.method, req.path)
+	default:
+		assert.Fail(t, "unsupported body type %T", expect)
+	}
+}
+
+type HandlerParams struct {
+	setup         func(storage *s3fake.Storage)
+	requests      []request
+	expectObjects map[string]string
+}
+
+var testHandlerParams = map[string]HandlerParams{
+	"create and list buckets": {
+		requests: []request{{
+			method: http.MethodPut, path: "/b",
+			expectStatus: http.StatusOK,
+			expectHeader: http.Header{"Location": {"/b"}},
+		}, {
+			method: http.MethodPut, path: "/a",
+			expectStatus: http.StatusOK,
+		}, {
+			method: http.MethodPut, path: "/a",
+			expectStatus: http.StatusConflict,
+			expectBody: errorBody(s3fake.CodeBucketExists,
+				"bucket already exists", "/a"),
+		}, {
+			method: http.MethodHead, path: "/a",
+			expectStatus: http.StatusOK,
+		}, {
+			method: http.MethodHead, path: "/c",
+			expectStatus: http.StatusNotFound,
+			expectBody:   "",
+		}, {
+			method: http.MethodGet, path: "/",
+			expectStatus: http.StatusOK,
+			expectHeader: http.Header{"Content-Type": {"application/xml"}},
+			expectBody: &s3fake.ListAllMyBucketsResult{
+				Buckets: []s3fake.BucketInfo{{Name: "a"}, {Name: "b"}},
+			},
+		}},
+	},
+	"delete buckets": {
+		setup: func(storage *s3fake.Storage) {
+			storage.CreateBucket("empty").Put("full", "key", []byte("hello"))
+		},
+		requests: []request{{
+			method: http.MethodDelete, path: "/empty",
+			expectStatus: http.StatusNoContent,
+		}, {
+			method: http.MethodDelete, path: "/full",
+			expectStatus: http.StatusConflict,
+			expectBody: errorBody(s3fake.CodeBucketNotEmpty,
+				"bucket not empty", "/full"),
+		}, {
+			method: http.MethodDelete, path: "/empty",
+			expectStatus: http.StatusNotFound,
+			expectBody: errorBody(s3fake.CodeNoSuchBucket,
+				"bucket does not exist", "/empty"),
+		}},
+		expectObjects: map[string]string{"full/key": "hello"},
+	},
+	"put, get, and delete object": {
+		setup: func(storage *s3fake.Storage) {
+			storage.CreateBucket("bucket")
+		},
+		requests: []request{{
+			method: http.MethodPut, path: "/bucket/dir/key",
+			header: http.Header{
+				"Content-Type":    {"text/plain"},
+				"X-Amz-Meta-Name": {"value"},
+			},
+			body:         "hello",
+			expectStatus: http.StatusOK,
+			expectHeader: http.Header{"Etag": {etagHello}},
+		}, {
+			method: http.MethodGet, path: "/bucket/dir/key",
+			expectStatus: http.StatusOK,
+			expectHeader: http.Header{
+				"Etag":            {etagHello},
+				"Content-Type":    {"text/plain"},
+				"Content-Length":  {"5"},
+				"X-Amz-Meta-Name": {"value"},
+			},
+			expectBody: "hello",
+		}, {
+			method: http.MethodHead, path: "/bucket/dir/key",
+			expectStatus: http.StatusOK,
+			expectHeader: http.Header{"Content-Length": {"5"}},
+			expectBody:   "",
+		}, {
+			method: http.MethodDelete, path: "/bucket/dir/key",
+			expectStatus: http.StatusNoContent,
+		}, {
+			method: http.MethodDelete, path: "/bucket/dir/key",
+			expectStatus: http.StatusNoContent,
+		}, {
+			method: http.MethodGet, path: "/bucket/dir/key",
+			expectStatus: http.StatusNotFound,
+			expectBody: errorBody(s3fake.CodeNoSuchKey,
+				"key does not exist", "/bucket/dir/key"),
+		}},
+	},
+	"missing bucket": {
+		requests: []request{{
+			method: http.MethodPut, path: "/bucket/key",
+			body:         "hello",
+			expectStatus: http.StatusNotFound,
+			expectBody: errorBody(s3fake.CodeNoSuchBucket,
+				"bucket does not exist", "/bucket/key"),
+		}, {
+			method: http.MethodGet, path: "/bucket/key",
+			expectStatus: http.StatusNotFound,
+			expectBody: errorBody(s3fake.CodeNoSuchBucket,
+				"bucket does not exist", "/bucket/key"),
+		}, {
+			method: http.MethodDelete, path: "/bucket/key",
+			expectStatus: http.StatusNotFound,
+			expectBody: errorBody(s3fake.CodeNoSuchBucket,
+				"bucket does not exist", "/bucket/key"),
+		}, {
+			method: http.MethodGet, path: "/bucket",
+			expectStatus: http.StatusNotFound,
+			expectBody: errorBody(s3fake.CodeNoSuchBucket,
+				"bucket does not exist", "/bucket"),
+		}, {
+			method: http.MethodPost, path: "/bucket/key?uploads",
+			expectStatus: http.StatusNotFound,
+			expectBody: errorBody(s3fake.CodeNoSuchBucket,
+				"bucket does not exist", "/bucket/key"),
+		}},
+	},
+	"methods not allowed": {
+		requests: []request{{
+			method: http.MethodPost, path: "/",
+			expectStatus: http.StatusMethodNotAllowed,
+			expectBody: errorBody(s3fake.CodeMethodNotAllowed,
+				"method not allowed", "/"),
+		}, {
+			method: http.MethodPost, path: "/bucket",
+			expectStatus: http.StatusMethodNotAllowed,
+			expectBody: errorBody(s3fake.CodeMethodNotAllowed,
+				"method not allowed", "/bucket"),
+		}, {
+			method: http.MethodPatch, path: "/bucket/key",
+			expectStatus: http.StatusMethodNotAllowed,
+			expectBody: errorBody(s3fake.CodeMethodNotAllowed,
+				"method not allowed", "/bucket/key"),
+		}},
+	},
+	"list objects": {
+		setup: func(storage *s3fake.Storage) {
+			storage.Put("bucket", "a/1", []byte("hello")).
+				Put("bucket", "a/2", []byte("hello")).
+				Put("bucket", "b", []byte("world")).
+				Put("bucket", "c/1", []byte("world"))
+		},
+		requests: []request{{
+			method: http.MethodGet, path: "/bucket?list-type=2",
+			expectStatus: http.StatusOK,
+			expectBody: &s3fake.ListBucketResult{
+				Name: "bucket", MaxKeys: 1000, KeyCount: 4,
+				Contents: []s3fake.ObjectInfo{
+					{Key: "a/1", ETag: etagHello, Size: 5, StorageClass: "STANDARD"},
+					{Key: "a/2", ETag: etagHello, Size: 5, StorageClass: "STANDARD"},
+					{Key: "b", ETag: etagWorld, Size: 5, StorageClass: "STANDARD"},
+					{Key: "c/1", ETag: etagWorld, Size: 5, StorageClass: "STANDARD"},
+				},
+			},
+		}, {
+			method: http.MethodGet, path: "/bucket?list-type=2&delimiter=/",
+			expectStatus: http.StatusOK,
+			expectBody: &s3fake.ListBucketResult{
+				Name: "bucket", Delimiter: "/", MaxKeys: 1000, KeyCount: 3,
+				Contents: []s3fake.ObjectInfo{
+					{Key: "b", ETag: etagWorld, Size: 5, StorageClass: "STANDARD"},
+				},
+				CommonPrefixes: []s3fake.CommonPrefix{
+					{Prefix: "a/"}, {Prefix: "c/"},
+				},
+			},
+		}, {
+			method:       http.MethodGet,
+			path:         "/bucket?list-type=2&delimiter=/&max-keys=1",
+			expectStatus: http.StatusOK,
+			expectBody: &s3fake.ListBucketResult{
+				Name: "bucket", Delimiter: "/", MaxKeys: 1, KeyCount: 1,
+				IsTruncated: true, NextContinuationToken: "a/2",
+				CommonPrefixes: []s3fake.CommonPrefix{{Prefix: "a/"}},
+			},
+		}, {
+			method: http.MethodGet,
+			path: "/bucket?list-type=2&delimiter=/&max-keys=1" +
+				"&continuation-token=a/2",
+			expectStatus: http.StatusOK,
+			expectBody: &s3fake.ListBucketResult{
+				Name: "bucket", Delimiter: "/", MaxKeys: 1, KeyCount: 1,
+				ContinuationToken: "a/2", IsTruncated: true,
+				NextContinuationToken: "b",
+				Contents: []s3fake.ObjectInfo{
+					{Key: "b", ETag: etagWorld, Size: 5, StorageClass: "STANDARD"},
+				},
+			},
+		}, {
+			method:       http.MethodGet,
+			path:         "/bucket?list-type=2&prefix=a/&start-after=a/1",
+			expectStatus: http.StatusOK,
+			expectBody: &s3fake.ListBucketResult{
+				Name: "bucket", Prefix: "a/", MaxKeys: 1000, KeyCount: 1,
+				StartAfter: "a/1",
+				Contents: []s3fake.ObjectInfo{
+					{Key: "a/2", ETag: etagHello, Size: 5, StorageClass: "STANDARD"},
+				},
+			},
+		}, {
+			method: http.MethodGet, path: "/bucket?list-type=2&max-keys=x",
+			expectStatus: http.StatusBadRequest,
+			expectBody: errorBody(s3fake.CodeInvalidArgument,
+				"invalid max-keys", "/bucket"),
+		}},
+	},
+	"conditional get": {
+		setup: func(storage *s3fake.Storage) {
+			storage.Put("bucket", "key", []byte("hello"))
+		},
+		requests: []request{{
+			method: http.MethodGet, path: "/bucket/key",
+			header:       http.Header{"If-Match": {`"other", ` + etagHello}},
+			expectStatus: http.StatusOK,
+			expectBody:   "hello",
+		}, {
+			method: http.MethodGet, path: "/bucket/key",
+			header:       http.Header{"If-Match": {etagWorld}},
+			expectStatus: http.StatusPreconditionFailed,
+			expectBody: errorBody(s3fake.CodePreconditionFailed,
+				"precondition failed", "/bucket/key"),
+		}, {
+			method: http.MethodGet, path: "/bucket/key",
+			header:       http.Header{"If-None-Match": {etagHello}},
+			expectStatus: http.StatusNotModified,
+			expectHeader: http.Header{"Etag": {etagHello}},
+			expectBody:   "",
+		}, {
+			method: http.MethodGet, path: "/bucket/key",
+			header:       http.Header{"If-None-Match": {etagWorld}},
+			expectStatus: http.StatusOK,
+			expectBody:   "hello",
+		}, {
+			method: http.MethodGet, path: "/bucket/key",
+			header: http.Header{"If-Modified-Since": {
+				time.Now().Add(time.Hour).UTC().Format(http.TimeFormat),
+			}},
+			expectStatus: http.StatusNotModified,
+		}, {
+			method: http.MethodGet, path: "/bucket/key",
+			header: http.Header{"If-Modified-Since": {
+				time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat),
+			}},
+			expectStatus: http.StatusOK,
+		}, {
+			method: http.MethodGet, path: "/bucket/key",
+			header: http.Header{"If-Unmodified-Since": {
+				time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat),
+			}},
+			expectStatus: http.StatusPreconditionFailed,
+		}, {
+			method: http.MethodHead, path: "/bucket/key",
+			header:       http.Header{"If-Match": {etagWorld}},
+			expectStatus: http.StatusPreconditionFailed,
+			expectBody:   "",
+		}},
+	},
+	"conditional put": {
+		setup: func(storage *s3fake.Storage) {
+			storage.Put("bucket", "key", []byte("hello"))
+		},
+		requests: []request{{
+			method: http.MethodPut, path: "/bucket/key",
+			header:       http.Header{"If-None-Match": {"*"}},
+			body:         "world",
+			expectStatus: http.StatusPreconditionFailed,
+			expectBody: errorBody(s3fake.CodePreconditionFailed,
+				"precondition failed", "/bucket/key"),
+		}, {
+			method: http.MethodPut, path: "/bucket/other",
+			header:       http.Header{"If-None-Match": {"*"}},
+			body:         "world",
+			expectStatus: http.StatusOK,
+		}, {
+			method: http.MethodPut, path: "/bucket/key",
+			header:       http.Header{"If-Match": {etagWorld}},
+			body:         "world",
+			expectStatus: http.StatusPreconditionFailed,
+		}, {
+			method: http.MethodPut, path: "/bucket/missing",
+			header:       http.Header{"If-Match": {etagHello}},
+			body:         "world",
+			expectStatus: http.StatusNotFound,
+			expectBody: errorBody(s3fake.CodeNoSuchKey,
+				"precondition failed", "/bucket/missing"),
+		}, {
+			method: http.MethodPut, path: "/bucket/key",
+			header:       http.Header{"If-Match": {etagHello}},
+			body:         "world",
+			expectStatus: http.StatusOK,
+			expectHeader: http.Header{"Etag": {etagWorld}},
+		}},
+		expectObjects: map[string]string{
+			"bucket/key": "world", "bucket/other": "world",
+		},
+	},
+	"multipart upload": {
+		setup: func(storage *s3fake.Storage) {
+			storage.CreateBucket("bucket")
+		},
+		requests: []request{{
+			method: http.MethodPost, path: "/bucket/key?uploads",
+			header:       http.Header{"Content-Type": {"text/plain"}},
+			expectStatus: http.StatusOK,
+			expectBody: &s3fake.InitiateMultipartUploadResult{
+				Bucket: "bucket", Key: "key", UploadID: "upload-1",
+			},
+		}, {
+			method:       http.MethodPut,
+			path:         "/bucket/key?partNumber=2&uploadId=upload-1",
+			body:         "world",
+			expectStatus: http.StatusOK,
+			expectHeader: http.Header{"Etag": {etagWorld}},
+		}, {
+			method:       http.MethodPut,
+			path:         "/bucket/key?partNumber=1&uploadId=upload-1",
+			body:         "hello",
+			expectStatus: http.StatusOK,
+			expectHeader: http.Header{"Etag": {etagHello}},
+		}, {
+			method:       http.MethodPut,
+			path:         "/bucket/key?partNumber=0&uploadId=upload-1",
+			expectStatus: http.StatusBadRequest,
+			expectBody: errorBody(s3fake.CodeInvalidArgument,
+				"invalid part number", "/bucket/key"),
+		}, {
+			method:       http.MethodPut,
+			path:         "/bucket/key?partNumber=1&uploadId=upload-2",
+			expectStatus: http.StatusNotFound,
+			expectBody: errorBody(s3fake.CodeNoSuchUpload,
+				"upload does not exist", "/bucket/key"),
+		}, {
+			method: http.MethodPost, path: "/bucket/key?uploadId=upload-1",
+			body:         "<CompleteMultipartUpload>",
+			expectStatus: http.StatusBadRequest,
+			expectBody: errorBody(s3fake.CodeMalformedXML,
+				"invalid complete multipart upload request", "/bucket/key"),
+		}, {
+			method: http.MethodPost, path: "/bucket/key?uploadId=upload-1",
+			body: "<CompleteMultipartUpload>" +
+				"<Part><PartNumber>2</PartNumber></Part>" +
+				"<Part><PartNumber>1</PartNumber></Part>" +
+				"</CompleteMultipartUpload>",
+			expectStatus: http.StatusBadRequest,
+			expectBody: errorBody(s3fake.CodeInvalidPartOrder,
+				"parts not in ascending order", "/bucket/key"),
+		}, {
+			method: http.MethodPost, path: "/bucket/key?uploadId=upload-1",
+			body: "<CompleteMultipartUpload>" +
+				"<Part><PartNumber>1</PartNumber><ETag>" + etagWorld +
+				"</ETag></Part></CompleteMultipartUpload>",
+			expectStatus: http.StatusBadRequest,
+			expectBody: errorBody(s3fake.CodeInvalidPart,
+				"invalid part [number=1]", "/bucket/key"),
+		}, {
+			method: http.MethodPost, path: "/bucket/key?uploadId=upload-2",
+			body: "<CompleteMultipartUpload>" +
+				"<Part><PartNumber>1</PartNumber></Part>" +
+				"</CompleteMultipartUpload>",
+			expectStatus: http.StatusNotFound,
+			expectBody: errorBody(s3fake.CodeNoSuchUpload,
+				"upload does not exist", "/bucket/key"),
+		}, {
+			method: http.MethodPost, path: "/bucket/key?uploadId=upload-1",
+			body: "<CompleteMultipartUpload>" +
+				"<Part><PartNumber>1</PartNumber><ETag>" + etagHello +
+				"</ETag></Part>" +
+				"<Part><PartNumber>2</PartNumber><ETag>" + etagWorld +
+				"</ETag></Part></CompleteMultipartUpload>",
+			expectStatus: http.StatusOK,
+			expectBody: &s3fake.CompleteMultipartUploadResult{
+				Location: "/bucket/key", Bucket: "bucket", Key: "key",
+				ETag: etagMultipart,
+			},
+		}, {
+			method: http.MethodGet, path: "/bucket/key",
+			expectStatus: http.StatusOK,
+			expectHeader: http.Header{
+				"Etag":         {etagMultipart},
+				"Content-Type": {"text/plain"},
+			},
+			expectBody: "helloworld",
+		}},
+		expectObjects: map[string]string{"bucket/key": "helloworld"},
+	},
+	"multipart upload other key": {
+		setup: func(storage *s3fake.Storage) {
+			storage.CreateBucket("bucket")
+		},
+		requests: []request{{
+			method: http.MethodPost, path: "/bucket/key?uploads",
+			expectStatus: http.StatusOK,
+		}, {
+			method:       http.MethodPut,
+			path:         "/bucket/other?partNumber=1&uploadId=upload-1",
+			body:         "hello",
+			expectStatus: http.StatusNotFound,
+			expectBody: errorBody(s3fake.CodeNoSuchUpload,
+				"upload does not exist", "/bucket/other"),
+		}, {
+			method:       http.MethodPut,
+			path:         "/bucket/key?partNumber=1&uploadId=upload-1",
+			body:         "hello",
+			expectStatus: http.StatusOK,
+			expectHeader: http.Header{"Etag": {etagHello}},
+		}, {
+			method: http.MethodPost, path: "/bucket/other?uploadId=upload-1",
+			body: "<CompleteMultipartUpload>" +
+				"<Part><PartNumber>1</PartNumber></Part>" +
+				"</CompleteMultipartUpload>",
+			expectStatus: http.StatusNotFound,
+			expectBody: errorBody(s3fake.CodeNoSuchUpload,
+				"upload does not exist", "/bucket/other"),
+		}, {
+			method: http.MethodDelete, path: "/bucket/other?uploadId=upload-1",
+			expectStatus: http.StatusNotFound,
+			expectBody: errorBody(s3fake.CodeNoSuchUpload,
+				"upload does not exist", "/bucket/other"),
+		}, {
+			method: http.MethodDelete, path: "/bucket/key?uploadId=upload-1",
+			expectStatus: http.StatusNoContent,
+		}},
+	},
+	"abort multipart upload": {
+		setup: func(storage *s3fake.Storage) {
+			storage.CreateBucket("bucket")
+		},
+		requests: []request{{
+			method: http.MethodPost, path: "/bucket/key?uploads",
+			expectStatus: http.StatusOK,
+		}, {
+			method: http.MethodDelete, path: "/bucket/key?uploadId=upload-1",
+			expectStatus: http.StatusNoContent,
+		}, {
+			method: http.MethodDelete, path: "/bucket/key?uploadId=upload-1",
+			expectStatus: http.StatusNotFound,
+			expectBody: errorBody(s3fake.CodeNoSuchUpload,
+				"upload does not exist", "/bucket/key"),
+		}},
+	},
+	"injected faults": {
+		setup: func(storage *s3fake.Storage) {
+			storage.Put("bucket", "key", []byte("hello")).
+				Fail(s3fake.Fault{
+					Method: http.MethodGet, Path: "/bucket/key",
+					Status: http.StatusServiceUnavailable,
+					Code:   s3fake.CodeSlowDown, Times: 2,
+				}).
+				Fail(s3fake.Fault{
+					Path:   "/other",
+					Status: http.StatusForbidden,
+					Code:   s3fake.CodeAccessDenied,
+				})
+		},
+		requests: []request{{
+			method: http.MethodGet, path: "/bucket/key",
+			expectStatus: http.StatusServiceUnavailable,
+			expectBody: errorBody(s3fake.CodeSlowDown,
+				"injected fault", "/bucket/key"),
+		}, {
+			method: http.MethodHead, path: "/bucket/key",
+			expectStatus: http.StatusOK,
+		}, {
+			method: http.MethodGet, path: "/bucket/key",
+			expectStatus: http.StatusServiceUnavailable,
+		}, {
+			method: http.MethodGet, path: "/bucket/key",
+			expectStatus: http.StatusOK,
+			expectBody:   "hello",
+		}, {
+			method: http.MethodPut, path: "/other",
+			expectStatus: http.StatusForbidden,
+		}, {
+			method: http.MethodPut, path: "/other",
+			expectStatus: http.StatusForbidden,
+			expectBody: errorBody(s3fake.CodeAccessDenied,
+				"injected fault", "/other"),
+		}},
+		expectObjects: map[string]string{"bucket/key": "hello"},
+	},
+}
+
+func TestHandler(t *testing.T) {
+	test.Map(t, testHandlerParams).
+		Run(func(t test.Test, param HandlerParams) {
+			// Given
+			storage := s3fake.New(t)
+			if param.setup != nil {
+				param.setup(storage)
+			}
+
+			// When
+			for _, req := range param.requests {
+				call(t, storage, req)
+			}
+
+			// Then
+			for path, data := range param.expectObjects {
+				name, key, _ := strings.Cut(path, "/")
+				storage.AssertObject(name, key, []byte(data))
+			}
+		})
+}
@@ -0,0 +1,302 @@
+// Package s3fake contains a small in-process S3-compatible object storage
+// stand-in supporting buckets, put, get, list, and delete of objects,
+// multipart uploads, and conditional requests with per-test state, fault
+// injection, and assertions on the stored objects. It is part of the public
+// interface, however, we are still experimenting to optimize the interface and
+// the user experience.
+package s3fake
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	gosync "sync"
+	"time"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/tkrop/go-testing/test"
+)
+
+// Fault is a fault injected into the requests of the object storage.
+type Fault struct {
+	// Method the HTTP method of the failing requests. An empty method matches
+	// all methods.
+	Method string
+	// Path the path prefix, i.e. `/bucket/key`, of the failing requests. An
+	// empty path matches all paths.
+	Path string
+	// Status the HTTP status code of the failure response.
+	Status int
+	// Code the S3 error code of the failure response.
+	Code string
+	// Times the number of failing requests. Zero fails all matching requests.
+	Times int
+}
+
+// matches returns whether the given request matches the fault.
+func (f *Fault) matches(req *http.Request) bool {
+	return (f.Method == "" || f.Method == req.Method) &&
+		strings.HasPrefix(req.URL.Path, f.Path)
+}
+
+// Storage is an in-process S3-compatible object storage stand-in with
+// per-test state served by a local HTTP test server.
+type Storage struct {
+	// t the attached test context.
+	t test.Test
+	// mu the mutex to protect the storage state.
+	mu gosync.Mutex
+	// buckets the buckets by name.
+	buckets map[string]*bucket
+	// faults the injected faults.
+	faults []*Fault
+	// uploads the number of initiated multipart uploads.
+	uploads int
+	// server the local HTTP test server.
+	server *httptest.Server
+}
+
+// bucket is a bucket of the object storage.
+type bucket struct {
+	// objects the objects by key.
+	objects map[string]*object
+	// uploads the pending multipart uploads by upload identifier.
+	uploads map[string]*upload
+	// created the creation time of the bucket.
+	created time.Time
+}
+
+// object is an object stored in a bucket.
+type object struct {
+	// data the content of the object.
+	data []byte
+	// etag the entity tag of the object.
+	etag string
+	// modified the last modification time of the object.
+	modified time.Time
+	// header the content type and user metadata of the object.
+	header http.Header
+}
+
+// upload is a pending multipart upload.
+type upload struct {
+	// key the key of the object uploaded.
+	key string
+	// parts the uploaded parts by part number.
+	parts map[int]*object
+	// header the content type and user metadata of the object.
+	header http.Header
+}
+
+// New creates a new object storage stand-in for the given test and starts the
+// local HTTP test server, that is closed on test cleanup.
+func New(t test.Test) *Storage {
+	s := &Storage{t: t, buckets: map[string]*bucket{}}
+	s.server = httptest.NewServer(s)
+	if c, ok := t.(test.Cleanuper); ok {
+		c.Cleanup(s.server.Close)
+	}
+	return s
+}
+
+// NewStorage creates a new object storage stand-in for the test of the given
+// mock controller, e.g. to be requested via `mock.Get(mocks, s3fake.NewStorage)`.
+func NewStorage(ctrl *gomock.Controller) *Storage {
+	t, ok := ctrl.T.(test.Test)
+	if !ok {
+		panic(ErrNoTest(ctrl.T))
+	}
+	return New(t)
+}
+
+// URL returns the endpoint URL of the object storage for path-style requests.
+func (s *Storage) URL() string {
+	return s.server.URL
+}
+
+// Client returns a HTTP client configured to access the object storage.
+func (s *Storage) Client() *http.Client {
+	return s.server.Client()
+}
+
+// Fail injects the given fault into the requests of the object storage.
+func (s *Storage) Fail(fault Fault) *Storage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &fault)
+	return s
+}
+
+// CreateBucket creates the buckets with given names, if they do not exist.
+func (s *Storage) CreateBucket(names ...string) *Storage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, name := range names {
+		s.bucket(name, true)
+	}
+	return s
+}
+
+// Put stores the given data as object with given key in the bucket with given
+// name, creating the bucket if necessary.
+func (s *Storage) Put(name, key string, data []byte) *Storage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bucket(name, true).objects[key] = newObject(data, http.Header{})
+	return s
+}
+
+// Object returns the content of the object with given key in the bucket with
+// given name and whether the object exists.
+func (s *Storage) Object(name, key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if b := s.bucket(name, false); b != nil {
+		if obj, ok := b.objects[key]; ok {
+			return append([]byte{}, obj.data...), true
+		}
+	}
+	return nil, false
+}
+
+// Keys returns the sorted keys of the objects in the bucket with given name.
+func (s *Storage) Keys(name string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.keys(s.bucket(name, false))
+}
+
+// AssertObject asserts that the object with given key in the bucket with
+// given name exists with the given content.
+func (s *Storage) AssertObject(name, key string, data []byte) bool {
+	s.t.Helper()
+	if actual, ok := s.Object(name, key); !ok {
+		s.t.Errorf("%v", ErrObjectMissing(name, key))
+		return false
+	} else if !bytes.Equal(actual, data) {
+		s.t.Errorf("%v", ErrObjectContent(name, key, data, actual))
+		return false
+	}
+	return true
+}
+
+// AssertNoObject asserts that the object with given key in the bucket with
+// given name does not exist.
+func (s *Storage) AssertNoObject(name, key string) bool {
+	s.t.Helper()
+	if _, ok := s.Object(name, key); ok {
+		s.t.Errorf("%v", ErrObjectExists(name, key))
+		return false
+	}
+	return true
+}
+
+// AssertNoUploads asserts that no multipart uploads are pending, i.e. that
+// all multipart uploads were either completed or aborted.
+func (s *Storage) AssertNoUploads() bool {
+	s.t.Helper()
+	s.mu.Lock()
+	pending := []string{}
+	for name, b := range s.buckets {
+		for _, upload := range b.uploads {
+			pending = append(pending, name+"/"+upload.key)
+		}
+	}
+	s.mu.Unlock()
+
+	if len(pending) != 0 {
+		sort.Strings(pending)
+		s.t.Errorf("%v", ErrUploadsPending(pending))
+		return false
+	}
+	return true
+}
+
+// fault returns the first fault matching the given request and consumes it.
+func (s *Storage) fault(req *http.Request) *Fault {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for index, fault := range s.faults {
+		if fault.matches(req) {
+			if fault.Times == 1 {
+				s.faults = append(s.faults[:index], s.faults[index+1:]...)
+			} else if fault.Times > 1 {
+				fault.Times--
+			}
+			return fault
+		}
+	}
+	return nil
+}
+
+// bucket returns the bucket with given name creating it, if requested.
+// Otherwise, nil is returned for a missing bucket.
+func (s *Storage) bucket(name string, create bool) *bucket {
+	b, ok := s.buckets[name]
+	if !ok && create {
+		b = &bucket{
+			objects: map[string]*object{}, uploads: map[string]*upload{},
+			created: time.Now().UTC().Truncate(time.Second),
+		}
+		s.buckets[name] = b
+	}
+	return b
+}
+
+// keys returns the sorted keys of the objects in the given bucket.
+func (*Storage) keys(b *bucket) []string {
+	keys := []string{}
+	if b != nil {
+		for key := range b.objects {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// newObject creates a new object with given data and header.
+func newObject(data []byte, header http.Header) *object {
+	sum := md5.Sum(data)
+	return &object{
+		data: data, header: header,
+		etag:     `"` + hex.EncodeToString(sum[:]) + `"`,
+		modified: time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+// ErrNoTest creates an error that the given test reporter of a mock controller
+// does not support the test interface required for the object storage.
+func ErrNoTest(reporter any) error {
+	return fmt.Errorf("storage not supported by test setup [type=%T]",
+		reporter)
+}
+
+// ErrObjectMissing creates an error reporting a missing object.
+func ErrObjectMissing(name, key string) error {
+	return fmt.Errorf("object missing [bucket=%s, key=%s]", name, key)
+}
+
+// ErrObjectExists creates an error reporting an unexpected existing object.
+func ErrObjectExists(name, key string) error {
+	return fmt.Errorf("object exists [bucket=%s, key=%s]", name, key)
+}
+
+// ErrObjectContent creates an error reporting an object with unexpected
+// content.
+func ErrObjectContent(name, key string, expect, actual []byte) error {
+	return fmt.Errorf("object content differs [bucket=%s, key=%s]: "+
+		"expected %q, actual %q", name, key, expect, actual)
+}
+
+// ErrUploadsPending creates an error reporting pending multipart uploads.
+func ErrUploadsPending(uploads []string) error {
+	return fmt.Errorf("multipart uploads pending [uploads=%s]",
+		strings.Join(uploads, ", "))
+}
@@ -0,0 +1,173 @@
+package s3fake_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tkrop/go-testing/mock"
+	"github.com/tkrop/go-testing/s3fake"
+	"github.com/tkrop/go-testing/test"
+)
+
+type StorageParams struct {
+	setup        func(storage *s3fake.Storage)
+	call         func(storage *s3fake.Storage) bool
+	expectResult bool
+	expectError  error
+	expect       test.Expect
+}
+
+var testStorageParams = map[string]StorageParams{
+	"assert object": {
+		setup: func(storage *s3fake.Storage) {
+			storage.Put("bucket", "key", []byte("hello"))
+		},
+		call: func(storage *s3fake.Storage) bool {
+			return storage.AssertObject("bucket", "key", []byte("hello"))
+		},
+		expectResult: true,
+		expect:       test.Success,
+	},
+	"assert object missing bucket": {
+		call: func(storage *s3fake.Storage) bool {
+			return storage.AssertObject("bucket", "key", []byte("hello"))
+		},
+		expectError: s3fake.ErrObjectMissing("bucket", "key"),
+		expect:      test.Failure,
+	},
+	"assert object missing key": {
+		setup: func(storage *s3fake.Storage) {
+			storage.CreateBucket("bucket")
+		},
+		call: func(storage *s3fake.Storage) bool {
+			return storage.AssertObject("bucket", "key", []byte("hello"))
+		},
+		expectError: s3fake.ErrObjectMissing("bucket", "key"),
+		expect:      test.Failure,
+	},
+	"assert object content": {
+		setup: func(storage *s3fake.Storage) {
+			storage.Put("bucket", "key", []byte("world"))
+		},
+		call: func(storage *s3fake.Storage) bool {
+			return storage.AssertObject("bucket", "key", []byte("hello"))
+		},
+		expectError: s3fake.ErrObjectContent("bucket", "key",
+			[]byte("hello"), []byte("world")),
+		expect: test.Failure,
+	},
+	"assert no object": {
+		setup: func(storage *s3fake.Storage) {
+			storage.Put("bucket", "other", []byte("hello"))
+		},
+		call: func(storage *s3fake.Storage) bool {
+			return storage.AssertNoObject("bucket", "key")
+		},
+		expectResult: true,
+		expect:       test.Success,
+	},
+	"assert no object exists": {
+		setup: func(storage *s3fake.Storage) {
+			storage.Put("bucket", "key", []byte("hello"))
+		},
+		call: func(storage *s3fake.Storage) bool {
+			return storage.AssertNoObject("bucket", "key")
+		},
+		expectError: s3fake.ErrObjectExists("bucket", "key"),
+		expect:      test.Failure,
+	},
+	"assert no uploads": {
+		setup: func(storage *s3fake.Storage) {
+			storage.CreateBucket("bucket")
+		},
+		call: func(storage *s3fake.Storage) bool {
+			return storage.AssertNoUploads()
+		},
+		expectResult: true,
+		expect:       test.Success,
+	},
+	"assert no uploads pending": {
+		setup: func(storage *s3fake.Storage) {
+			storage.CreateBucket("a", "b")
+			initiate(storage, "/b/key")
+			initiate(storage, "/a/key")
+		},
+		call: func(storage *s3fake.Storage) bool {
+			return storage.AssertNoUploads()
+		},
+		expectError: s3fake.ErrUploadsPending([]string{"a/key", "b/key"}),
+		expect:      test.Failure,
+	},
+}
+
+// initiate initiates a multipart upload for the given object path.
+func initiate(storage *s3fake.Storage, path string) {
+	response, err := storage.Client().Post(
+		storage.URL()+path+"?uploads", "text/plain", http.NoBody)
+	if err == nil {
+		response.Body.Close()
+	}
+}
+
+func TestStorage(t *testing.T) {
+	test.Map(t, testStorageParams).
+		Run(func(t test.Test, param StorageParams) {
+			// Given
+			if param.expectError != nil {
+				mock.NewMock(t).Expect(test.Errorf("%v", param.expectError))
+			}
+			storage := s3fake.New(t)
+			if param.setup != nil {
+				param.setup(storage)
+			}
+
+			// When
+			result := param.call(storage)
+
+			// Then
+			assert.Equal(t, param.expectResult, result)
+		})
+}
+
+func TestStorageState(t *testing.T) {
+	// Given
+	mocks := mock.NewMock(t)
+	storage := mock.Get(mocks, s3fake.NewStorage)
+
+	// When
+	storage.CreateBucket("bucket", "empty").
+		Put("bucket", "b", []byte("world")).
+		Put("bucket", "a", []byte("hello"))
+
+	// Then
+	data, ok := storage.Object("bucket", "a")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("hello"), data)
+	_, ok = storage.Object("bucket", "c")
+	assert.False(t, ok)
+	_, ok = storage.Object("missing", "a")
+	assert.False(t, ok)
+	assert.Equal(t, []string{"a", "b"}, storage.Keys("bucket"))
+	assert.Equal(t, []string{}, storage.Keys("empty"))
+	assert.Equal(t, []string{}, storage.Keys("missing"))
+}
+
+func TestNewStoragePanic(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(reporter{})
+
+	// When
+	defer func() {
+		assert.Equal(t, s3fake.ErrNoTest(ctrl.T), recover())
+	}()
+	s3fake.NewStorage(ctrl)
+}
+
+// reporter is a minimal test reporter not supporting the object storage.
+type reporter struct{}
+
+func (reporter) Errorf(string, ...any) {}
+func (reporter) Fatalf(string, ...any) {}
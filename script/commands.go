@@ -0,0 +1,177 @@
+package script
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// builtins are the builtin script commands.
+var builtins = map[string]Command{
+	"cd":     cd,
+	"cmp":    cmp,
+	"env":    env,
+	"exec":   execute,
+	"exists": exists,
+	"gock":   httpMock,
+	"mkdir":  mkdir,
+	"rm":     rm,
+	"stderr": match("stderr"),
+	"stdout": match("stdout"),
+}
+
+// assertions are the builtin commands validating the output of the previous
+// command. These commands keep the captured output of the previous command.
+var assertions = map[string]bool{
+	"cmp": true, "exists": true, "stderr": true, "stdout": true,
+}
+
+// cd changes the current directory of the script.
+func cd(s *State, args ...string) error {
+	if len(args) != 1 {
+		return ErrUsage("cd", "cd dir")
+	}
+	dir := s.Path(args[0])
+	if info, err := os.Stat(dir); err != nil {
+		return err
+	} else if !info.IsDir() {
+		return ErrUsage("cd", "cd dir")
+	}
+	s.dir = dir
+	return nil
+}
+
+// cmp compares the content of the first name - a file, `stdout`, or `stderr`
+// - with the content of the second file. In update mode, a differing second
+// file that is part of the archive is updated instead of failing.
+func cmp(s *State, args ...string) error {
+	if len(args) != 2 {
+		return ErrUsage("cmp", "cmp file|stdout|stderr expect-file")
+	}
+	actual, err := s.content(args[0])
+	if err != nil {
+		return err
+	}
+	expected, err := s.content(args[1])
+	if err != nil {
+		return err
+	} else if bytes.Equal(actual, expected) {
+		return nil
+	}
+
+	if file := s.file(args[1]); s.config.update && file != nil {
+		file.Data = append([]byte{}, actual...)
+		s.updated = true
+		return os.WriteFile(s.Path(args[1]), actual, 0o644)
+	}
+	return ErrCompare(args[0], args[1], actual, expected)
+}
+
+// env sets the given environment variables given as `KEY=value` pairs.
+func env(s *State, args ...string) error {
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			return ErrUsage("env", "env KEY=value...")
+		}
+		s.Setenv(key, value)
+	}
+	return nil
+}
+
+// execute executes the given external program with the given arguments in
+// the current directory of the script using the script environment.
+func execute(s *State, args ...string) error {
+	if len(args) == 0 {
+		return ErrUsage("exec", "exec program [args...]")
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Dir, cmd.Env = s.dir, s.Environ()
+	cmd.Stdout, cmd.Stderr = s.Stdout(), s.Stderr()
+	return cmd.Run()
+}
+
+// exists checks whether the given files exist.
+func exists(s *State, args ...string) error {
+	if len(args) == 0 {
+		return ErrUsage("exists", "exists file...")
+	}
+	for _, arg := range args {
+		if _, err := os.Stat(s.Path(arg)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// httpMock registers a HTTP request/response mock with the gock controller
+// of the script that replies to the given method and URL with the given
+// status code and the content of the optional body file.
+func httpMock(s *State, args ...string) error {
+	if len(args) < 3 || len(args) > 4 {
+		return ErrUsage("gock", "gock method url status [body-file]")
+	}
+	status, err := strconv.Atoi(args[2])
+	if err != nil {
+		return err
+	}
+	var body []byte
+	if len(args) == 4 {
+		if body, err = os.ReadFile(s.Path(args[3])); err != nil {
+			return err
+		}
+	}
+
+	request := s.Gock().New(args[1])
+	request.Method = strings.ToUpper(args[0])
+	request.Reply(status).Body(bytes.NewReader(body))
+	return nil
+}
+
+// mkdir creates the given directories including their parents.
+func mkdir(s *State, args ...string) error {
+	if len(args) == 0 {
+		return ErrUsage("mkdir", "mkdir dir...")
+	}
+	for _, arg := range args {
+		if err := os.MkdirAll(s.Path(arg), 0o755); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rm removes the given files or directories recursively.
+func rm(s *State, args ...string) error {
+	if len(args) == 0 {
+		return ErrUsage("rm", "rm file...")
+	}
+	for _, arg := range args {
+		if err := os.RemoveAll(s.Path(arg)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// match creates a command checking whether the captured output with given
+// name of the previous command matches the given regular expression.
+func match(name string) Command {
+	return func(s *State, args ...string) error {
+		if len(args) != 1 {
+			return ErrUsage(name, name+" regexp")
+		}
+		regex, err := regexp.Compile("(?m)" + args[0])
+		if err != nil {
+			return err
+		}
+		content, _ := s.content(name)
+		if !regex.Match(content) {
+			return ErrNoMatch(name, args[0])
+		}
+		return nil
+	}
+}
@@ -0,0 +1,91 @@
+package script_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tkrop/go-testing/mock"
+	"github.com/tkrop/go-testing/script"
+	"github.com/tkrop/go-testing/test"
+)
+
+type CommandsParams struct {
+	line      string
+	expectErr error
+	expect    test.Expect
+}
+
+var testCommandsParams = map[string]CommandsParams{
+	"cd usage": {
+		line:      "cd",
+		expectErr: script.ErrUsage("cd", "cd dir"),
+	},
+	"cd file": {
+		line:      "cd file.txt",
+		expectErr: script.ErrUsage("cd", "cd dir"),
+	},
+	"cmp usage": {
+		line: "cmp stdout",
+		expectErr: script.ErrUsage("cmp",
+			"cmp file|stdout|stderr expect-file"),
+	},
+	"cmp differ": {
+		line: "cmp file.txt stdout",
+		expectErr: script.ErrCompare("file.txt", "stdout",
+			[]byte("content\n"), nil),
+	},
+	"env usage": {
+		line:      "env KEY",
+		expectErr: script.ErrUsage("env", "env KEY=value..."),
+	},
+	"exec usage": {
+		line:      "exec",
+		expectErr: script.ErrUsage("exec", "exec program [args...]"),
+	},
+	"exists usage": {
+		line:      "exists",
+		expectErr: script.ErrUsage("exists", "exists file..."),
+	},
+	"gock usage": {
+		line:      "gock get http://api.test",
+		expectErr: script.ErrUsage("gock", "gock method url status [body-file]"),
+	},
+	"mkdir usage": {
+		line:      "mkdir",
+		expectErr: script.ErrUsage("mkdir", "mkdir dir..."),
+	},
+	"rm usage": {
+		line:      "rm",
+		expectErr: script.ErrUsage("rm", "rm file..."),
+	},
+	"stdout usage": {
+		line:      "stdout",
+		expectErr: script.ErrUsage("stdout", "stdout regexp"),
+	},
+	"stdout no match": {
+		line:      "stdout missing",
+		expectErr: script.ErrNoMatch("stdout", "missing"),
+	},
+	"stderr no match": {
+		line:      "stderr missing",
+		expectErr: script.ErrNoMatch("stderr", "missing"),
+	},
+}
+
+func TestCommands(t *testing.T) {
+	test.Map(t, testCommandsParams).
+		Run(func(t test.Test, param CommandsParams) {
+			// Given
+			path := filepath.Join(tempDir(t), "test.txtar")
+			require.NoError(t, os.WriteFile(path, []byte(param.line+"\n"+
+				"-- file.txt --\ncontent\n"), 0o600))
+			mock.NewMock(t).Expect(test.Fatalf("%s:%d: %s: %v",
+				path, 1, param.line, param.expectErr))
+
+			// When
+			script.RunScript(t, path)
+		})
+}
@@ -0,0 +1,419 @@
+// Package script contains a small framework to run script-based CLI tests
+// written as `txtar` archives as isolated parameterized test cases. It is part
+// of the public interface, however, we are still experimenting to optimize the
+// interface and the user experience.
+package script
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/tkrop/go-testing/gock"
+	"github.com/tkrop/go-testing/mock"
+	"github.com/tkrop/go-testing/test"
+)
+
+// Command is a script command implemented in Go. The command receives the
+// script state and the expanded arguments. Output is written to the standard
+// output and error writers of the state. An error signals command failure.
+type Command func(s *State, args ...string) error
+
+// Option is an option to configure the script runner.
+type Option func(*config)
+
+// config is the configuration of the script runner.
+type config struct {
+	commands map[string]Command
+	setup    func(s *State)
+	update   bool
+}
+
+// Commands registers the given custom commands for the scripts. Custom
+// commands replace builtin commands with the same name.
+func Commands(commands map[string]Command) Option {
+	return func(config *config) {
+		for name, command := range commands {
+			config.commands[name] = command
+		}
+	}
+}
+
+// Setup registers the given setup function that is called before each script
+// is run, e.g. to set up mock or HTTP stand-ins and to export their addresses
+// to the script via environment variables.
+func Setup(setup func(s *State)) Option {
+	return func(config *config) {
+		config.setup = setup
+	}
+}
+
+// Update enables or disables the update mode. By default, the update mode is
+// enabled by setting the `TEST_UPDATE` environment variable to a non-empty
+// value.
+func Update(update bool) Option {
+	return func(config *config) {
+		config.update = update
+	}
+}
+
+// scriptParams is the test parameter set of a script.
+type scriptParams struct {
+	// path the path of the script file.
+	path string
+}
+
+// Run runs all scripts, i.e. all `*.txtar` files, in the given directory as
+// isolated (by default) parallel test cases named by the script file names.
+// The files of the archive are extracted into a temporary working directory
+// before the commands of the script, i.e. the archive comment, are executed.
+func Run(t *testing.T, dir string, opts ...Option) {
+	t.Helper()
+
+	test.Map(t, scripts(t, dir)).Run(func(t test.Test, param scriptParams) {
+		RunScript(t, param.path, opts...)
+	})
+}
+
+// scripts resolves the test parameter sets of the scripts in the given
+// directory named by the script file names. The test fails, if the directory
+// contains no scripts.
+func scripts(t test.Test, dir string) map[string]scriptParams {
+	t.Helper()
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.txtar"))
+	if err != nil {
+		t.Fatalf("%v", err)
+	} else if len(paths) == 0 {
+		t.Fatalf("%v", ErrNoScripts(dir))
+	}
+
+	params := make(map[string]scriptParams, len(paths))
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), ".txtar")
+		params[name] = scriptParams{path: path}
+	}
+	return params
+}
+
+// RunScript runs the script with given path in the given test context.
+func RunScript(t test.Test, path string, opts ...Option) {
+	t.Helper()
+
+	archive, err := ReadArchive(path)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	newState(t, path, archive, newConfig(opts...)).run()
+}
+
+// newConfig creates the script runner configuration from the given options.
+func newConfig(opts ...Option) *config {
+	config := &config{
+		commands: map[string]Command{},
+		update:   os.Getenv(test.UpdateEnv) != "",
+	}
+	for name, command := range builtins {
+		config.commands[name] = command
+	}
+	for _, opt := range opts {
+		opt(config)
+	}
+	return config
+}
+
+// State is the state of a running script.
+type State struct {
+	t       test.Test
+	config  *config
+	mocks   *mock.Mocks
+	path    string
+	archive *Archive
+	updated bool
+	work    string
+	dir     string
+	env     map[string]string
+	stdout  bytes.Buffer
+	stderr  bytes.Buffer
+}
+
+// newState creates a new script state extracting the files of the given
+// archive into a new temporary working directory.
+func newState(
+	t test.Test, path string, archive *Archive, config *config,
+) *State {
+	t.Helper()
+
+	work, err := os.MkdirTemp("", "script-")
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if c, ok := t.(test.Cleanuper); ok {
+		c.Cleanup(func() { os.RemoveAll(work) })
+	}
+
+	s := &State{
+		t: t, config: config, mocks: mock.NewMock(t),
+		path: path, archive: archive, work: work, dir: work,
+		env: map[string]string{
+			"WORK":   work,
+			"HOME":   "/no-home",
+			"PATH":   os.Getenv("PATH"),
+			"TMPDIR": filepath.Join(work, ".tmp"),
+		},
+	}
+	for _, file := range append([]File{{Name: ".tmp/"}}, archive.Files...) {
+		if err := s.write(file.Name, file.Data); err != nil {
+			t.Fatalf("%v", err)
+		}
+	}
+	return s
+}
+
+// T returns the test context of the script.
+func (s *State) T() test.Test {
+	return s.t
+}
+
+// Mocks returns the mock handler of the script to set up mock stand-ins.
+func (s *State) Mocks() *mock.Mocks {
+	return s.mocks
+}
+
+// Gock returns the HTTP request/response mock controller of the script to
+// set up HTTP stand-ins for clients of custom commands.
+func (s *State) Gock() *gock.Controller {
+	return mock.Get(s.mocks, gock.NewGock)
+}
+
+// Work returns the working directory of the script.
+func (s *State) Work() string {
+	return s.work
+}
+
+// Dir returns the current directory of the script.
+func (s *State) Dir() string {
+	return s.dir
+}
+
+// Path returns the absolute path of the given path relative to the current
+// directory of the script.
+func (s *State) Path(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(s.dir, path)
+}
+
+// Getenv returns the value of the script environment variable with given key.
+func (s *State) Getenv(key string) string {
+	return s.env[key]
+}
+
+// Setenv sets the script environment variable with given key to given value.
+func (s *State) Setenv(key, value string) {
+	s.env[key] = value
+}
+
+// Environ returns the sorted script environment in `KEY=value` form.
+func (s *State) Environ() []string {
+	env := make([]string, 0, len(s.env))
+	for key, value := range s.env {
+		env = append(env, key+"="+value)
+	}
+	sort.Strings(env)
+	return env
+}
+
+// Stdout returns the standard output writer of the current command.
+func (s *State) Stdout() io.Writer {
+	return &s.stdout
+}
+
+// Stderr returns the standard error writer of the current command.
+func (s *State) Stderr() io.Writer {
+	return &s.stderr
+}
+
+// run runs the commands of the script and writes the updated archive, if the
+// expected output sections were updated.
+func (s *State) run() {
+	s.t.Helper()
+
+	if s.config.setup != nil {
+		s.config.setup(s)
+	}
+
+	lines := strings.Split(string(s.archive.Comment), "\n")
+	for index, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if err := s.exec(line); err != nil {
+			s.t.Fatalf("%s:%d: %s: %v", s.path, index+1, line, err)
+		}
+	}
+
+	if s.updated {
+		if err := os.WriteFile(s.path, s.archive.Format(), 0o644); err != nil {
+			s.t.Fatalf("%v", err)
+		}
+	}
+}
+
+// exec executes the given command line.
+func (s *State) exec(line string) error {
+	args, err := s.parse(line)
+	if err != nil {
+		return err
+	}
+
+	negate := args[0] == "!"
+	if negate {
+		args = args[1:]
+		if len(args) == 0 {
+			return ErrUsage("!", "! command [args...]")
+		}
+	}
+	command, ok := s.config.commands[args[0]]
+	if !ok {
+		return ErrUnknownCommand(args[0])
+	}
+
+	if !assertions[args[0]] {
+		s.stdout.Reset()
+		s.stderr.Reset()
+	}
+	err = command(s, args[1:]...)
+	if negate && err == nil {
+		return ErrUnexpectedSuccess(args[0])
+	} else if negate {
+		return nil
+	}
+	return err
+}
+
+// parse splits the given command line into arguments. Arguments are separated
+// by white spaces and may be quoted by single quotes, where two single quotes
+// represent a literal single quote. Environment variables, i.e. `$KEY` or
+// `${KEY}`, are expanded outside of quotes.
+func (s *State) parse(line string) ([]string, error) {
+	args := []string{}
+	var arg strings.Builder
+	quoted, inArg := false, false
+	for index := 0; index < len(line); index++ {
+		char := line[index]
+		switch {
+		case quoted && char == '\'':
+			if index+1 < len(line) && line[index+1] == '\'' {
+				arg.WriteByte('\'')
+				index++
+			} else {
+				quoted = false
+			}
+		case quoted:
+			arg.WriteByte(char)
+		case char == '\'':
+			quoted, inArg = true, true
+		case char == ' ' || char == '\t':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			end := index + 1
+			for end < len(line) && !strings.ContainsRune(" \t'", rune(line[end])) {
+				end++
+			}
+			arg.WriteString(os.Expand(line[index:end], s.Getenv))
+			index, inArg = end-1, true
+		}
+	}
+	if quoted {
+		return nil, ErrQuote(line)
+	} else if inArg {
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
+
+// write writes the file with given name relative to the working directory.
+// Names ending with a slash create directories.
+func (s *State) write(name string, data []byte) error {
+	path := filepath.Join(s.work, filepath.FromSlash(name))
+	if strings.HasSuffix(name, "/") {
+		return os.MkdirAll(path, 0o755)
+	} else if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// file returns the archive file stored at the given path relative to the
+// current directory of the script or nil, if the path is not part of the
+// archive.
+func (s *State) file(name string) *File {
+	path := s.Path(name)
+	for index, file := range s.archive.Files {
+		if filepath.Join(s.work, filepath.FromSlash(file.Name)) == path {
+			return &s.archive.Files[index]
+		}
+	}
+	return nil
+}
+
+// content returns the content of the given name, i.e. the captured standard
+// output or error of the last command or the content of the file.
+func (s *State) content(name string) ([]byte, error) {
+	switch name {
+	case "stdout":
+		return s.stdout.Bytes(), nil
+	case "stderr":
+		return s.stderr.Bytes(), nil
+	}
+	return os.ReadFile(s.Path(name))
+}
+
+// ErrNoScripts creates an error reporting a directory without scripts.
+func ErrNoScripts(dir string) error {
+	return fmt.Errorf("no scripts found [dir=%s]", dir)
+}
+
+// ErrUnknownCommand creates an error reporting an unknown script command.
+func ErrUnknownCommand(name string) error {
+	return fmt.Errorf("unknown command [name=%s]", name)
+}
+
+// ErrUnexpectedSuccess creates an error reporting a negated command that
+// unexpectedly succeeded.
+func ErrUnexpectedSuccess(name string) error {
+	return fmt.Errorf("unexpected command success [name=%s]", name)
+}
+
+// ErrUsage creates an error reporting an invalid command usage.
+func ErrUsage(name, usage string) error {
+	return fmt.Errorf("invalid usage [name=%s]: usage: %s", name, usage)
+}
+
+// ErrQuote creates an error reporting a missing closing quote.
+func ErrQuote(line string) error {
+	return fmt.Errorf("missing closing quote [line=%s]", line)
+}
+
+// ErrNoMatch creates an error reporting content not matching the pattern.
+func ErrNoMatch(name, pattern string) error {
+	return fmt.Errorf("no match for %q in %s", pattern, name)
+}
+
+// ErrCompare creates an error reporting differing contents.
+func ErrCompare(name, expect string, actual, expected []byte) error {
+	return fmt.Errorf("%s and %s differ:\n--- %s\n%s--- %s\n%s",
+		name, expect, name, fixNewline(actual), expect, fixNewline(expected))
+}
@@ -0,0 +1,231 @@
+package script_test
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tkrop/go-testing/mock"
+	"github.com/tkrop/go-testing/script"
+	"github.com/tkrop/go-testing/test"
+)
+
+// commands are the custom commands used by the test scripts.
+var commands = map[string]script.Command{
+	"greet": greet,
+	"fetch": fetch,
+}
+
+// greet greets the name from the environment or the given file.
+func greet(s *script.State, args ...string) error {
+	name := s.Getenv("NAME")
+	if len(args) == 2 && args[0] == "-f" {
+		file, err := os.Open(s.Path(args[1]))
+		if err != nil {
+			_, _ = io.WriteString(s.Stderr(), err.Error()+"\n")
+			return err
+		}
+		defer file.Close()
+		scanner := bufio.NewScanner(file)
+		scanner.Scan()
+		name = scanner.Text()
+	}
+	_, err := io.WriteString(s.Stdout(), "hello "+name+"\n")
+	return err
+}
+
+// fetch fetches the given URL using a client intercepted by the HTTP
+// stand-ins of the script.
+func fetch(s *script.State, args ...string) error {
+	client := &http.Client{}
+	s.Gock().InterceptClient(client)
+	resp, err := client.Get(args[0])
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, err = io.Copy(s.Stdout(), resp.Body)
+	return err
+}
+
+func TestRun(t *testing.T) {
+	script.Run(t, "testdata", script.Commands(commands), script.Update(false))
+}
+
+type RunScriptParams struct {
+	script  string
+	missing bool
+	setup   func(path string) mock.SetupFunc
+	opts    []script.Option
+	expect  test.Expect
+}
+
+var testRunScriptParams = map[string]RunScriptParams{
+	"setup stand-in": {
+		script: "fetch $API_URL/value\nstdout '^value$'\n",
+		opts: []script.Option{
+			script.Commands(commands),
+			script.Setup(func(s *script.State) {
+				s.Setenv("API_URL", "http://api.test")
+				s.Gock().New("http://api.test").Get("/value").
+					Reply(http.StatusOK).BodyString("value")
+			}),
+		},
+		expect: test.Success,
+	},
+	"custom replaces builtin": {
+		script: "exec anything\n",
+		opts: []script.Option{script.Commands(map[string]script.Command{
+			"exec": func(*script.State, ...string) error { return nil },
+		})},
+		expect: test.Success,
+	},
+	"quoted arguments": {
+		script: "env 'QUOTE=it''s $HOME' HOME=$HOME/x\n" +
+			"env RESULT=${QUOTE}-$HOME\n" +
+			"exec sh -c 'echo $RESULT'\n" +
+			"stdout '^it''s \\$HOME-/no-home/x$'\n",
+		expect: test.Success,
+	},
+	"unknown command": {
+		script: "# comment\n\nunknown arg\n",
+		setup: func(path string) mock.SetupFunc {
+			return test.Fatalf("%s:%d: %s: %v", path, 3, "unknown arg",
+				script.ErrUnknownCommand("unknown"))
+		},
+	},
+	"unexpected success": {
+		script: "! exec true\n",
+		setup: func(path string) mock.SetupFunc {
+			return test.Fatalf("%s:%d: %s: %v", path, 1, "! exec true",
+				script.ErrUnexpectedSuccess("exec"))
+		},
+	},
+	"negation usage": {
+		script: "!\n",
+		setup: func(path string) mock.SetupFunc {
+			return test.Fatalf("%s:%d: %s: %v", path, 1, "!",
+				script.ErrUsage("!", "! command [args...]"))
+		},
+	},
+	"missing quote": {
+		script: "exec 'true\n",
+		setup: func(path string) mock.SetupFunc {
+			return test.Fatalf("%s:%d: %s: %v", path, 1, "exec 'true",
+				script.ErrQuote("exec 'true"))
+		},
+	},
+	"invalid usage": {
+		script: "cd a b\n",
+		setup: func(path string) mock.SetupFunc {
+			return test.Fatalf("%s:%d: %s: %v", path, 1, "cd a b",
+				script.ErrUsage("cd", "cd dir"))
+		},
+	},
+	"missing script": {
+		missing: true,
+		setup: func(path string) mock.SetupFunc {
+			return test.Fatalf("%v", &os.PathError{
+				Op: "open", Path: path, Err: syscall.ENOENT,
+			})
+		},
+	},
+}
+
+func TestRunScript(t *testing.T) {
+	test.Map(t, testRunScriptParams).
+		Run(func(t test.Test, param RunScriptParams) {
+			// Given
+			path := filepath.Join(tempDir(t), "test.txtar")
+			if !param.missing {
+				require.NoError(t, os.WriteFile(path,
+					[]byte(param.script), 0o600))
+			}
+			if param.setup != nil {
+				mock.NewMock(t).Expect(param.setup(path))
+			}
+
+			// When
+			script.RunScript(t, path, param.opts...)
+		})
+}
+
+type UpdateParams struct {
+	script       string
+	update       bool
+	expectScript string
+	expect       test.Expect
+}
+
+var testUpdateParams = map[string]UpdateParams{
+	"update stdout": {
+		script: "greet\ncmp stdout expect.txt\n" +
+			"-- expect.txt --\nhello old\n",
+		update: true,
+		expectScript: "greet\ncmp stdout expect.txt\n" +
+			"-- expect.txt --\nhello world\n",
+		expect: test.Success,
+	},
+	"update in sub directory": {
+		script: "cd sub\ngreet\ncmp stdout expect.txt\n" +
+			"-- sub/expect.txt --\nhello old\n",
+		update: true,
+		expectScript: "cd sub\ngreet\ncmp stdout expect.txt\n" +
+			"-- sub/expect.txt --\nhello world\n",
+		expect: test.Success,
+	},
+	"unchanged": {
+		script: "greet\ncmp stdout expect.txt\n" +
+			"-- expect.txt --\nhello world\n",
+		update: true,
+		expectScript: "greet\ncmp stdout expect.txt\n" +
+			"-- expect.txt --\nhello world\n",
+		expect: test.Success,
+	},
+	"no update": {
+		script: "greet\ncmp stdout expect.txt\n" +
+			"-- expect.txt --\nhello old\n",
+		expectScript: "greet\ncmp stdout expect.txt\n" +
+			"-- expect.txt --\nhello old\n",
+	},
+}
+
+func TestUpdate(t *testing.T) {
+	test.Map(t, testUpdateParams).
+		Run(func(t test.Test, param UpdateParams) {
+			// Given
+			path := filepath.Join(tempDir(t), "test.txtar")
+			require.NoError(t, os.WriteFile(path, []byte(param.script), 0o600))
+			if !param.update {
+				mock.NewMock(t).Expect(test.Fatalf("%s:%d: %s: %v", path, 2,
+					"cmp stdout expect.txt", script.ErrCompare("stdout",
+						"expect.txt", []byte("hello world\n"),
+						[]byte("hello old\n"))))
+			}
+			t.(*test.Tester).Cleanup(func() {
+				content, err := os.ReadFile(path)
+				assert.NoError(t, err)
+				assert.Equal(t, param.expectScript, string(content))
+			})
+
+			// When
+			script.RunScript(t, path, script.Commands(commands),
+				script.Update(param.update), script.Setup(
+					func(s *script.State) { s.Setenv("NAME", "world") }))
+		})
+}
+
+// tempDir creates a temporary directory that is removed after the test.
+func tempDir(t test.Test) string {
+	dir, err := os.MkdirTemp("", "script-test-")
+	require.NoError(t, err)
+	t.(*test.Tester).Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
@@ -0,0 +1,56 @@
+package script
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tkrop/go-testing/mock"
+	"github.com/tkrop/go-testing/test"
+)
+
+type ScriptsParams struct {
+	dir          string
+	expectParams map[string]scriptParams
+	expectError  error
+	expect       test.Expect
+}
+
+var testScriptsParams = map[string]ScriptsParams{
+	"scripts found": {
+		dir: "testdata",
+		expectParams: map[string]scriptParams{
+			"exec":  {path: filepath.Join("testdata", "exec.txtar")},
+			"fetch": {path: filepath.Join("testdata", "fetch.txtar")},
+			"greet": {path: filepath.Join("testdata", "greet.txtar")},
+		},
+		expect: test.Success,
+	},
+	"no scripts found": {
+		dir:         "not-existing",
+		expectError: ErrNoScripts("not-existing"),
+		expect:      test.Failure,
+	},
+	"invalid pattern": {
+		dir:         "[",
+		expectError: filepath.ErrBadPattern,
+		expect:      test.Failure,
+	},
+}
+
+func TestScripts(t *testing.T) {
+	test.Map(t, testScriptsParams).
+		Run(func(t test.Test, param ScriptsParams) {
+			// Given
+			if param.expectError != nil {
+				mock.NewMock(t).Expect(test.Fatalf("%v", param.expectError))
+			}
+
+			// When
+			params := scripts(t, param.dir)
+
+			// Then
+			assert.Equal(t, param.expectParams, params)
+		})
+}
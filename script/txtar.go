@@ -0,0 +1,99 @@
+package script
+
+import (
+	"bytes"
+	"os"
+	"strings"
+)
+
+// Archive is a simple text archive in `txtar` format, i.e. a comment followed
+// by a sequence of files, each introduced by a marker line `-- name --`.
+type Archive struct {
+	// Comment the leading comment of the archive, i.e. the script.
+	Comment []byte
+	// Files the files of the archive in the order of appearance.
+	Files []File
+}
+
+// File is a single file of a text archive.
+type File struct {
+	// Name the name of the file.
+	Name string
+	// Data the content of the file.
+	Data []byte
+}
+
+// ParseArchive parses the given data in `txtar` format.
+func ParseArchive(data []byte) *Archive {
+	archive := &Archive{}
+	var file *File
+	for len(data) > 0 {
+		line := data
+		if index := bytes.IndexByte(data, '\n'); index >= 0 {
+			line, data = data[:index+1], data[index+1:]
+		} else {
+			data = nil
+		}
+
+		if name, ok := marker(line); ok {
+			archive.Files = append(archive.Files, File{Name: name})
+			file = &archive.Files[len(archive.Files)-1]
+		} else if file != nil {
+			file.Data = append(file.Data, line...)
+		} else {
+			archive.Comment = append(archive.Comment, line...)
+		}
+	}
+	return archive
+}
+
+// ReadArchive reads and parses the archive file with given path.
+func ReadArchive(path string) (*Archive, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseArchive(data), nil
+}
+
+// Format formats the archive in `txtar` format. Missing trailing newlines of
+// the comment and the files are added.
+func (a *Archive) Format() []byte {
+	buffer := &bytes.Buffer{}
+	buffer.Write(fixNewline(a.Comment))
+	for _, file := range a.Files {
+		buffer.WriteString("-- " + file.Name + " --\n")
+		buffer.Write(fixNewline(file.Data))
+	}
+	return buffer.Bytes()
+}
+
+// File returns the file with given name or nil, if the archive does not
+// contain the file.
+func (a *Archive) File(name string) *File {
+	for index := range a.Files {
+		if a.Files[index].Name == name {
+			return &a.Files[index]
+		}
+	}
+	return nil
+}
+
+// marker returns the file name of the given marker line.
+func marker(line []byte) (string, bool) {
+	text := strings.TrimRight(string(line), "\r\n")
+	if !strings.HasPrefix(text, "-- ") || !strings.HasSuffix(text, " --") ||
+		len(text) < len("-- x --") {
+		return "", false
+	}
+	name := strings.TrimSpace(text[3 : len(text)-3])
+	return name, name != ""
+}
+
+// fixNewline adds a missing trailing newline to the given non-empty data.
+func fixNewline(data []byte) []byte {
+	if len(data) == 0 || data[len(data)-1] == '\n' {
+		return data
+	}
+	return append(append([]byte{}, data...), '\n')
+}
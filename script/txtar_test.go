@@ -0,0 +1,69 @@
+package script_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tkrop/go-testing/script"
+	"github.com/tkrop/go-testing/test"
+)
+
+type ArchiveParams struct {
+	data          string
+	expectArchive *script.Archive
+	expectFormat  string
+}
+
+var testArchiveParams = map[string]ArchiveParams{
+	"empty": {
+		expectArchive: &script.Archive{},
+	},
+	"comment only": {
+		data: "exec true\nstdout .",
+		expectArchive: &script.Archive{
+			Comment: []byte("exec true\nstdout ."),
+		},
+		expectFormat: "exec true\nstdout .\n",
+	},
+	"files": {
+		data: "exec true\n-- a.txt --\nline a\n-- dir/b.txt --\n" +
+			"-- c.txt --\nline c",
+		expectArchive: &script.Archive{
+			Comment: []byte("exec true\n"),
+			Files: []script.File{
+				{Name: "a.txt", Data: []byte("line a\n")},
+				{Name: "dir/b.txt"},
+				{Name: "c.txt", Data: []byte("line c")},
+			},
+		},
+		expectFormat: "exec true\n-- a.txt --\nline a\n-- dir/b.txt --\n" +
+			"-- c.txt --\nline c\n",
+	},
+	"invalid markers": {
+		data: "-- --\n--a.txt--\n-- b.txt --\n-- c.txt\n",
+		expectArchive: &script.Archive{
+			Comment: []byte("-- --\n--a.txt--\n"),
+			Files: []script.File{
+				{Name: "b.txt", Data: []byte("-- c.txt\n")},
+			},
+		},
+		expectFormat: "-- --\n--a.txt--\n-- b.txt --\n-- c.txt\n",
+	},
+}
+
+func TestArchive(t *testing.T) {
+	test.Map(t, testArchiveParams).
+		Run(func(t test.Test, param ArchiveParams) {
+			// When
+			archive := script.ParseArchive([]byte(param.data))
+
+			// Then
+			assert.Equal(t, param.expectArchive, archive)
+			assert.Equal(t, param.expectFormat, string(archive.Format()))
+			for index, file := range archive.Files {
+				assert.Same(t, &archive.Files[index], archive.File(file.Name))
+			}
+			assert.Nil(t, archive.File("missing"))
+		})
+}
@@ -0,0 +1,180 @@
+// Package snapshot contains a small framework for interaction snapshot tests,
+// i.e. approval tests comparing the recorded mock and HTTP interaction trace
+// of a test with a golden trace file. It is part of the public interface,
+// however, we are still experimenting to optimize the interface and the user
+// experience.
+package snapshot
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	gosync "sync"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/tkrop/go-testing/gock"
+	"github.com/tkrop/go-testing/mock"
+	"github.com/tkrop/go-testing/test"
+)
+
+// Normalizer is a function normalizing volatile values of a trace entry, e.g.
+// timestamps or random identifiers, to create a stable interaction trace.
+type Normalizer func(entry string) string
+
+// Replace creates a normalizer replacing all matches of the given regular
+// expression with the given replacement.
+func Replace(pattern, replace string) Normalizer {
+	regex := regexp.MustCompile(pattern)
+	return func(entry string) string {
+		return regex.ReplaceAllString(entry, replace)
+	}
+}
+
+// Default normalizers for common volatile values.
+var (
+	// UUIDs replaces UUIDs with `<uuid>`.
+	UUIDs = Replace(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-`+
+		`[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`, "<uuid>")
+	// Times replaces RFC3339 timestamps with `<time>`.
+	Times = Replace(`\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?`+
+		`(Z|[+-]\d{2}:\d{2})`, "<time>")
+)
+
+// Approve creates a mock setup function that records the mock and HTTP
+// interactions of the test and compares the interaction trace with the golden
+// trace file at the given path when the test finishes. The given normalizers
+// are applied to each trace entry. In update mode, i.e. if the `TEST_UPDATE`
+// environment variable is set, the golden trace file is written instead. On
+// repeated setup, the path and the normalizers of the approval are replaced,
+// while the approval is registered as tracer only once.
+//
+// Only mock calls using the notification functions `Return`, `ReturnWith`,
+// and `Panic` of the mock handler and requests handled by the HTTP
+// request/response mock controller are recorded.
+func Approve(path string, normalizers ...Normalizer) mock.SetupFunc {
+	return func(mocks *mock.Mocks) any {
+		approval := mock.Get(mocks, NewApproval)
+		approval.mu.Lock()
+		approval.path = path
+		approval.normalizers = normalizers
+		approval.mu.Unlock()
+		approval.register.Do(func() {
+			mocks.Tracer(approval)
+			mock.Get(mocks, gock.NewGock).Tracer(approval)
+		})
+		return nil
+	}
+}
+
+// Approval is an interaction trace recorder comparing the recorded trace with
+// a golden trace file.
+type Approval struct {
+	t           test.Test
+	mu          gosync.Mutex
+	path        string
+	normalizers []Normalizer
+	entries     []string
+	update      bool
+	register    gosync.Once
+}
+
+// NewApproval creates a new interaction trace recorder for the given mock
+// controller that verifies the trace when the test finishes.
+func NewApproval(ctrl *gomock.Controller) *Approval {
+	t, ok := ctrl.T.(test.Test)
+	if !ok {
+		panic(ErrNoTest(ctrl.T))
+	}
+
+	approval := &Approval{t: t, update: os.Getenv(test.UpdateEnv) != ""}
+	if c, ok := t.(test.Cleanuper); ok {
+		c.Cleanup(func() {
+			if err := approval.Verify(); err != nil {
+				approval.t.Errorf("%v", err)
+			}
+		})
+	}
+	return approval
+}
+
+// Trace records the given interaction trace entry.
+func (a *Approval) Trace(entry string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.entries = append(a.entries, entry)
+}
+
+// Update enables or disables the update mode of the golden trace file. By
+// default, the update mode is enabled by the `TEST_UPDATE` environment
+// variable.
+func (a *Approval) Update(update bool) *Approval {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.update = update
+	return a
+}
+
+// String returns the normalized interaction trace in golden file format.
+func (a *Approval) String() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	builder := strings.Builder{}
+	for _, entry := range a.entries {
+		for _, normalize := range a.normalizers {
+			entry = normalize(entry)
+		}
+		builder.WriteString(entry + "\n")
+	}
+	return builder.String()
+}
+
+// Verify compares the normalized interaction trace with the golden trace file.
+// In update mode, the golden trace file is written instead.
+func (a *Approval) Verify() error {
+	actual := a.String()
+	a.mu.Lock()
+	update := a.update
+	a.mu.Unlock()
+	if update {
+		if err := os.MkdirAll(filepath.Dir(a.path), 0o755); err != nil {
+			return err
+		}
+		return os.WriteFile(a.path, []byte(actual), 0o644)
+	}
+
+	expected, err := os.ReadFile(a.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrMissing(a.path)
+	} else if err != nil {
+		return err
+	} else if string(expected) != actual {
+		return ErrTrace(a.path, string(expected), actual)
+	}
+	return nil
+}
+
+// ErrNoTest creates an error that the given test reporter of a mock controller
+// does not support the test interface required for approvals.
+func ErrNoTest(reporter any) error {
+	return fmt.Errorf("approval not supported by test setup [type=%T]",
+		reporter)
+}
+
+// ErrMissing creates an error reporting a missing golden trace file.
+func ErrMissing(path string) error {
+	return fmt.Errorf("golden trace missing [path=%s, update=%s]",
+		path, test.UpdateEnv)
+}
+
+// ErrTrace creates an error reporting an interaction trace differing from the
+// golden trace.
+func ErrTrace(path, expected, actual string) error {
+	return fmt.Errorf("interaction trace differs [path=%s, update=%s]:\n"+
+		"--- expected\n%s--- actual\n%s", path, test.UpdateEnv,
+		expected, actual)
+}
@@ -0,0 +1,270 @@
+package snapshot_test
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tkrop/go-testing/gock"
+	"github.com/tkrop/go-testing/mock"
+	"github.com/tkrop/go-testing/snapshot"
+	"github.com/tkrop/go-testing/test"
+)
+
+//go:generate mockgen -package=snapshot_test -destination=mock_iface_test.go -source=snapshot_test.go  IFace
+
+type IFace interface {
+	CallA(string)
+	CallB(string) (string, error)
+}
+
+func CallA(input string) mock.SetupFunc {
+	return func(mocks *mock.Mocks) any {
+		return mock.Get(mocks, NewMockIFace).EXPECT().CallA(input).
+			Do(mocks.Return(IFace.CallA))
+	}
+}
+
+func CallB(input, output string, err error) mock.SetupFunc {
+	return func(mocks *mock.Mocks) any {
+		return mock.Get(mocks, NewMockIFace).EXPECT().CallB(input).
+			DoAndReturn(mocks.Return(IFace.CallB, output, err))
+	}
+}
+
+func CallBPanic(input string, reason any) mock.SetupFunc {
+	return func(mocks *mock.Mocks) any {
+		return mock.Get(mocks, NewMockIFace).EXPECT().CallB(input).
+			DoAndReturn(mocks.Panic(IFace.CallB, reason))
+	}
+}
+
+func GetHTTP(path string, status int) mock.SetupFunc {
+	return func(mocks *mock.Mocks) any {
+		mock.Get(mocks, gock.NewGock).New("http://api.test").Get(path).
+			Reply(status)
+		return nil
+	}
+}
+
+// unit is the legacy flow under test calling the mock and the HTTP client.
+func unit(mocks *mock.Mocks, input string) {
+	iface := mock.Get(mocks, NewMockIFace)
+	iface.CallA(input)
+	output, _ := iface.CallB(input)
+
+	client := &http.Client{}
+	mock.Get(mocks, gock.NewGock).InterceptClient(client)
+	if resp, err := client.Get("http://api.test/" + output); err == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+}
+
+type ApproveParams struct {
+	golden      string
+	missing     bool
+	update      bool
+	repeat      bool
+	normalizers []snapshot.Normalizer
+	input       string
+	setup       mock.SetupFunc
+	expectTrace string
+	expectError func(path string) error
+	expect      test.Expect
+}
+
+var testApproveParams = map[string]ApproveParams{
+	"matching trace": {
+		golden: "IFace.CallA(\"a\") => ()\n" +
+			"IFace.CallB(\"a\") => (\"b\", <nil>)\n" +
+			"GET http://api.test/b => 200\n",
+		input: "a",
+		setup: mock.Setup(
+			GetHTTP("/b", http.StatusOK),
+			CallB("a", "b", nil),
+			CallA("a"),
+		),
+		expectTrace: "IFace.CallA(\"a\") => ()\n" +
+			"IFace.CallB(\"a\") => (\"b\", <nil>)\n" +
+			"GET http://api.test/b => 200\n",
+		expect: test.Success,
+	},
+	"repeated approval": {
+		golden: "IFace.CallA(\"<uuid>\") => ()\n" +
+			"IFace.CallB(\"<uuid>\") => (\"b\", <nil>)\n" +
+			"GET http://api.test/b => 200\n",
+		repeat:      true,
+		normalizers: []snapshot.Normalizer{snapshot.UUIDs},
+		input:       "6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+		setup: mock.Setup(
+			GetHTTP("/b", http.StatusOK),
+			CallB("6ba7b810-9dad-11d1-80b4-00c04fd430c8", "b", nil),
+			CallA("6ba7b810-9dad-11d1-80b4-00c04fd430c8"),
+		),
+		expectTrace: "IFace.CallA(\"<uuid>\") => ()\n" +
+			"IFace.CallB(\"<uuid>\") => (\"b\", <nil>)\n" +
+			"GET http://api.test/b => 200\n",
+		expect: test.Success,
+	},
+	"normalized trace": {
+		golden: "IFace.CallA(\"<uuid>\") => ()\n" +
+			"IFace.CallB(\"<uuid>\") => (\"<time>\", <nil>)\n" +
+			"GET http://api.test/<time> => error(gock: cannot match " +
+			"any request)\n",
+		normalizers: []snapshot.Normalizer{
+			snapshot.UUIDs, snapshot.Times,
+		},
+		input: "6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+		setup: mock.Setup(
+			CallB("6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+				"2022-01-02T03:04:05.123Z", nil),
+			CallA("6ba7b810-9dad-11d1-80b4-00c04fd430c8"),
+		),
+		expectTrace: "IFace.CallA(\"<uuid>\") => ()\n" +
+			"IFace.CallB(\"<uuid>\") => (\"<time>\", <nil>)\n" +
+			"GET http://api.test/<time> => error(gock: cannot match " +
+			"any request)\n",
+		expect: test.Success,
+	},
+	"update trace": {
+		golden: "IFace.CallA(\"x\") => ()\n",
+		update: true,
+		input:  "a",
+		setup: mock.Setup(
+			GetHTTP("/b", http.StatusNotFound),
+			CallB("a", "b", assert.AnError),
+			CallA("a"),
+		),
+		expectTrace: "IFace.CallA(\"a\") => ()\n" +
+			"IFace.CallB(\"a\") => (\"b\", " + assert.AnError.Error() + ")\n" +
+			"GET http://api.test/b => 404\n",
+		expect: test.Success,
+	},
+	"update missing trace": {
+		missing: true,
+		update:  true,
+		input:   "a",
+		setup: mock.Setup(
+			GetHTTP("/b", http.StatusOK),
+			CallB("a", "b", nil),
+			CallA("a"),
+		),
+		expectTrace: "IFace.CallA(\"a\") => ()\n" +
+			"IFace.CallB(\"a\") => (\"b\", <nil>)\n" +
+			"GET http://api.test/b => 200\n",
+		expect: test.Success,
+	},
+	"differing trace": {
+		golden: "IFace.CallB(\"a\") => (\"b\", <nil>)\n" +
+			"IFace.CallA(\"a\") => ()\n",
+		input: "a",
+		setup: mock.Setup(
+			GetHTTP("/b", http.StatusOK),
+			CallB("a", "b", nil),
+			CallA("a"),
+		),
+		expectTrace: "IFace.CallB(\"a\") => (\"b\", <nil>)\n" +
+			"IFace.CallA(\"a\") => ()\n",
+		expectError: func(path string) error {
+			return snapshot.ErrTrace(path,
+				"IFace.CallB(\"a\") => (\"b\", <nil>)\n"+
+					"IFace.CallA(\"a\") => ()\n",
+				"IFace.CallA(\"a\") => ()\n"+
+					"IFace.CallB(\"a\") => (\"b\", <nil>)\n"+
+					"GET http://api.test/b => 200\n")
+		},
+	},
+	"missing trace": {
+		missing: true,
+		input:   "a",
+		setup: mock.Setup(
+			GetHTTP("/b", http.StatusOK),
+			CallB("a", "b", nil),
+			CallA("a"),
+		),
+		expectError: func(path string) error {
+			return snapshot.ErrMissing(path)
+		},
+	},
+}
+
+func TestApprove(t *testing.T) {
+	test.Map(t, testApproveParams).
+		Run(func(t test.Test, param ApproveParams) {
+			// Given
+			path := filepath.Join(tempDir(t), "trace", "golden.trace")
+			if !param.missing {
+				require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+				require.NoError(t, os.WriteFile(path,
+					[]byte(param.golden), 0o600))
+			}
+			if param.expectError != nil {
+				mock.NewMock(t).Expect(test.Errorf("%v",
+					param.expectError(path)))
+			}
+			t.(*test.Tester).Cleanup(func() {
+				content, _ := os.ReadFile(path)
+				assert.Equal(t, param.expectTrace, string(content))
+			})
+			mocks := mock.NewMock(t).Expect(mock.Setup(
+				snapshot.Approve(path, param.normalizers...),
+				param.setup,
+			))
+			if param.repeat {
+				mocks.Expect(snapshot.Approve(path, param.normalizers...))
+			}
+			mock.Get(mocks, snapshot.NewApproval).Update(param.update)
+
+			// When
+			unit(mocks, param.input)
+		})
+}
+
+func TestApprovePanic(t *testing.T) {
+	// Given
+	path := filepath.Join(t.TempDir(), "golden.trace")
+	require.NoError(t, os.WriteFile(path,
+		[]byte("IFace.CallB(\"a\") => panic(failure)\n"), 0o600))
+	mocks := mock.NewMock(t).Expect(mock.Setup(
+		snapshot.Approve(path),
+		CallBPanic("a", "failure"),
+	))
+
+	// When
+	defer func() {
+		assert.Equal(t, "failure", recover())
+	}()
+	_, _ = mock.Get(mocks, NewMockIFace).CallB("a")
+}
+
+func TestNewApprovalPanic(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(reporter{})
+
+	// When
+	defer func() {
+		assert.Equal(t, snapshot.ErrNoTest(ctrl.T), recover())
+	}()
+	snapshot.NewApproval(ctrl)
+}
+
+// tempDir creates a temporary directory that is removed after the test.
+func tempDir(t test.Test) string {
+	dir, err := os.MkdirTemp("", "snapshot-test-")
+	require.NoError(t, err)
+	t.(*test.Tester).Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+// reporter is a minimal test reporter not supporting approvals.
+type reporter struct{}
+
+func (reporter) Errorf(string, ...any) {}
+func (reporter) Fatalf(string, ...any) {}
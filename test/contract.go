@@ -0,0 +1,87 @@
+package test
+
+import (
+	"testing"
+)
+
+// ContractRunner is a test runner for contract test parameter sets shared by
+// all implementations of the interface `I`.
+type ContractRunner[I, P any] interface {
+	// Run runs the test parameter sets (by default) parallel against each
+	// implementation.
+	Run(call func(t Test, impl I, param P))
+	// RunSeq runs the test parameter sets in a sequence against each
+	// implementation.
+	RunSeq(call func(t Test, impl I, param P))
+}
+
+// contract is the contract test runner struct.
+type contract[I, P any] struct {
+	t      *testing.T
+	impls  map[string]func(Test) I
+	params map[string]P
+}
+
+// Contract creates a contract test runner running the given contract test
+// parameter sets against each of the given implementations of the interface
+// `I` using the isolated test runner, e.g. to continuously verify the
+// behavioral equivalence of an in-memory fake, an SQL, and an HTTP-backed
+// implementation. The implementations are created per test case by the given
+// factories and the test cases are grouped by implementation in sub-tests
+// named by the factory, e.g. `TestStore/sql/put`:
+//
+//	test.Contract(t, map[string]func(test.Test) Store{
+//		"memory": func(t test.Test) Store { return NewMemoryStore() },
+//		"sql":    func(t test.Test) Store { return NewSQLStore(db(t)) },
+//	}, testStoreParams).
+//		Run(func(t test.Test, store Store, param StoreParams) {
+//			...
+//		})
+func Contract[I, P any](
+	t *testing.T, impls map[string]func(Test) I, params map[string]P,
+) ContractRunner[I, P] {
+	t.Helper()
+
+	return &contract[I, P]{t: t, impls: impls, params: params}
+}
+
+// Run runs the test parameter sets (by default) parallel against each
+// implementation.
+func (c *contract[I, P]) Run(call func(t Test, impl I, param P)) {
+	c.run(call, Parallel)
+}
+
+// RunSeq runs the test parameter sets in a sequence against each
+// implementation.
+func (c *contract[I, P]) RunSeq(call func(t Test, impl I, param P)) {
+	c.run(call, false)
+}
+
+// run runs the test parameter sets either parallel or in sequence against each
+// implementation.
+func (c *contract[I, P]) run(
+	call func(t Test, impl I, param P), parallel bool,
+) {
+	if debugger.parallel(parallel) {
+		c.t.Parallel()
+	}
+
+	names := make([]string, 0, len(c.impls))
+	for name := range c.impls {
+		names = append(names, name)
+	}
+	for _, name := range shuffler.names(c.t, names) {
+		create := c.impls[name]
+		c.t.Run(name, func(t *testing.T) {
+			runner := Map(t, c.params)
+			test := func(t Test, param P) {
+				call(t, create(t), param)
+			}
+			if parallel {
+				runner.Run(test)
+			} else {
+				runner.RunSeq(test)
+			}
+		})
+	}
+}
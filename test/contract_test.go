@@ -0,0 +1,162 @@
+package test_test
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tkrop/go-testing/test"
+)
+
+// Counter is a simple counter interface with multiple implementations.
+type Counter interface {
+	// Add adds the given delta to the counter and returns the new value.
+	Add(delta int) int
+	// Value returns the current value of the counter.
+	Value() int
+}
+
+// plainCounter is a plain counter implementation.
+type plainCounter struct {
+	value int
+}
+
+func (c *plainCounter) Add(delta int) int {
+	c.value += delta
+	return c.value
+}
+
+func (c *plainCounter) Value() int {
+	return c.value
+}
+
+// syncCounter is a thread-safe counter implementation.
+type syncCounter struct {
+	mu     sync.Mutex
+	values []int
+}
+
+func (c *syncCounter) Add(delta int) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values = append(c.values, delta)
+	return c.value()
+}
+
+func (c *syncCounter) Value() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.value()
+}
+
+func (c *syncCounter) value() int {
+	sum := 0
+	for _, value := range c.values {
+		sum += value
+	}
+	return sum
+}
+
+// contractNames collects the names of the contract test cases.
+var contractNames = struct {
+	sync.Mutex
+	names []string
+}{}
+
+// record records the name of the given contract test case.
+func record(t test.Test) {
+	contractNames.Lock()
+	defer contractNames.Unlock()
+	contractNames.names = append(contractNames.names, t.Name())
+}
+
+type CounterParams struct {
+	deltas      []int
+	expectAdded []int
+	expectValue int
+}
+
+var testCounterParams = map[string]CounterParams{
+	"initial value": {
+		expectAdded: []int{},
+		expectValue: 0,
+	},
+	"add value": {
+		deltas:      []int{2},
+		expectAdded: []int{2},
+		expectValue: 2,
+	},
+	"add negative value": {
+		deltas:      []int{2, -3},
+		expectAdded: []int{2, -1},
+		expectValue: -1,
+	},
+}
+
+// testCounter is the shared contract test of the counter implementations.
+func testCounter(t test.Test, counter Counter, param CounterParams) {
+	// Given
+	record(t)
+
+	// When
+	added := []int{}
+	for _, delta := range param.deltas {
+		added = append(added, counter.Add(delta))
+	}
+
+	// Then
+	assert.Equal(t, param.expectAdded, added)
+	assert.Equal(t, param.expectValue, counter.Value())
+}
+
+// counters are the counter implementations under test.
+var counters = map[string]func(test.Test) Counter{
+	"plain": func(test.Test) Counter { return &plainCounter{} },
+	"sync":  func(test.Test) Counter { return &syncCounter{} },
+}
+
+// expectContract registers the cleanup validating the names of the contract
+// test cases of the given test.
+func expectContract(t *testing.T) {
+	t.Cleanup(func() {
+		expect := []string{}
+		for _, name := range []string{"plain", "sync"} {
+			for _, tcase := range []string{
+				"add_negative_value", "add_value", "initial_value",
+			} {
+				expect = append(expect,
+					fmt.Sprintf("%s/%s/%s", t.Name(), name, tcase))
+			}
+		}
+
+		contractNames.Lock()
+		defer contractNames.Unlock()
+		names, others := []string{}, []string{}
+		for _, name := range contractNames.names {
+			if strings.HasPrefix(name, t.Name()+"/") {
+				names = append(names, name)
+			} else {
+				others = append(others, name)
+			}
+		}
+		sort.Strings(names)
+		assert.Equal(t, expect, names)
+		contractNames.names = others
+	})
+}
+
+func TestContract(t *testing.T) {
+	expectContract(t)
+
+	test.Contract(t, counters, testCounterParams).Run(testCounter)
+}
+
+func TestContractSeq(t *testing.T) {
+	expectContract(t)
+
+	test.Contract(t, counters, testCounterParams).RunSeq(testCounter)
+}
@@ -0,0 +1,197 @@
+package test
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	gosync "sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DebugEnv is the environment variable to enable the sequential debug
+	// mode without using `Debug`. The values `on` and `true` enable the debug
+	// mode.
+	DebugEnv = "TEST_DEBUG"
+	// DebugFlag is the test binary flag to enable the sequential debug mode
+	// without using `Debug`, e.g. via `go test -args -test-debug`. The flag is
+	// only registered by `Main`, so that binaries importing the package do not
+	// provide it.
+	DebugFlag = "test-debug"
+)
+
+// Debug adds a policy to enable the sequential debug mode, that forces all
+// isolated test runs to be executed sequentially, disables the test timeout,
+// and prints the isolation lifecycle events of each test, i.e. start,
+// cleanup, unlock, and expectation evaluation. This allows to debug a flaky
+// parallel test table in a debugger without changing the code.
+func Debug() Option {
+	return Policies(&debugPolicy{})
+}
+
+// debugPolicy is the policy to enable the sequential debug mode.
+type debugPolicy struct{}
+
+// Setup enables the sequential debug mode.
+func (*debugPolicy) Setup() {
+	debugger.enable()
+}
+
+// Verify disables the sequential debug mode.
+func (*debugPolicy) Verify() error {
+	debugger.disable()
+	return nil
+}
+
+// Timeout returns the given timeout or - in debug mode - an infinite timeout,
+// to prevent test deadlines from expiring while the test is stopped in a
+// debugger. The test binary timeout can only be disabled by `Main`, so that
+// without `Main` a hint to use `go test -timeout=0` is printed once.
+func Timeout(timeout time.Duration) time.Duration {
+	if debugger.enabled() {
+		debugger.hint()
+		return math.MaxInt64
+	}
+	return timeout
+}
+
+// debugFlag is the flag value to enable the sequential debug mode.
+type debugFlag struct {
+	atomic.Bool
+}
+
+// String returns the flag value as string.
+func (f *debugFlag) String() string {
+	return strconv.FormatBool(f.Load())
+}
+
+// Set sets the flag value from the given string.
+func (f *debugFlag) Set(value string) error {
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return err
+	}
+	f.Store(enabled)
+	return nil
+}
+
+// IsBoolFlag signals that the flag does not require a value.
+func (*debugFlag) IsBoolFlag() bool {
+	return true
+}
+
+// debugConfig is the package-wide configuration of the sequential debug mode.
+type debugConfig struct {
+	mu       gosync.Mutex
+	active   bool
+	started  bool
+	flag     debugFlag
+	register gosync.Once
+	hinted   gosync.Once
+	output   io.Writer
+}
+
+// debugger is the package-wide sequential debug mode configuration.
+var debugger = &debugConfig{output: os.Stdout}
+
+// setup registers the debug flag once, if it is not registered yet. It is
+// called by `Main` before parsing the test binary flags.
+func (c *debugConfig) setup() {
+	c.register.Do(func() {
+		if flag.Lookup(DebugFlag) == nil {
+			flag.Var(&c.flag, DebugFlag, "enable the sequential debug mode")
+		}
+	})
+}
+
+// enable enables the sequential debug mode.
+func (c *debugConfig) enable() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.active = true
+}
+
+// start reports the sequential debug mode and disables the test timeout, if
+// the debug mode is enabled by policy, flag, or environment variable. Since
+// the test timeout is set up when the tests are started, it can only be
+// disabled by `Main` before running the tests.
+func (c *debugConfig) start() {
+	if !c.enabled() {
+		return
+	}
+
+	if flag.Lookup("test.timeout") != nil {
+		_ = flag.Set("test.timeout", "0")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.started = true
+	fmt.Fprintf(c.output, "test: debug mode enabled\n")
+}
+
+// hint prints a hint to disable the test binary timeout once, if the debug
+// mode is enabled without `Main` and the test timeout is still active.
+func (c *debugConfig) hint() {
+	c.mu.Lock()
+	started := c.started
+	c.mu.Unlock()
+	if started {
+		return
+	}
+
+	timeout := flag.Lookup("test.timeout")
+	if timeout == nil || timeout.Value.String() == "0s" {
+		return
+	}
+	c.hinted.Do(func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		fmt.Fprintf(c.output, "test: debug mode without test.Main, "+
+			"disable the test timeout via `go test -timeout=0`\n")
+	})
+}
+
+// disable disables the sequential debug mode.
+func (c *debugConfig) disable() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.active = false
+}
+
+// enabled returns whether the sequential debug mode is enabled by policy,
+// flag, or environment variable.
+func (c *debugConfig) enabled() bool {
+	if c.flag.Load() {
+		return true
+	}
+	switch os.Getenv(DebugEnv) {
+	case "on", "true":
+		return true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.active
+}
+
+// parallel returns whether a test is supposed to run in parallel, i.e. the
+// given parallel flag, as long as the debug mode is not enabled.
+func (c *debugConfig) parallel(parallel bool) bool {
+	return parallel && !c.enabled()
+}
+
+// event prints the given isolation lifecycle event of the test with the given
+// name, if the debug mode is enabled.
+func (c *debugConfig) event(name string, format string, args ...any) {
+	if c.enabled() {
+		c.hint()
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		fmt.Fprintf(c.output, "test: debug %s: %s\n",
+			name, fmt.Sprintf(format, args...))
+	}
+}
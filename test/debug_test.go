@@ -0,0 +1,104 @@
+package test_test
+
+import (
+	"flag"
+	"math"
+	gosync "sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tkrop/go-testing/test"
+)
+
+// debugParams are the test parameter sets used to check the debug mode.
+var debugParams = map[string]int{
+	"a": 0, "b": 1, "c": 2, "d": 3,
+}
+
+// debugRuns runs the debug parameter sets with the given options and returns
+// the order of the executed test cases and the end of the test runner, i.e.
+// `-` in the order, as well as the timeout provided while running the tests.
+// Parallel test cases are executed after the test runner has finished.
+func debugRuns(t *testing.T, opts ...test.Option) (string, time.Duration) {
+	order, timeout := "", time.Duration(0)
+	mu := gosync.Mutex{}
+	test.RunMain(MainFunc(func() int {
+		t.Run("group", func(t *testing.T) {
+			t.Run("map", func(t *testing.T) {
+				test.Map(t, debugParams).
+					Run(func(t test.Test, param int) {
+						mu.Lock()
+						defer mu.Unlock()
+						order += "x"
+					})
+				mu.Lock()
+				defer mu.Unlock()
+				order += "-"
+			})
+		})
+		timeout = test.Timeout(time.Second)
+		return 0
+	}), opts...)
+	return order, timeout
+}
+
+func TestDebug(t *testing.T) {
+	// When
+	order, timeout := debugRuns(t, test.Debug())
+
+	// Then
+	assert.Equal(t, "xxxx-", order)
+	assert.Equal(t, time.Duration(math.MaxInt64), timeout)
+	assert.Equal(t, time.Second, test.Timeout(time.Second))
+}
+
+func TestDebugDisabled(t *testing.T) {
+	// When
+	order, timeout := debugRuns(t)
+
+	// Then
+	assert.Equal(t, "-xxxx", order)
+	assert.Equal(t, time.Second, timeout)
+}
+
+// resetTimeout restores the test timeout flag on test cleanup.
+func resetTimeout(t *testing.T) {
+	timeout := flag.Lookup("test.timeout").Value.String()
+	t.Cleanup(func() {
+		assert.NoError(t, flag.Set("test.timeout", timeout))
+	})
+}
+
+func TestDebugEnv(t *testing.T) {
+	// Given
+	resetTimeout(t)
+	t.Setenv(test.DebugEnv, "on")
+
+	// When
+	order, timeout := debugRuns(t)
+
+	// Then
+	assert.Equal(t, "xxxx-", order)
+	assert.Equal(t, time.Duration(math.MaxInt64), timeout)
+	assert.Equal(t, "0s", flag.Lookup("test.timeout").Value.String())
+}
+
+func TestDebugFlag(t *testing.T) {
+	// Given
+	resetTimeout(t)
+	test.RunMain(MainFunc(func() int { return 0 }))
+	assert.NoError(t, flag.Set(test.DebugFlag, "true"))
+	t.Cleanup(func() {
+		assert.NoError(t, flag.Set(test.DebugFlag, "false"))
+	})
+
+	// When
+	order, timeout := debugRuns(t)
+
+	// Then
+	assert.Equal(t, "xxxx-", order)
+	assert.Equal(t, time.Duration(math.MaxInt64), timeout)
+	assert.Equal(t, "0s", flag.Lookup("test.timeout").Value.String())
+}
@@ -0,0 +1,284 @@
+package test
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	gosync "sync"
+	"sync/atomic"
+
+	"github.com/tkrop/go-testing/internal/reflect"
+	"github.com/tkrop/go-testing/mock"
+)
+
+// setupFuncType is the type of mock setup functions in test parameter sets.
+var setupFuncType = reflect.TypeOf(mock.SetupFunc(nil))
+
+// Documentation adds a policy to run the parameterized tests of the package in
+// documentation mode. In documentation mode, the test case names, inputs,
+// expected outputs, and mock interactions of the test parameter sets are
+// extracted while running the tests and written as Markdown table per test
+// function into the given directory after all tests have finished.
+func Documentation(dir string) Option {
+	return Policies(&docPolicy{dir: dir})
+}
+
+// docPolicy is the policy to write the documentation of the test parameter
+// sets after all tests have finished.
+type docPolicy struct {
+	dir string
+}
+
+// Setup enables the documentation mode.
+func (p *docPolicy) Setup() {
+	documentation.enable()
+}
+
+// Verify writes the documentation of the recorded test parameter sets.
+func (p *docPolicy) Verify() error {
+	tables := documentation.disable()
+	if err := os.MkdirAll(p.dir, 0o755); err != nil {
+		return err
+	}
+	for _, table := range tables {
+		name := strings.ReplaceAll(table.name, "/", "_") + ".md"
+		file, err := os.Create(filepath.Join(p.dir, name))
+		if err != nil {
+			return err
+		}
+		err = table.Markdown(file)
+		if cerr := file.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DocTable is the documentation table of the test parameter sets of a test
+// function.
+type DocTable struct {
+	name    string
+	columns []string
+	rows    map[string][]string
+}
+
+// NewDocTable creates a new documentation table for the test function with
+// given name.
+func NewDocTable(name string) *DocTable {
+	return &DocTable{name: name, rows: map[string][]string{}}
+}
+
+// Add adds the test parameter set with given test case name to the table. The
+// fields of the parameter set are documented as follows: fields starting with
+// `expect` as expected outputs, mock setup functions as mock interactions, and
+// all other fields - except for the test case name and other functions - as
+// inputs.
+func (d *DocTable) Add(name string, param any) {
+	columns, row := docRow(name, param)
+	d.add(name, columns, row)
+}
+
+// add adds the given documentation row with given test case name and columns
+// to the table.
+func (d *DocTable) add(name string, columns, row []string) {
+	if len(columns) > len(d.columns) {
+		d.columns = columns
+	}
+	d.rows[name] = row
+}
+
+// docRow creates the documentation columns and row of the test parameter set
+// with given test case name.
+func docRow(name string, param any) ([]string, []string) {
+	value := reflect.ValueOf(param)
+	for value.Kind() == reflect.Pointer && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	columns, row := []string{"Case"}, []string{name}
+	if value.Kind() != reflect.Struct {
+		columns = append(columns, "Parameter")
+		row = append(row, format(reflect.ArgOf(value)))
+	} else {
+		inputs, outputs, mocks := docFields(value)
+		for _, field := range append(inputs, outputs...) {
+			columns = append(columns, field.name)
+			row = append(row, format(reflect.FieldArgOf(value, field.index)))
+		}
+		if len(mocks) != 0 {
+			calls := []string{}
+			for _, field := range mocks {
+				calls = append(calls,
+					describe(reflect.FieldArgOf(value, field.index))...)
+			}
+			columns = append(columns, "Mock interactions")
+			row = append(row, strings.Join(calls, "\n"))
+		}
+	}
+	return columns, row
+}
+
+// Markdown writes the documentation table in Markdown to the given writer.
+func (d *DocTable) Markdown(w io.Writer) error {
+	names := make([]string, 0, len(d.rows))
+	for name := range d.rows {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	builder := &strings.Builder{}
+	fmt.Fprintf(builder, "# %s\n\n", d.name)
+	fmt.Fprintf(builder, "| %s |\n", strings.Join(d.columns, " | "))
+	builder.WriteString("|")
+	for range d.columns {
+		builder.WriteString("---|")
+	}
+	builder.WriteString("\n")
+	for _, name := range names {
+		row := d.rows[name]
+		cells := make([]string, len(d.columns))
+		for index := range cells {
+			if index < len(row) {
+				cells[index] = cell(row[index])
+			}
+		}
+		fmt.Fprintf(builder, "| %s |\n", strings.Join(cells, " | "))
+	}
+
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+// docField is a field of a test parameter set to document.
+type docField struct {
+	name  string
+	index int
+}
+
+// docFields returns the input, expected output, and mock setup fields of the
+// given test parameter set.
+func docFields(value reflect.Value) (inputs, outputs, mocks []docField) {
+	vtype := value.Type()
+	for index := 0; index < vtype.NumField(); index++ {
+		field := vtype.Field(index)
+		switch {
+		case field.Type.ConvertibleTo(setupFuncType):
+			mocks = append(mocks, docField{name: field.Name, index: index})
+		case field.Name == "name" || field.Type.Kind() == reflect.Func:
+		case strings.HasPrefix(field.Name, "expect"):
+			outputs = append(outputs, docField{name: field.Name, index: index})
+		default:
+			inputs = append(inputs, docField{name: field.Name, index: index})
+		}
+	}
+	return inputs, outputs, mocks
+}
+
+// format formats the given field argument for documentation.
+func format(arg any) string {
+	switch arg := arg.(type) {
+	case nil:
+		return ""
+	case Expect:
+		if arg == Success {
+			return "success"
+		}
+		return "failure"
+	case error:
+		return arg.Error()
+	}
+
+	value := reflect.ValueOf(arg)
+	for value.Kind() == reflect.Pointer && !value.IsNil() {
+		value = value.Elem()
+	}
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice:
+		if value.IsNil() {
+			return ""
+		}
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// describe describes the mock interactions of the given mock setup function.
+func describe(arg any) []string {
+	value := reflect.ValueOf(arg)
+	if !value.IsValid() || value.IsNil() {
+		return nil
+	}
+	return mock.Describe(value.Convert(setupFuncType).
+		Interface().(mock.SetupFunc))
+}
+
+// cell escapes the given text to be used in a Markdown table cell.
+func cell(text string) string {
+	text = strings.ReplaceAll(text, "|", "\\|")
+	return strings.ReplaceAll(text, "\n", "<br>")
+}
+
+// docCollector is the collector of documentation tables.
+type docCollector struct {
+	mu      gosync.Mutex
+	enabled atomic.Bool
+	tables  map[string]*DocTable
+}
+
+// documentation is the package-wide documentation collector.
+var documentation = &docCollector{}
+
+// enable enables the documentation mode.
+func (c *docCollector) enable() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tables = map[string]*DocTable{}
+	c.enabled.Store(true)
+}
+
+// disable disables the documentation mode and returns the collected tables
+// sorted by test name.
+func (c *docCollector) disable() []*DocTable {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tables := make([]*DocTable, 0, len(c.tables))
+	for _, table := range c.tables {
+		tables = append(tables, table)
+	}
+	sort.Slice(tables, func(i, j int) bool {
+		return tables[i].name < tables[j].name
+	})
+	c.enabled.Store(false)
+	c.tables = nil
+	return tables
+}
+
+// record records the test parameter set with given test case name for the
+// test function with given name, if the documentation mode is enabled. The
+// documentation row is created without holding the lock, since it runs the
+// mock setup functions of the parameter set.
+func (c *docCollector) record(test, name string, param any) {
+	if !c.enabled.Load() {
+		return
+	}
+	columns, row := docRow(name, param)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.tables == nil {
+		return
+	}
+
+	table, ok := c.tables[test]
+	if !ok {
+		table = NewDocTable(test)
+		c.tables[test] = table
+	}
+	table.add(name, columns, row)
+}
@@ -0,0 +1,142 @@
+package test_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tkrop/go-testing/mock"
+	"github.com/tkrop/go-testing/test"
+)
+
+// DocParams is a test parameter set to document.
+type DocParams struct {
+	name        test.Name
+	setup       mock.SetupFunc
+	call        func()
+	input       string
+	count       *int
+	expect      test.Expect
+	expectError error
+}
+
+type DocTableParams struct {
+	params       map[string]any
+	expectOutput string
+}
+
+// count is a count input value.
+var count = 2
+
+var testDocTableParams = map[string]DocTableParams{
+	"struct params": {
+		params: map[string]any{
+			"success": DocParams{
+				input:  "a|b",
+				count:  &count,
+				expect: test.Success,
+			},
+			"failure": DocParams{
+				setup: mock.Chain(
+					test.Errorf("fail"),
+					test.Fatalf("%s", "abort"),
+				),
+				input:       "line\nbreak",
+				expectError: errors.New("failed"),
+			},
+		},
+		expectOutput: "# TestUnit\n\n" +
+			"| Case | input | count | expect | expectError | Mock interactions |\n" +
+			"|---|---|---|---|---|---|\n" +
+			"| failure | line<br>break |  | failure | failed | " +
+			"*test.Validator.Errorf(is equal to fail (string))<br>" +
+			"*test.Validator.Fatalf(is equal to %s (string), " +
+			"is equal to abort (string)) |\n" +
+			"| success | a\\|b | 2 | success |  |  |\n",
+	},
+	"simple params": {
+		params: map[string]any{
+			"first":  1,
+			"second": &count,
+		},
+		expectOutput: "# TestUnit\n\n" +
+			"| Case | Parameter |\n" +
+			"|---|---|\n" +
+			"| first | 1 |\n" +
+			"| second | 2 |\n",
+	},
+	"invalid setup": {
+		params: map[string]any{
+			"invalid": DocParams{
+				setup: func(*mock.Mocks) any { panic("invalid") },
+			},
+		},
+		expectOutput: "# TestUnit\n\n" +
+			"| Case | input | count | expect | expectError | Mock interactions |\n" +
+			"|---|---|---|---|---|---|\n" +
+			"| invalid |  |  | failure |  | invalid mock setup: invalid |\n",
+	},
+}
+
+func TestDocTable(t *testing.T) {
+	test.Map(t, testDocTableParams).
+		Run(func(t test.Test, param DocTableParams) {
+			// Given
+			table := test.NewDocTable("TestUnit")
+			for name, param := range param.params {
+				table.Add(name, param)
+			}
+			builder := &strings.Builder{}
+
+			// When
+			err := table.Markdown(builder)
+
+			// Then
+			require.NoError(t, err)
+			assert.Equal(t, param.expectOutput, builder.String())
+		})
+}
+
+func TestDocumentation(t *testing.T) {
+	// Given
+	dir := filepath.Join(t.TempDir(), "docs")
+
+	// When
+	code := test.RunMain(MainFunc(func() int {
+		t.Run("TestUnit", func(t *testing.T) {
+			test.Map(t, map[string]DocParams{
+				"case": {input: "value", expect: test.Success},
+			}).RunSeq(func(t test.Test, param DocParams) {})
+		})
+		return 0
+	}), test.Documentation(dir))
+
+	// Then
+	assert.Equal(t, 0, code)
+	data, err := os.ReadFile(filepath.Join(dir, "TestDocumentation_TestUnit.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# TestDocumentation/TestUnit\n\n"+
+		"| Case | input | count | expect | expectError | Mock interactions |\n"+
+		"|---|---|---|---|---|---|\n"+
+		"| case | value |  | success |  |  |\n", string(data))
+}
+
+func TestDocumentationError(t *testing.T) {
+	// Given
+	file := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(file, []byte{}, 0o600))
+	builder := &strings.Builder{}
+
+	// When
+	code := test.RunMain(MainFunc(func() int { return 0 }),
+		test.Output(builder), test.Documentation(filepath.Join(file, "docs")))
+
+	// Then
+	assert.Equal(t, 1, code)
+	assert.Contains(t, builder.String(), "FAIL: mkdir")
+}
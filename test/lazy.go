@@ -0,0 +1,132 @@
+package test
+
+import (
+	"fmt"
+	gosync "sync"
+
+	"github.com/tkrop/go-testing/internal/reflect"
+	"github.com/tkrop/go-testing/mock"
+)
+
+// Lazy is a lazy parameter value that is resolved per test case, e.g. to
+// provide values depending on the test like temporary directories, mocks, or
+// fixture URLs in parameter sets created at package initialization. Lazy
+// fields of a parameter set are resolved by the test runner at the start of
+// each test case, so that the test function receives lazy values that always
+// return the resolved value.
+type Lazy[T any] func(t Test) T
+
+// resolver is the interface to resolve lazy parameter values.
+type resolver interface {
+	// resolve resolves the lazy value for the given test returning a lazy
+	// value that always returns the resolved value.
+	resolve(t Test) any
+}
+
+// resolve resolves the lazy value for the given test returning a lazy value
+// that always returns the resolved value.
+func (l Lazy[T]) resolve(t Test) any {
+	if l == nil {
+		return l
+	}
+	value := l(t)
+	return Lazy[T](func(Test) T { return value })
+}
+
+// lazyEntry is the entry of a lazy value resolved once per test case.
+type lazyEntry[T any] struct {
+	// once ensures that the value is resolved only once.
+	once gosync.Once
+	// value the resolved value.
+	value T
+}
+
+// lazyValues the lazy values resolved per test case.
+var lazyValues gosync.Map
+
+// NewLazy creates a lazy value that is resolved at most once per test case
+// using the given resolve function, i.e. all usages of the lazy value in
+// parameter sets, mock setup functions, and expectations of a test case share
+// the same value. The resolved values are released on test cleanup.
+func NewLazy[T any](resolve func(t Test) T) Lazy[T] {
+	key := new(int)
+	return func(t Test) T {
+		values := valuesOf(t)
+		if values == nil {
+			return resolve(t)
+		}
+
+		actual, _ := values.LoadOrStore(key, &lazyEntry[T]{})
+		entry := actual.(*lazyEntry[T])
+		entry.once.Do(func() { entry.value = resolve(t) })
+		return entry.value
+	}
+}
+
+// LazyMap creates a lazy value by mapping the value of the given lazy value
+// using the given mapping function, e.g. to derive expectations from a lazy
+// fixture URL.
+func LazyMap[T, R any](lazy Lazy[T], mapping func(T) R) Lazy[R] {
+	return func(t Test) R {
+		return mapping(lazy(t))
+	}
+}
+
+// LazySetup creates a mock setup function from the given lazy mock setup
+// function that is resolved with the test of the mock handler, e.g. to use
+// lazy values as arguments or results of mock calls.
+func LazySetup(setup Lazy[mock.SetupFunc]) mock.SetupFunc {
+	return func(mocks *mock.Mocks) any {
+		t, ok := mocks.T().(Test)
+		if !ok {
+			panic(ErrNoTest(mocks.T()))
+		}
+		if fncall := setup(t); fncall != nil {
+			return fncall(mocks)
+		}
+		return nil
+	}
+}
+
+// valuesOf returns the lazy values of the test case of the given test. If the
+// test does not support cleanup, nil is returned.
+func valuesOf(t Test) *gosync.Map {
+	for {
+		tester, ok := t.(*Tester)
+		if !ok {
+			break
+		}
+		t = tester.t
+	}
+
+	if values, ok := lazyValues.Load(t); ok {
+		return values.(*gosync.Map)
+	}
+	cleaner, ok := t.(Cleanuper)
+	if !ok {
+		return nil
+	}
+	values, loaded := lazyValues.LoadOrStore(t, &gosync.Map{})
+	if !loaded {
+		cleaner.Cleanup(func() { lazyValues.Delete(t) })
+	}
+	return values.(*gosync.Map)
+}
+
+// resolveParam resolves all lazy fields of the given parameter set or pointer
+// to a parameter set for the given test. Parameter sets without lazy fields
+// are returned unchanged.
+func resolveParam[P any](t Test, param P) P {
+	return reflect.MapArgsOf(param, func(arg any) (any, bool) {
+		if lazy, ok := arg.(resolver); ok {
+			return lazy.resolve(t), true
+		}
+		return nil, false
+	})
+}
+
+// ErrNoTest creates an error that the given test reporter of a mock handler
+// does not support the test interface to resolve lazy values.
+func ErrNoTest(reporter any) error {
+	return fmt.Errorf("test reporter not supported [type=%T]", reporter)
+}
@@ -0,0 +1,163 @@
+package test_test
+
+import (
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tkrop/go-testing/mock"
+	"github.com/tkrop/go-testing/test"
+)
+
+// sequence is the sequence to create distinct lazy values.
+var sequence atomic.Int64
+
+// lazyName is a lazy value shared by all usages in a test case.
+var lazyName = test.NewLazy(func(t test.Test) string {
+	return t.Name() + "#" + strconv.FormatInt(sequence.Add(1), 10)
+})
+
+type LazyParams struct {
+	value  test.Lazy[string]
+	other  test.Lazy[string]
+	setup  mock.SetupFunc
+	test   func(t test.Test, param LazyParams)
+	expect test.Expect
+}
+
+var testLazyParams = map[string]LazyParams{
+	"nil value": {
+		test: func(t test.Test, param LazyParams) {
+			assert.Nil(t, param.value)
+		},
+		expect: test.Success,
+	},
+	"lazy value": {
+		value: func(t test.Test) string { return t.Name() },
+		test: func(t test.Test, param LazyParams) {
+			assert.Equal(t, t.Name(), param.value(t))
+		},
+		expect: test.Success,
+	},
+	"lazy value resolved once": {
+		value: func(test.Test) string {
+			return strconv.FormatInt(sequence.Add(1), 10)
+		},
+		test: func(t test.Test, param LazyParams) {
+			assert.Equal(t, param.value(t), param.value(t))
+		},
+		expect: test.Success,
+	},
+	"new lazy value shared": {
+		value: lazyName,
+		other: test.LazyMap(lazyName, strings.ToUpper),
+		test: func(t test.Test, param LazyParams) {
+			assert.True(t, strings.HasPrefix(param.value(t), t.Name()+"#"))
+			assert.Equal(t, strings.ToUpper(param.value(t)), param.other(t))
+			assert.Equal(t, param.value(t), lazyName(t))
+		},
+		expect: test.Success,
+	},
+	"lazy setup": {
+		value: lazyName,
+		setup: test.LazySetup(func(t test.Test) mock.SetupFunc {
+			return test.Errorf("%s", lazyName(t))
+		}),
+		test: func(t test.Test, param LazyParams) {
+			t.Errorf("%s", param.value(t))
+		},
+		expect: test.Failure,
+	},
+	"lazy setup nil": {
+		setup: test.LazySetup(func(test.Test) mock.SetupFunc {
+			return nil
+		}),
+		test:   func(test.Test, LazyParams) {},
+		expect: test.Success,
+	},
+}
+
+func TestLazy(t *testing.T) {
+	test.Map(t, testLazyParams).
+		Run(func(t test.Test, param LazyParams) {
+			// Given
+			mocks := mock.NewMock(t).Expect(param.setup)
+
+			// When
+			param.test(t, param)
+
+			// Then
+			mocks.Wait()
+		})
+}
+
+var testLazyPointerParams = map[string]*LazyParams{
+	"nil value": {
+		test: func(t test.Test, param LazyParams) {
+			assert.Nil(t, param.value)
+		},
+	},
+	"lazy value": {
+		value: func(t test.Test) string { return t.Name() },
+		test: func(t test.Test, param LazyParams) {
+			assert.Equal(t, t.Name(), param.value(t))
+		},
+	},
+}
+
+func TestLazyPointer(t *testing.T) {
+	test.Map(t, testLazyPointerParams).
+		Run(func(t test.Test, param *LazyParams) {
+			// When
+			param.test(t, *param)
+		})
+}
+
+func TestNewLazy(t *testing.T) {
+	// Given
+	values := map[string]string{}
+
+	// When
+	for _, name := range []string{"a", "b"} {
+		t.Run(name, func(t *testing.T) {
+			values[name] = lazyName(t)
+			assert.Equal(t, values[name],
+				lazyName(test.NewTester(t, test.Success)))
+		})
+	}
+
+	// Then
+	assert.NotEqual(t, values["a"], values["b"])
+	assert.NotEqual(t, lazyName(reporter{}), lazyName(reporter{}))
+}
+
+func TestLazySetupPanic(t *testing.T) {
+	// Given
+	mocks := mock.NewMock(&failer{})
+	defer func() {
+		// Then
+		assert.Equal(t, test.ErrNoTest(&failer{}), recover())
+	}()
+
+	// When
+	test.LazySetup(func(test.Test) mock.SetupFunc { return nil })(mocks)
+}
+
+// failer is a minimal test reporter not supporting the test interface.
+type failer struct{}
+
+func (*failer) Helper()               {}
+func (*failer) Errorf(string, ...any) {}
+func (*failer) Fatalf(string, ...any) {}
+
+// reporter is a minimal test not supporting cleanup.
+type reporter struct{}
+
+func (reporter) Helper()               {}
+func (reporter) Name() string          { return "reporter" }
+func (reporter) Errorf(string, ...any) {}
+func (reporter) Fatalf(string, ...any) {}
+func (reporter) FailNow()              {}
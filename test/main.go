@@ -0,0 +1,354 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	gosync "sync"
+	"time"
+)
+
+// UpdateEnv is the environment variable to enable the update mode of golden
+// files, e.g. of scripts or interaction traces, that replaces the expected
+// content with the actual content instead of failing.
+const UpdateEnv = "TEST_UPDATE"
+
+// M is a minimal interface for abstracting the package test main, i.e.
+// `testing.M`, that is needed to run the tests of a package.
+type M interface {
+	Run() int
+}
+
+// Policy is a package-wide test policy that is set up before running the
+// tests of a package and verified after all tests have finished.
+type Policy interface {
+	// Setup sets up the policy before running the tests.
+	Setup()
+	// Verify verifies the policy after running the tests and returns an error
+	// if the policy was violated.
+	Verify() error
+}
+
+// Option is an option to configure the package-wide test policies of `Main`.
+type Option func(*config)
+
+// config is the configuration of the package-wide test policies.
+type config struct {
+	policies []Policy
+	output   io.Writer
+}
+
+// Main runs the tests of a package using the given options to configure the
+// package-wide test policies and exits with the resulting exit code. It is
+// supposed to be called from `TestMain`:
+//
+//	func TestMain(m *testing.M) {
+//		test.Main(m, test.Goroutines(), test.LateFailures())
+//	}
+func Main(m M, opts ...Option) {
+	os.Exit(RunMain(m, opts...))
+}
+
+// RunMain runs the tests of a package using the given options to configure
+// the package-wide test policies and returns the resulting exit code. The
+// policies are set up before running the tests and verified afterwards. If a
+// policy is violated, the violation is reported and the exit code is set to
+// failure.
+func RunMain(m M, opts ...Option) int {
+	config := &config{output: os.Stderr}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	// The test flags are parsed before running the tests to enable the debug
+	// mode via flag before the test timeout is set up.
+	debugger.setup()
+	if !flag.Parsed() {
+		flag.Parse()
+	}
+	for _, policy := range config.policies {
+		policy.Setup()
+	}
+	debugger.start()
+	shuffler.start(config.output)
+
+	code := m.Run()
+
+	for _, policy := range config.policies {
+		if err := policy.Verify(); err != nil {
+			fmt.Fprintf(config.output, "FAIL: %v\n", err)
+			if code == 0 {
+				code = 1
+			}
+		}
+	}
+	return code
+}
+
+// Output sets the writer to report policy violations. The default writer is
+// `os.Stderr`.
+func Output(w io.Writer) Option {
+	return func(config *config) {
+		config.output = w
+	}
+}
+
+// Policies adds the given custom policies to the package-wide test policies.
+func Policies(policies ...Policy) Option {
+	return func(config *config) {
+		config.policies = append(config.policies, policies...)
+	}
+}
+
+// Goroutines adds a policy to detect go-routines that are leaked by the tests
+// of the package, i.e. go-routines that were created while running the tests
+// and are still running after all tests have finished. Go-routines with a
+// stack trace matching any of the given regular expressions are ignored.
+func Goroutines(ignore ...string) Option {
+	return Policies(NewGoroutinePolicy(time.Second, ignore...))
+}
+
+// LateFailures adds a policy to detect failures that are reported by an
+// isolated test after it has finished, e.g. late mock calls of detached
+// go-routines. Instead of crashing the test run, late failures are recorded
+// and reported after all tests have finished.
+func LateFailures() Option {
+	return Policies(lateFailures)
+}
+
+// Network adds a policy to guard the tests against network access via the
+// default HTTP transport. Connections to hosts other than the loopback
+// interface and the given allowed hosts are rejected and reported.
+func Network(allow ...string) Option {
+	return Policies(NewNetworkPolicy(allow...))
+}
+
+// GoroutinePolicy is a policy to detect leaked go-routines.
+type GoroutinePolicy struct {
+	grace  time.Duration
+	ignore []*regexp.Regexp
+	before map[string]bool
+}
+
+// NewGoroutinePolicy creates a new policy to detect leaked go-routines that
+// are still running after the given grace period. Go-routines with a stack
+// trace matching any of the given regular expressions are ignored.
+func NewGoroutinePolicy(
+	grace time.Duration, ignore ...string,
+) *GoroutinePolicy {
+	policy := &GoroutinePolicy{grace: grace}
+	for _, pattern := range ignore {
+		policy.ignore = append(policy.ignore, regexp.MustCompile(pattern))
+	}
+	return policy
+}
+
+// Setup records the go-routines running before the tests.
+func (p *GoroutinePolicy) Setup() {
+	p.before = map[string]bool{}
+	for id := range goroutines() {
+		p.before[id] = true
+	}
+}
+
+// Verify checks for go-routines that were created while running the tests
+// and are still running after the grace period.
+func (p *GoroutinePolicy) Verify() error {
+	deadline := time.Now().Add(p.grace)
+	for {
+		leaked := p.leaked()
+		if len(leaked) == 0 {
+			return nil
+		} else if time.Now().After(deadline) {
+			return ErrGoroutines(leaked)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// leaked returns the sorted stack traces of the leaked go-routines.
+func (p *GoroutinePolicy) leaked() []string {
+	leaked := []string{}
+	for id, stack := range goroutines() {
+		if !p.before[id] && !p.ignored(stack) {
+			leaked = append(leaked, stack)
+		}
+	}
+	sort.Strings(leaked)
+	return leaked
+}
+
+// ignored returns whether the go-routine with given stack trace is ignored.
+func (p *GoroutinePolicy) ignored(stack string) bool {
+	for _, ignore := range p.ignore {
+		if ignore.MatchString(stack) {
+			return true
+		}
+	}
+	return false
+}
+
+// goroutines returns the stack traces of all go-routines except the current
+// go-routine mapped by their go-routine identifier.
+func goroutines() map[string]string {
+	buffer := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buffer, true)
+		if n < len(buffer) {
+			buffer = buffer[:n]
+			break
+		}
+		buffer = make([]byte, 2*len(buffer))
+	}
+
+	stacks := map[string]string{}
+	for i, stack := range strings.Split(string(buffer), "\n\n") {
+		if i == 0 {
+			continue // skip the current go-routine.
+		}
+		header, _, _ := strings.Cut(stack, " [")
+		stacks[strings.TrimPrefix(header, "goroutine ")] = stack
+	}
+	return stacks
+}
+
+// NetworkPolicy is a policy to guard tests against network access via the
+// default HTTP transport.
+type NetworkPolicy struct {
+	mu        gosync.Mutex
+	allow     map[string]bool
+	transport *http.Transport
+	dial      func(context.Context, string, string) (net.Conn, error)
+	blocked   []string
+}
+
+// NewNetworkPolicy creates a new policy to guard tests against network access
+// via the default HTTP transport allowing access to the loopback interface
+// and the given hosts.
+func NewNetworkPolicy(allow ...string) *NetworkPolicy {
+	policy := &NetworkPolicy{allow: map[string]bool{"localhost": true}}
+	for _, host := range allow {
+		policy.allow[host] = true
+	}
+	return policy
+}
+
+// Setup installs the network guard as dialer of the default HTTP transport.
+// Since the guard is installed in the transport instance, it is not lost when
+// the default HTTP transport is replaced and restored, e.g. by `gock.Off`.
+func (p *NetworkPolicy) Setup() {
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return
+	}
+
+	p.transport, p.dial = transport, transport.DialContext
+	if p.dial == nil {
+		p.dial = (&net.Dialer{}).DialContext
+	}
+	transport.DialContext = p.DialContext
+}
+
+// Verify restores the dialer of the default HTTP transport and reports the
+// blocked network access.
+func (p *NetworkPolicy) Verify() error {
+	if p.transport != nil {
+		p.transport.DialContext = p.dial
+		p.transport.CloseIdleConnections()
+		p.transport = nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.blocked) != 0 {
+		return ErrNetwork(p.blocked)
+	}
+	return nil
+}
+
+// DialContext delegates connections to allowed hosts to the original dialer of
+// the default HTTP transport and rejects all other connections.
+func (p *NetworkPolicy) DialContext(
+	ctx context.Context, network, address string,
+) (net.Conn, error) {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+	}
+	if ip := net.ParseIP(host); (ip != nil && ip.IsLoopback()) || p.allow[host] {
+		return p.dial(ctx, network, address)
+	}
+
+	p.mu.Lock()
+	p.blocked = append(p.blocked, network+" "+address)
+	p.mu.Unlock()
+	return nil, ErrNetwork([]string{network + " " + address})
+}
+
+// latePolicy is a policy to record and report late failures.
+type latePolicy struct {
+	mu       gosync.Mutex
+	enabled  bool
+	failures []string
+}
+
+// lateFailures is the package-wide late failure policy.
+var lateFailures = &latePolicy{}
+
+// Setup enables recording of late failures.
+func (p *latePolicy) Setup() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.enabled = true
+}
+
+// Verify reports the recorded late failures.
+func (p *latePolicy) Verify() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.enabled = false
+	if len(p.failures) != 0 {
+		failures := p.failures
+		p.failures = nil
+		return ErrLateFailures(failures)
+	}
+	return nil
+}
+
+// record records the given late failure of the test with given name, if
+// recording is enabled.
+func (p *latePolicy) record(name, msg string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.enabled {
+		p.failures = append(p.failures, name+": "+msg)
+	}
+	return p.enabled
+}
+
+// ErrGoroutines creates an error reporting leaked go-routines with given
+// stack traces.
+func ErrGoroutines(stacks []string) error {
+	return errors.New("leaked go-routines:\n" + strings.Join(stacks, "\n\n"))
+}
+
+// ErrNetwork creates an error reporting blocked network requests.
+func ErrNetwork(requests []string) error {
+	return errors.New("blocked network access: " + strings.Join(requests, ", "))
+}
+
+// ErrLateFailures creates an error reporting failures of tests that were
+// reported after the tests have finished.
+func ErrLateFailures(failures []string) error {
+	return errors.New("late failures after test finished:\n\t" +
+		strings.Join(failures, "\n\t"))
+}
@@ -0,0 +1,219 @@
+package test_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+
+	"github.com/tkrop/go-testing/internal/sync"
+	"github.com/tkrop/go-testing/test"
+)
+
+// MainFunc is a test main function.
+type MainFunc func() int
+
+// Run runs the test main function.
+func (m MainFunc) Run() int {
+	return m()
+}
+
+// PolicyStub is a policy stub recording the calls.
+type PolicyStub struct {
+	calls []string
+	err   error
+}
+
+// Setup records the setup call.
+func (p *PolicyStub) Setup() {
+	p.calls = append(p.calls, "setup")
+}
+
+// Verify records the verify call and returns the configured error.
+func (p *PolicyStub) Verify() error {
+	p.calls = append(p.calls, "verify")
+	return p.err
+}
+
+type RunMainParams struct {
+	code         int
+	errs         []error
+	expectCode   int
+	expectOutput string
+}
+
+var testRunMainParams = map[string]RunMainParams{
+	"success": {
+		code:       0,
+		errs:       []error{nil, nil},
+		expectCode: 0,
+	},
+	"tests failing": {
+		code:       1,
+		errs:       []error{nil},
+		expectCode: 1,
+	},
+	"policy violated": {
+		code:         0,
+		errs:         []error{nil, errors.New("violated")},
+		expectCode:   1,
+		expectOutput: "FAIL: violated\n",
+	},
+	"tests and policies failing": {
+		code:       2,
+		errs:       []error{errors.New("first"), errors.New("second")},
+		expectCode: 2,
+		expectOutput: "FAIL: first\n" +
+			"FAIL: second\n",
+	},
+}
+
+func TestRunMain(t *testing.T) {
+	test.Map(t, testRunMainParams).
+		Run(func(t test.Test, param RunMainParams) {
+			// Given
+			policies := []test.Policy{}
+			for _, err := range param.errs {
+				policies = append(policies, &PolicyStub{err: err})
+			}
+			builder := &strings.Builder{}
+
+			// When
+			code := test.RunMain(MainFunc(func() int {
+				for _, policy := range policies {
+					assert.Equal(t, []string{"setup"},
+						policy.(*PolicyStub).calls)
+				}
+				return param.code
+			}), test.Output(builder), test.Policies(policies...))
+
+			// Then
+			assert.Equal(t, param.expectCode, code)
+			assert.Equal(t, param.expectOutput, builder.String())
+			for _, policy := range policies {
+				assert.Equal(t, []string{"setup", "verify"},
+					policy.(*PolicyStub).calls)
+			}
+		})
+}
+
+func TestGoroutinePolicy(t *testing.T) {
+	// Given
+	policy := test.NewGoroutinePolicy(50 * time.Millisecond)
+	policy.Setup()
+	started, done := make(chan struct{}), make(chan struct{})
+	go leakingGoroutine(started, done)
+	<-started
+
+	// When
+	err := policy.Verify()
+
+	// Then
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "leaked go-routines:")
+	assert.Contains(t, err.Error(), "leakingGoroutine")
+
+	// When
+	close(done)
+	policy = test.NewGoroutinePolicy(time.Second)
+	policy.Setup()
+	err = policy.Verify()
+
+	// Then
+	assert.NoError(t, err)
+}
+
+func TestGoroutinePolicyIgnore(t *testing.T) {
+	// Given
+	policy := test.NewGoroutinePolicy(0, "leakingGoroutine")
+	policy.Setup()
+	started, done := make(chan struct{}), make(chan struct{})
+	defer close(done)
+	go leakingGoroutine(started, done)
+	<-started
+
+	// When
+	err := policy.Verify()
+
+	// Then
+	assert.NoError(t, err)
+}
+
+// leakingGoroutine is a go-routine function signaling its start and waiting
+// for the given channel to be closed.
+func leakingGoroutine(started, done chan struct{}) {
+	close(started)
+	<-done
+}
+
+func TestNetworkPolicy(t *testing.T) {
+	// Given
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}))
+	defer server.Close()
+	builder := &strings.Builder{}
+	blocked := []string{"tcp blocked.invalid:80", "tcp restored.invalid:80"}
+
+	// When
+	code := test.RunMain(MainFunc(func() int {
+		resp, err := http.Get(server.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		_, err = http.Get("http://blocked.invalid/path")
+		assert.ErrorContains(t, err,
+			test.ErrNetwork(blocked[:1]).Error())
+
+		gock.Intercept()
+		gock.Off()
+		_, err = http.Get("http://restored.invalid/path")
+		assert.ErrorContains(t, err,
+			test.ErrNetwork(blocked[1:]).Error())
+		return 0
+	}), test.Output(builder), test.Network("allowed.invalid"))
+
+	// Then
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "FAIL: "+test.ErrNetwork(blocked).Error()+"\n",
+		builder.String())
+}
+
+func TestLateFailures(t *testing.T) {
+	// Given
+	builder := &strings.Builder{}
+	var tester *test.Tester
+
+	// When
+	code := test.RunMain(MainFunc(func() int {
+		t.Run("inner", func(t *testing.T) {
+			tester = test.NewTester(t, test.Success)
+			tester.Run(func(test.Test) {}, false)
+		})
+
+		tester.Errorf("late error")
+		wg := sync.NewWaitGroup()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tester.Fatalf("late fatal")
+		}()
+		wg.Wait()
+		return 0
+	}), test.Output(builder), test.LateFailures())
+
+	// Then
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "FAIL: "+test.ErrLateFailures([]string{
+		"TestLateFailures/inner: late error",
+		"TestLateFailures/inner: late fatal",
+	}).Error()+"\n", builder.String())
+}
@@ -0,0 +1,273 @@
+package test
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// Keywords of the scenario steps.
+const (
+	// Given keyword of a step setting up the test context.
+	Given = "Given"
+	// When keyword of a step executing the system under test.
+	When = "When"
+	// Then keyword of a step validating the test results.
+	Then = "Then"
+	// And keyword of a step continuing the previous step.
+	And = "And"
+	// But keyword of a step continuing the previous step.
+	But = "But"
+)
+
+// StepFunc is a scenario step function receiving the test and the shared
+// scenario context.
+type StepFunc[C any] func(t Test, ctx *C)
+
+// Steps is a scenario of named test steps sharing a typed test context.
+type Steps[C any] struct {
+	t   Test
+	ctx *C
+}
+
+// Scenario creates a new scenario for the given test with a new shared typed
+// test context. The steps of the scenario are executed immediately in the
+// order they are added. If the test is an isolated test, failures are
+// attributed to the current step by prefixing the failure messages with the
+// keyword and name of the step.
+func Scenario[C any](t Test) *Steps[C] {
+	return &Steps[C]{t: t, ctx: new(C)}
+}
+
+// Context returns the shared typed test context of the scenario.
+func (s *Steps[C]) Context() *C {
+	return s.ctx
+}
+
+// Given executes the given step function setting up the test context.
+func (s *Steps[C]) Given(name string, step StepFunc[C]) *Steps[C] {
+	s.t.Helper()
+	return s.Step(Given, name, step)
+}
+
+// When executes the given step function executing the system under test.
+func (s *Steps[C]) When(name string, step StepFunc[C]) *Steps[C] {
+	s.t.Helper()
+	return s.Step(When, name, step)
+}
+
+// Then executes the given step function validating the test results.
+func (s *Steps[C]) Then(name string, step StepFunc[C]) *Steps[C] {
+	s.t.Helper()
+	return s.Step(Then, name, step)
+}
+
+// And executes the given step function continuing the previous step.
+func (s *Steps[C]) And(name string, step StepFunc[C]) *Steps[C] {
+	s.t.Helper()
+	return s.Step(And, name, step)
+}
+
+// But executes the given step function continuing the previous step with a
+// contrasting condition.
+func (s *Steps[C]) But(name string, step StepFunc[C]) *Steps[C] {
+	s.t.Helper()
+	return s.Step(But, name, step)
+}
+
+// Step executes the given step function with given keyword and name.
+func (s *Steps[C]) Step(keyword, name string, step StepFunc[C]) *Steps[C] {
+	s.t.Helper()
+	if t, ok := s.t.(*Tester); ok {
+		name := keyword + " " + name
+		t.step.Store(&name)
+	}
+	step(s.t, s.ctx)
+	return s
+}
+
+// BindFunc is a step function bound to the steps of a feature receiving the
+// test, the shared scenario context, and the arguments captured by the
+// regular expression of the binding.
+type BindFunc[C any] func(t Test, ctx *C, args ...string)
+
+// Bindings is a mapping of regular expressions to step functions used to bind
+// the step texts of a feature to step functions.
+type Bindings[C any] map[string]BindFunc[C]
+
+// binding is a compiled step binding.
+type binding[C any] struct {
+	regex *regexp.Regexp
+	step  BindFunc[C]
+}
+
+// Parent is the minimal interface of a parent test, i.e. `*testing.T`, that
+// is needed to run the scenarios of a feature as sub-tests.
+type Parent interface {
+	Test
+	// Parallel signals that the test is to be run in parallel.
+	Parallel()
+	// Run runs the given test function as sub-test with given name.
+	Run(name string, test func(t *testing.T)) bool
+}
+
+// scenario is a scenario of a feature.
+type scenario struct {
+	name   string
+	expect Expect
+	steps  [][2]string
+}
+
+// Feature runs the scenarios of the given Gherkin-like feature text as
+// isolated (by default) parallel sub-tests. The step texts are bound to the
+// step functions via the regular expressions of the given bindings that must
+// match the complete step text. A scenario tagged with `@failure` is expected
+// to fail. Free text lines following the `Feature:` and `Scenario:` lines are
+// ignored as description. Invalid feature texts fail the test immediately.
+//
+//	Feature: feature name
+//	  free text description of the feature.
+//
+//	  @failure
+//	  Scenario: scenario name
+//	    Given a user "alice"
+//	    When the user logs in with password "wrong"
+//	    Then the login fails
+func Feature[C any](t Parent, feature string, bindings Bindings[C]) {
+	t.Helper()
+
+	scenarios, err := parseFeature(feature)
+	if err != nil {
+		t.Fatalf("%v", err)
+		return
+	}
+
+	compiled := make([]binding[C], 0, len(bindings))
+	for pattern, step := range bindings {
+		compiled = append(compiled, binding[C]{
+			regex: regexp.MustCompile("^" + pattern + "$"),
+			step:  step,
+		})
+	}
+
+	if debugger.parallel(Parallel) {
+		t.Parallel()
+	}
+	for _, scenario := range scenarios {
+		scenario := scenario
+		t.Run(scenario.name, run(scenario.expect, func(t Test) {
+			steps := Scenario[C](t)
+			for _, step := range scenario.steps {
+				steps.bind(compiled, step[0], step[1])
+			}
+		}, Parallel))
+	}
+}
+
+// FeatureFile runs the scenarios of the Gherkin-like feature file with given
+// path using the given bindings (see `Feature`). If the feature file cannot
+// be read, the test fails immediately.
+func FeatureFile[C any](t Parent, path string, bindings Bindings[C]) {
+	t.Helper()
+
+	feature, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v", err)
+		return
+	}
+	Feature(t, string(feature), bindings)
+}
+
+// bind executes the step function bound to the given step text.
+func (s *Steps[C]) bind(bindings []binding[C], keyword, text string) {
+	s.t.Helper()
+
+	var found *binding[C]
+	var args []string
+	for index, binding := range bindings {
+		if match := binding.regex.FindStringSubmatch(text); match != nil {
+			if found != nil {
+				s.t.Fatalf("%v", ErrStepAmbiguous(keyword, text))
+			}
+			found, args = &bindings[index], match[1:]
+		}
+	}
+	if found == nil {
+		s.t.Fatalf("%v", ErrStepUndefined(keyword, text))
+	}
+
+	s.Step(keyword, text, func(t Test, ctx *C) {
+		found.step(t, ctx, args...)
+	})
+}
+
+// parseFeature parses the scenarios of the given Gherkin-like feature text.
+// Free text lines directly following the `Feature:` and `Scenario:` lines are
+// skipped as description.
+func parseFeature(feature string) ([]*scenario, error) {
+	scenarios := []*scenario{}
+	var current *scenario
+	expect, described := Success, false
+
+	scanner := bufio.NewScanner(strings.NewReader(feature))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		keyword, rest, _ := strings.Cut(text, " ")
+		switch {
+		case text == "" || strings.HasPrefix(text, "#"):
+		case strings.HasPrefix(text, "Feature:"):
+			described = true
+		case strings.HasPrefix(text, "@"):
+			for _, tag := range strings.Fields(text) {
+				if tag == "@failure" {
+					expect = Failure
+				}
+			}
+			described = false
+		case strings.HasPrefix(text, "Scenario:"):
+			current = &scenario{
+				name:   strings.TrimSpace(strings.TrimPrefix(text, "Scenario:")),
+				expect: expect,
+			}
+			scenarios = append(scenarios, current)
+			expect, described = Success, true
+		case current != nil && isKeyword(keyword):
+			current.steps = append(current.steps,
+				[2]string{keyword, strings.TrimSpace(rest)})
+			described = false
+		case described && !isKeyword(keyword):
+		default:
+			return nil, ErrFeature(line, text)
+		}
+	}
+	return scenarios, nil
+}
+
+// isKeyword returns whether the given word is a step keyword.
+func isKeyword(word string) bool {
+	switch word {
+	case Given, When, Then, And, But:
+		return true
+	}
+	return false
+}
+
+// ErrStepUndefined creates an error reporting a feature step without matching
+// step binding.
+func ErrStepUndefined(keyword, text string) error {
+	return fmt.Errorf("undefined step: %s %s", keyword, text)
+}
+
+// ErrStepAmbiguous creates an error reporting a feature step with multiple
+// matching step bindings.
+func ErrStepAmbiguous(keyword, text string) error {
+	return fmt.Errorf("ambiguous step: %s %s", keyword, text)
+}
+
+// ErrFeature creates an error reporting an invalid line in a feature text.
+func ErrFeature(line int, text string) error {
+	return fmt.Errorf("invalid feature line %d: %s", line, text)
+}
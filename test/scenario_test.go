@@ -0,0 +1,267 @@
+package test_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tkrop/go-testing/mock"
+	"github.com/tkrop/go-testing/test"
+)
+
+// Account is a simple scenario context.
+type Account struct {
+	owner   string
+	balance int
+	steps   []string
+	err     error
+}
+
+// Withdraw withdraws the given amount from the account.
+func (a *Account) Withdraw(amount int) {
+	if amount > a.balance {
+		a.err = assert.AnError
+		return
+	}
+	a.balance -= amount
+}
+
+type ScenarioParams struct {
+	setup  mock.SetupFunc
+	test   func(test.Test)
+	expect test.Expect
+}
+
+var testScenarioParams = map[string]ScenarioParams{
+	"success": {
+		test: func(t test.Test) {
+			ctx := test.Scenario[Account](t).
+				Given("an account", func(t test.Test, ctx *Account) {
+					ctx.balance = 100
+					ctx.steps = append(ctx.steps, "given")
+				}).
+				When("withdrawing", func(t test.Test, ctx *Account) {
+					ctx.Withdraw(30)
+					ctx.steps = append(ctx.steps, "when")
+				}).
+				Then("balance is reduced", func(t test.Test, ctx *Account) {
+					assert.Equal(t, 70, ctx.balance)
+					ctx.steps = append(ctx.steps, "then")
+				}).
+				And("no error", func(t test.Test, ctx *Account) {
+					assert.NoError(t, ctx.err)
+					ctx.steps = append(ctx.steps, "and")
+				}).
+				But("balance is not zero", func(t test.Test, ctx *Account) {
+					assert.NotZero(t, ctx.balance)
+					ctx.steps = append(ctx.steps, "but")
+				}).Context()
+
+			assert.Equal(t, []string{"given", "when", "then", "and", "but"},
+				ctx.steps)
+		},
+		expect: test.Success,
+	},
+	"failure attributed to step": {
+		setup: mock.Chain(
+			test.Errorf("%s: fail", "When withdrawing"),
+			expectSucceed("TestScenario/failure_attributed_to_step"),
+		),
+		test: test.InRun(test.Success, func(t test.Test) {
+			test.Scenario[Account](t).
+				Given("an account", func(t test.Test, ctx *Account) {}).
+				When("withdrawing", func(t test.Test, ctx *Account) {
+					t.Errorf("fail")
+				}).
+				Then("validating", func(t test.Test, ctx *Account) {})
+		}),
+	},
+	"fatal failure aborts scenario": {
+		setup: mock.Chain(
+			test.Fatalf("%s: fail", "Given an account"),
+			expectSucceed("TestScenario/fatal_failure_aborts_scenario"),
+		),
+		test: test.InRun(test.Success, func(t test.Test) {
+			test.Scenario[Account](t).
+				Given("an account", func(t test.Test, ctx *Account) {
+					t.Fatalf("fail")
+				}).
+				Then("not executed", func(t test.Test, ctx *Account) {
+					t.Errorf("executed")
+				})
+		}),
+	},
+	"panic attributed to step": {
+		setup: mock.Chain(
+			test.Fatalf("%s: panic: %v", "Then validating", "fail"),
+			expectSucceed("TestScenario/panic_attributed_to_step"),
+		),
+		test: test.InRun(test.Success, func(t test.Test) {
+			test.Scenario[Account](t).
+				Then("validating", func(t test.Test, ctx *Account) {
+					panic("fail")
+				})
+		}),
+	},
+}
+
+// expectSucceed creates the validation of the failure reported by an isolated
+// test expected to succeed.
+func expectSucceed(name string) mock.SetupFunc {
+	return test.Errorf("Expected test to succeed but it failed: %s", name)
+}
+
+func TestScenario(t *testing.T) {
+	test.Map(t, testScenarioParams).
+		Run(func(t test.Test, param ScenarioParams) {
+			// Given
+			mock.NewMock(t).Expect(param.setup)
+
+			// When
+			param.test(t)
+		})
+}
+
+// accountFeature is a feature describing account withdrawals.
+const accountFeature = `Feature: account withdrawal
+  As an account owner
+  I want to withdraw money up to the balance.
+  # withdrawals are only allowed up to the balance.
+
+  Scenario: withdraw within balance
+    Withdrawals within the balance reduce the balance.
+
+    Given an account of "alice" with balance 100
+    When withdrawing 30
+    Then the balance is 70
+    And no error occurred
+
+  @slow @failure
+  Scenario: withdraw above balance
+    Given an account of "bob" with balance 10
+    When withdrawing 30
+    Then the balance is 10
+    But no error occurred
+`
+
+// accountBindings are the step bindings of the account feature.
+var accountBindings = test.Bindings[Account]{
+	`an account of "([^"]*)" with balance (\d+)`: func(
+		t test.Test, ctx *Account, args ...string,
+	) {
+		ctx.owner = args[0]
+		ctx.balance = atoi(t, args[1])
+	},
+	`withdrawing (\d+)`: func(t test.Test, ctx *Account, args ...string) {
+		ctx.Withdraw(atoi(t, args[0]))
+	},
+	`the balance is (\d+)`: func(t test.Test, ctx *Account, args ...string) {
+		assert.Equal(t, atoi(t, args[0]), ctx.balance)
+	},
+	`no error occurred`: func(t test.Test, ctx *Account, args ...string) {
+		assert.NoError(t, ctx.err)
+	},
+}
+
+// atoi converts the given string argument to an integer.
+func atoi(t test.Test, arg string) int {
+	value, err := strconv.Atoi(arg)
+	require.NoError(t, err)
+	return value
+}
+
+func TestFeature(t *testing.T) {
+	test.Feature(t, accountFeature, accountBindings)
+}
+
+func TestFeatureFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "account.feature")
+	require.NoError(t, os.WriteFile(path, []byte(accountFeature), 0o600))
+
+	test.FeatureFile(t, path, accountBindings)
+}
+
+func TestFeatureUndefinedStep(t *testing.T) {
+	test.Feature(t, `
+  @failure
+  Scenario: undefined step
+    Given an undefined step
+    Then the balance is 0
+`, accountBindings)
+}
+
+func TestFeatureAmbiguousStep(t *testing.T) {
+	test.Feature(t, `
+  @failure
+  Scenario: ambiguous step
+    Given no error occurred
+`, test.Bindings[Account]{
+		`no error.*`:       func(test.Test, *Account, ...string) {},
+		`.*error occurred`: func(test.Test, *Account, ...string) {},
+	})
+}
+
+type FeatureErrorParams struct {
+	feature     string
+	expectError error
+}
+
+var testFeatureErrorParams = map[string]FeatureErrorParams{
+	"step before scenario": {
+		feature:     "Feature: invalid\n  Given an account\n",
+		expectError: test.ErrFeature(2, "Given an account"),
+	},
+	"unknown keyword": {
+		feature: "Scenario: invalid\n  Given an account\n" +
+			"  Whenever withdrawing\n",
+		expectError: test.ErrFeature(3, "Whenever withdrawing"),
+	},
+	"description after tag": {
+		feature:     "@failure\n  free text\n",
+		expectError: test.ErrFeature(2, "free text"),
+	},
+}
+
+// parent is a parent test recording the fatal failure instead of aborting.
+type parent struct {
+	*testing.T
+	fatal string
+}
+
+// Fatalf records the fatal failure.
+func (p *parent) Fatalf(format string, args ...any) {
+	p.fatal = fmt.Sprintf(format, args...)
+}
+
+func TestFeatureError(t *testing.T) {
+	for name, param := range testFeatureErrorParams {
+		name, param := name, param
+		t.Run(name, func(t *testing.T) {
+			// Given
+			parent := &parent{T: t}
+
+			// When
+			test.Feature(parent, param.feature, accountBindings)
+
+			// Then
+			assert.Equal(t, param.expectError.Error(), parent.fatal)
+		})
+	}
+}
+
+func TestFeatureFileError(t *testing.T) {
+	// Given
+	parent := &parent{T: t}
+
+	// When
+	test.FeatureFile(parent, "not-existing.feature", accountBindings)
+
+	// Then
+	assert.Equal(t, "open not-existing.feature: no such file or directory",
+		parent.fatal)
+}
@@ -0,0 +1,143 @@
+package test
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	gosync "sync"
+	"testing"
+	"time"
+
+	"github.com/tkrop/go-testing/internal/shuffle"
+)
+
+// ShuffleEnv is the environment variable to enable shuffling of test cases
+// with the given seed without using `Shuffle`. The value `on` uses a time
+// based seed, while `off` or an empty value disables shuffling. Any other
+// value including `0` is used as seed.
+const ShuffleEnv = shuffle.Env
+
+// Shuffle adds a policy to shuffle the order of the test cases of all `test.Map`
+// and `test.Slice` runners using the given seed. Any seed including zero is a
+// valid seed. The seed is reported by `Main` on start and by each shuffled
+// test runner to allow reproducing the test case order.
+func Shuffle(seed int64) Option {
+	return Policies(&shufflePolicy{seed: &seed})
+}
+
+// ShuffleRandom adds a policy to shuffle the order of the test cases of all
+// `test.Map` and `test.Slice` runners like `Shuffle` using a time based seed.
+func ShuffleRandom() Option {
+	return Policies(&shufflePolicy{})
+}
+
+// shufflePolicy is the policy to enable shuffling of test cases.
+type shufflePolicy struct {
+	// seed the seed to use, nil for a time based seed.
+	seed *int64
+}
+
+// Setup enables shuffling of test cases.
+func (p *shufflePolicy) Setup() {
+	if p.seed == nil {
+		shuffler.enable(time.Now().UnixNano())
+		return
+	}
+	shuffler.enable(*p.seed)
+}
+
+// Verify disables shuffling of test cases.
+func (p *shufflePolicy) Verify() error {
+	shuffler.disable()
+	return nil
+}
+
+// shuffleConfig is the package-wide configuration of test case shuffling.
+type shuffleConfig struct {
+	mu      gosync.Mutex
+	once    gosync.Once
+	enabled bool
+	seed    int64
+}
+
+// shuffler is the package-wide test case shuffling configuration.
+var shuffler = &shuffleConfig{}
+
+// enable enables shuffling of test cases with given seed.
+func (c *shuffleConfig) enable(seed int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.enabled, c.seed = true, seed
+}
+
+// start reports the seed to the given writer, if shuffling of test cases is
+// enabled by policy or environment variable.
+func (c *shuffleConfig) start(w io.Writer) {
+	c.env()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.enabled {
+		fmt.Fprintf(w, "test: shuffle seed %d\n", c.seed)
+	}
+}
+
+// disable disables shuffling of test cases.
+func (c *shuffleConfig) disable() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.enabled = false
+}
+
+// env enables shuffling of test cases once, if the shuffle environment
+// variable is set.
+func (c *shuffleConfig) env() {
+	c.once.Do(func() {
+		switch value := os.Getenv(ShuffleEnv); value {
+		case "", "off":
+		case "on":
+			c.enable(time.Now().UnixNano())
+		default:
+			seed, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				panic(ErrShuffleSeed(value))
+			}
+			c.enable(seed)
+		}
+	})
+}
+
+// names returns the given test case names in the order they are supposed to
+// run in the given test. If shuffling is enabled, the names are sorted and
+// shuffled using a random source derived from the seed and the test name, so
+// that the order is independent from other tests, and the seed is logged to
+// the test to allow reproducing the order. Otherwise the names are returned
+// as they are.
+func (c *shuffleConfig) names(t *testing.T, names []string) []string {
+	c.env()
+	c.mu.Lock()
+	enabled, seed := c.enabled, c.seed
+	c.mu.Unlock()
+	if !enabled {
+		return names
+	}
+	t.Logf("shuffle seed %d", seed)
+
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(t.Name()))
+	random := rand.New(rand.NewSource(seed ^ int64(hash.Sum64())))
+
+	sort.Strings(names)
+	random.Shuffle(len(names), func(i, j int) {
+		names[i], names[j] = names[j], names[i]
+	})
+	return names
+}
+
+// ErrShuffleSeed creates an error reporting an invalid shuffle seed.
+func ErrShuffleSeed(seed string) error {
+	return fmt.Errorf("invalid shuffle seed [%s=%s]", ShuffleEnv, seed)
+}
@@ -0,0 +1,98 @@
+package test_test
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tkrop/go-testing/test"
+)
+
+// shuffleParams are the test parameter sets used to check shuffling.
+var shuffleParams = map[string]int{
+	"a": 0, "b": 1, "c": 2, "d": 3, "e": 4, "f": 5, "g": 6, "h": 7,
+}
+
+// shuffleOrder runs the shuffle parameter sets with the given options and
+// returns the order of the executed test cases.
+func shuffleOrder(t *testing.T, opts ...test.Option) []string {
+	order := []string{}
+	test.RunMain(MainFunc(func() int {
+		t.Run("map", func(t *testing.T) {
+			test.Map(t, shuffleParams).
+				RunSeq(func(t test.Test, param int) {
+					order = append(order, string(rune('a'+param)))
+				})
+		})
+		t.Run("slice", func(t *testing.T) {
+			test.Slice(t, []int{0, 1, 2, 3, 4, 5, 6, 7}).
+				RunSeq(func(t test.Test, param int) {
+					order = append(order, string(rune('A'+param)))
+				})
+		})
+		return 0
+	}), opts...)
+	return order
+}
+
+func TestShuffle(t *testing.T) {
+	// Given
+	builder := &strings.Builder{}
+
+	// When
+	order := shuffleOrder(t, test.Shuffle(42), test.Output(builder))
+	other := shuffleOrder(t, test.Shuffle(7))
+
+	// Then
+	assert.Equal(t, "test: shuffle seed 42\n", builder.String())
+	assert.Equal(t, []string{
+		"a", "d", "h", "e", "b", "g", "c", "f",
+		"F", "D", "H", "E", "G", "C", "B", "A",
+	}, order)
+	assert.NotEqual(t, order, other)
+
+	sorted := append([]string{}, order...)
+	sort.Strings(sorted)
+	assert.Equal(t, []string{
+		"A", "B", "C", "D", "E", "F", "G", "H",
+		"a", "b", "c", "d", "e", "f", "g", "h",
+	}, sorted)
+}
+
+func TestShuffleZeroSeed(t *testing.T) {
+	// Given
+	builder := &strings.Builder{}
+
+	// When
+	order := shuffleOrder(t, test.Shuffle(0), test.Output(builder))
+
+	// Then
+	assert.Equal(t, "test: shuffle seed 0\n", builder.String())
+	assert.Equal(t, []string{
+		"g", "b", "f", "a", "h", "c", "e", "d",
+		"C", "B", "E", "G", "D", "F", "H", "A",
+	}, order)
+}
+
+func TestShuffleRandom(t *testing.T) {
+	// Given
+	builder := &strings.Builder{}
+
+	// When
+	order := shuffleOrder(t, test.ShuffleRandom(), test.Output(builder))
+
+	// Then
+	assert.Regexp(t, "^test: shuffle seed -?[0-9]+\n$", builder.String())
+	assert.Len(t, order, 16)
+}
+
+func TestShuffleDisabled(t *testing.T) {
+	// When
+	order := shuffleOrder(t)
+
+	// Then
+	assert.Equal(t, []string{"A", "B", "C", "D", "E", "F", "G", "H"},
+		order[8:])
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/tkrop/go-testing/internal/reflect"
+	"github.com/tkrop/go-testing/internal/result"
 	"github.com/tkrop/go-testing/internal/slices"
 	"github.com/tkrop/go-testing/internal/sync"
 )
@@ -65,6 +66,13 @@ type Cleanuper interface {
 	Cleanup(f func())
 }
 
+// Tracer is a tracer receiving the failure events reported to a test in order
+// of occurrence.
+type Tracer interface {
+	// Trace receives the description of a failure event.
+	Trace(entry string)
+}
+
 // Tester is a test isolation environment based on the `Test` abstraction. It
 // can be used as a drop in replacement for `testing.T` in various libraries
 // to check for expected test failures.
@@ -75,7 +83,10 @@ type Tester struct {
 	wg       sync.WaitGroup
 	mu       gosync.Mutex
 	failed   atomic.Bool
+	done     atomic.Bool
+	step     atomic.Pointer[string]
 	reporter Reporter
+	tracers  []Tracer
 	cleanups []func()
 	expect   Expect
 }
@@ -107,6 +118,13 @@ func (t *Tester) Reporter(reporter Reporter) {
 	t.reporter = reporter
 }
 
+// Tracer adds a tracer receiving the failure events reported to the test.
+// This can be used to correlate failures with other test interactions. If
+// multiple tracers are added, each tracer receives all failure events.
+func (t *Tester) Tracer(tracer Tracer) {
+	t.tracers = append(t.tracers, tracer)
+}
+
 // Cleanup is a function called to setup test cleanup after execution. This
 // method is allowing `gomock` to register its `finish` method that reports the
 // missing mock calls.
@@ -126,13 +144,36 @@ func (t *Tester) Helper() {
 	t.t.Helper()
 }
 
+// Failed reports whether a failure was reported to the test.
+func (t *Tester) Failed() bool {
+	return t.failed.Load()
+}
+
+// Expect returns the expected outcome of the test, i.e. whether the test is
+// expected to succeed or to fail.
+func (t *Tester) Expect() Expect {
+	return t.expect
+}
+
+// Logf delegates the log message to the parent test context, if supported.
+func (t *Tester) Logf(format string, args ...any) {
+	t.Helper()
+	if l, ok := t.t.(interface{ Logf(string, ...any) }); ok {
+		l.Logf(format, args...)
+	}
+}
+
 // Errorf handles failure messages where the test is supposed to continue. On
 // an expected success, the failure is also delegated to the parent test
 // context.
 func (t *Tester) Errorf(format string, args ...any) {
 	t.Helper()
 	t.failed.Store(true)
-	if t.expect == Success {
+	t.trace("error: "+format, args...)
+	if t.late(format, args...) {
+		return
+	} else if t.expect == Success {
+		format, args := t.attribute(format, args...)
 		t.t.Errorf(format, args...)
 	} else if t.reporter != nil {
 		t.reporter.Errorf(format, args...)
@@ -145,8 +186,12 @@ func (t *Tester) Errorf(format string, args ...any) {
 func (t *Tester) Fatalf(format string, args ...any) {
 	t.Helper()
 	t.failed.Store(true)
+	t.trace("fatal: "+format, args...)
 	defer t.unlock()
-	if t.expect == Success {
+	if t.late(format, args...) {
+		runtime.Goexit()
+	} else if t.expect == Success {
+		format, args := t.attribute(format, args...)
 		t.t.Fatalf(format, args...)
 	} else if t.reporter != nil {
 		t.reporter.Fatalf(format, args...)
@@ -160,8 +205,11 @@ func (t *Tester) Fatalf(format string, args ...any) {
 func (t *Tester) FailNow() {
 	t.Helper()
 	t.failed.Store(true)
+	t.trace("fail now")
 	defer t.unlock()
-	if t.expect == Success {
+	if t.late("fail now") {
+		runtime.Goexit()
+	} else if t.expect == Success {
 		t.t.FailNow()
 	} else if t.reporter != nil {
 		t.reporter.FailNow()
@@ -174,8 +222,13 @@ func (t *Tester) FailNow() {
 func (t *Tester) Panic(arg any) {
 	t.Helper()
 	t.failed.Store(true)
+	if t.expect != Success { // traced via `Fatalf` otherwise.
+		t.trace("panic: %v", arg)
+	}
 	defer t.unlock()
-	if t.expect == Success {
+	if t.late("panic: %v", arg) {
+		runtime.Goexit()
+	} else if t.expect == Success {
 		t.Fatalf("panic: %v", arg)
 	} else if t.reporter != nil {
 		t.reporter.Panic(arg)
@@ -189,9 +242,10 @@ func (t *Tester) Panic(arg any) {
 // context.
 func (t *Tester) Run(test func(Test), parallel bool) Test {
 	t.Helper()
-	if parallel {
+	if debugger.parallel(parallel) {
 		t.Parallel()
 	}
+	debugger.event(t.Name(), "start [expect-success=%v]", t.expect)
 
 	// register cleanup handlers.
 	t.register()
@@ -228,6 +282,8 @@ func (t *Tester) register() {
 
 // cleanup runs the cleanup methods registered on the isolated test environment.
 func (t *Tester) cleanup() {
+	debugger.event(t.Name(), "cleanup")
+	t.step.Store(nil)
 	t.mu.Lock()
 	cleanups := slices.Reverse(t.cleanups)
 	t.mu.Unlock()
@@ -242,19 +298,54 @@ func (t *Tester) cleanup() {
 func (t *Tester) finish() {
 	t.mu.Lock()
 	defer t.mu.Unlock()
+	defer t.done.Store(true)
 
+	debugger.event(t.Name(), "evaluate [expect-success=%v, failed=%v]",
+		t.expect, t.failed.Load())
 	switch t.expect {
 	case Success:
 		if t.failed.Load() {
+			t.record(result.Success)
 			t.t.Errorf("Expected test to succeed but it failed: %s", t.t.Name())
 		}
 	case Failure:
 		if !t.failed.Load() {
+			t.record(result.Failure)
 			t.t.Errorf("Expected test to fail but it succeeded: %s", t.t.Name())
 		}
 	}
 }
 
+// record logs the structured result record of an unmet expectation to the
+// parent test context, if supported, to allow test reports to evaluate it.
+// Records of nested isolated tests are omitted, since the parent evaluates
+// the delegated failure on its own.
+func (t *Tester) record(expect string) {
+	t.Helper()
+	if _, ok := t.t.(*Tester); !ok {
+		t.Logf("%s", &result.Result{Expect: expect, Failed: t.failed.Load()})
+	}
+}
+
+// attribute attributes the given failure message to the current test step by
+// prefixing it with the step name, if a test step is running.
+func (t *Tester) attribute(format string, args ...any) (string, []any) {
+	if step := t.step.Load(); step != nil {
+		return "%s: " + format, append([]any{*step}, args...)
+	}
+	return format, args
+}
+
+// late records the given failure as late failure, if the test has already
+// finished and late failures are recorded, e.g. for mock calls of detached
+// go-routines running after the test finished.
+func (t *Tester) late(format string, args ...any) bool {
+	if !t.done.Load() {
+		return false
+	}
+	return lateFailures.record(t.Name(), fmt.Sprintf(format, args...))
+}
+
 // recover recovers from panics and generate test failure.
 func (t *Tester) recover() {
 	t.Helper()
@@ -263,9 +354,22 @@ func (t *Tester) recover() {
 	}
 }
 
+// trace traces the given failure event, if tracers are set up.
+func (t *Tester) trace(format string, args ...any) {
+	if len(t.tracers) == 0 {
+		return
+	}
+
+	entry := fmt.Sprintf(format, args...)
+	for _, tracer := range t.tracers {
+		tracer.Trace(entry)
+	}
+}
+
 // unlock unlocks the wait group of the test by consuming the wait group
 // counter completely.
 func (t *Tester) unlock() {
+	debugger.event(t.Name(), "unlock")
 	if t.wg != nil {
 		t.wg.Add(math.MinInt)
 	}
@@ -329,23 +433,32 @@ func (r *runner[P]) RunSeq(call func(t Test, param P)) {
 func (r *runner[P]) run(call func(t Test, param P), parallel bool) {
 	switch params := r.params.(type) {
 	case map[string]P:
-		if parallel {
+		if debugger.parallel(parallel) {
 			r.t.Parallel()
 		}
 
-		for name, param := range params {
-			name, param := name, param
+		names := make([]string, 0, len(params))
+		for name := range params {
+			names = append(names, name)
+		}
+		for _, name := range shuffler.names(r.t, names) {
+			name, param := name, params[name]
 			r.t.Run(name, r.wrap(name, param, call, parallel))
 		}
 
 	case []P:
-		if parallel {
+		if debugger.parallel(parallel) {
 			r.t.Parallel()
 		}
 
+		names := make([]string, 0, len(params))
+		indexes := make(map[string]int, len(params))
 		for index, param := range params {
-			index, param := index, param
 			name := fmt.Sprintf("%s[%d]", r.name(param), index)
+			names, indexes[name] = append(names, name), index
+		}
+		for _, name := range shuffler.names(r.t, names) {
+			name, param := name, params[indexes[name]]
 			r.t.Run(name, r.wrap(name, param, call, parallel))
 		}
 	case P:
@@ -361,15 +474,17 @@ func (r *runner[P]) run(call func(t Test, param P), parallel bool) {
 	}
 }
 
-// wrap creates the test wrapper method executing the test.
+// wrap creates the test wrapper method executing the test. Lazy fields of the
+// parameter set are resolved at the start of the test case.
 func (r *runner[P]) wrap(
 	name string, param P, call func(t Test, param P), parallel bool,
 ) func(*testing.T) {
+	documentation.record(r.t.Name(), name, param)
 	return run(r.expect(param), func(t Test) {
 		// Helpful for debugging to see the test case.
 		require.NotEmpty(t, name)
 
-		call(t, param)
+		call(t, resolveParam(t, param))
 	}, parallel)
 }
 
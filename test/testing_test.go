@@ -339,3 +339,67 @@ func TestTypePanic(t *testing.T) {
 	test.New[TestParam](t, ParamParam{expect: false}).
 		Run(func(t test.Test, param TestParam) {})
 }
+
+// tracer is a simple tracer recording the traced failure events.
+type tracer struct {
+	entries []string
+}
+
+func (t *tracer) Trace(entry string) {
+	t.entries = append(t.entries, entry)
+}
+
+type TracerParams struct {
+	expect      test.Expect
+	call        func(t test.Test)
+	expectTrace []string
+}
+
+var testTracerParams = map[string]TracerParams{
+	"no failure": {
+		call:   func(test.Test) {},
+		expect: test.Success,
+	},
+	"errorf": {
+		call:        func(t test.Test) { t.Errorf("fail %d", 1) },
+		expectTrace: []string{"error: fail 1"},
+		expect:      test.Success,
+	},
+	"fatalf": {
+		call:        func(t test.Test) { t.Fatalf("fail %d", 1) },
+		expectTrace: []string{"fatal: fail 1"},
+		expect:      test.Success,
+	},
+	"failnow": {
+		call:        func(t test.Test) { t.FailNow() },
+		expectTrace: []string{"fail now"},
+		expect:      test.Success,
+	},
+	"panic": {
+		call:        func(t test.Test) { panic("fail") },
+		expectTrace: []string{"panic: fail"},
+		expect:      test.Success,
+	},
+}
+
+func TestTracer(t *testing.T) {
+	test.Map(t, testTracerParams).
+		Run(func(t test.Test, param TracerParams) {
+			// Given
+			tracer, other := &tracer{}, &tracer{}
+			var tester *test.Tester
+
+			// When
+			test.InRun(test.Expect(param.expectTrace == nil), func(t test.Test) {
+				tester = t.(*test.Tester)
+				tester.Tracer(tracer)
+				tester.Tracer(other)
+				param.call(t)
+			})(t)
+
+			// Then
+			assert.Equal(t, param.expectTrace, tracer.entries)
+			assert.Equal(t, param.expectTrace, other.entries)
+			assert.Equal(t, param.expectTrace != nil, tester.Failed())
+		})
+}
@@ -0,0 +1,71 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/tkrop/go-testing/internal/reflect"
+)
+
+// TypeFunc is a test function instantiated for a specific type that is used
+// to run the same test parameter sets across multiple type instantiations.
+type TypeFunc[P any] struct {
+	name string
+	call func(t Test, param P)
+}
+
+// Type creates a test function for the explicitly given type instantiation
+// `T` using the given instantiation of a generic test function, e.g.
+// `test.Type[int](testSet[int])`. The sub-tests of the type instantiation are
+// named by the type.
+func Type[T any, P any](call func(t Test, param P)) TypeFunc[P] {
+	return TypeFunc[P]{
+		name: reflect.TypeOf((*T)(nil)).Elem().String(),
+		call: call,
+	}
+}
+
+// Types runs the given test parameter sets - a single test parameter set, a
+// slice of test parameter sets, or a test case name to test parameter set map
+// (see `New`) - for each of the given type instantiations of a generic test
+// function using the isolated (by default) parallel test runner. The test
+// cases are grouped by type instantiation in sub-tests named by the type:
+//
+//	test.Types(t, testSetParams,
+//		test.Type[int](testSet[int]),
+//		test.Type[string](testSet[string]),
+//	)
+func Types[P any](t *testing.T, params any, types ...TypeFunc[P]) {
+	t.Helper()
+
+	runTypes(t, params, types, Parallel)
+}
+
+// TypesSeq runs the given test parameter sets for each of the given type
+// instantiations of a generic test function like `Types`, but in a sequence.
+func TypesSeq[P any](t *testing.T, params any, types ...TypeFunc[P]) {
+	t.Helper()
+
+	runTypes(t, params, types, false)
+}
+
+// runTypes runs the given test parameter sets for each of the given type
+// instantiations either parallel or in sequence.
+func runTypes[P any](
+	t *testing.T, params any, types []TypeFunc[P], parallel bool,
+) {
+	t.Helper()
+
+	if debugger.parallel(parallel) {
+		t.Parallel()
+	}
+	for _, tfunc := range types {
+		tfunc := tfunc
+		t.Run(tfunc.name, func(t *testing.T) {
+			if parallel {
+				New[P](t, params).Run(tfunc.call)
+			} else {
+				New[P](t, params).RunSeq(tfunc.call)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package test_test
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tkrop/go-testing/test"
+)
+
+// Set is a simple generic set.
+type Set[T comparable] map[T]struct{}
+
+// Add adds the given values to the set.
+func (s Set[T]) Add(values ...T) Set[T] {
+	for _, value := range values {
+		s[value] = struct{}{}
+	}
+	return s
+}
+
+type TypesParams struct {
+	values    []int
+	expectLen int
+	expect    test.Expect
+}
+
+var testTypesParams = map[string]TypesParams{
+	"empty": {
+		expectLen: 0,
+		expect:    test.Success,
+	},
+	"distinct": {
+		values:    []int{1, 2, 3},
+		expectLen: 3,
+		expect:    test.Success,
+	},
+	"duplicates": {
+		values:    []int{1, 2, 1},
+		expectLen: 2,
+		expect:    test.Success,
+	},
+	"failure": {
+		values:    []int{1},
+		expectLen: 2,
+	},
+}
+
+// typesNames collects the names of the typed test cases.
+var typesNames = struct {
+	sync.Mutex
+	names []string
+}{}
+
+// testTypes is the generic test function for the set type instantiations.
+func testTypes[T comparable](t test.Test, param TypesParams) {
+	// Given
+	values := make([]T, 0, len(param.values))
+	for _, value := range param.values {
+		values = append(values, typed[T](value))
+	}
+	typesNames.Lock()
+	typesNames.names = append(typesNames.names, t.Name())
+	typesNames.Unlock()
+
+	// When
+	set := Set[T]{}.Add(values...)
+
+	// Then
+	assert.Equal(t, param.expectLen, len(set))
+}
+
+// typed converts the given integer value to the given type.
+func typed[T comparable](value int) T {
+	var result T
+	switch any(result).(type) {
+	case string:
+		return any(strconv.Itoa(value)).(T)
+	case float64:
+		return any(float64(value)).(T)
+	}
+	return any(value).(T)
+}
+
+// expectTypes registers the cleanup validating the names of the typed test
+// cases of the given test.
+func expectTypes(t *testing.T) {
+	t.Cleanup(func() {
+		expect := []string{}
+		for _, name := range []string{"float64", "int", "string"} {
+			for _, tcase := range []string{
+				"distinct", "duplicates", "empty", "failure",
+			} {
+				expect = append(expect,
+					fmt.Sprintf("%s/%s/%s", t.Name(), name, tcase))
+			}
+		}
+
+		typesNames.Lock()
+		defer typesNames.Unlock()
+		names, others := []string{}, []string{}
+		for _, name := range typesNames.names {
+			if strings.HasPrefix(name, t.Name()+"/") {
+				names = append(names, name)
+			} else {
+				others = append(others, name)
+			}
+		}
+		sort.Strings(names)
+		assert.Equal(t, expect, names)
+		typesNames.names = others
+	})
+}
+
+func TestTypes(t *testing.T) {
+	expectTypes(t)
+
+	test.Types(t, testTypesParams,
+		test.Type[int](testTypes[int]),
+		test.Type[string](testTypes[string]),
+		test.Type[float64](testTypes[float64]),
+	)
+}
+
+func TestTypesSeq(t *testing.T) {
+	expectTypes(t)
+
+	test.TypesSeq(t, testTypesParams,
+		test.Type[int](testTypes[int]),
+		test.Type[string](testTypes[string]),
+		test.Type[float64](testTypes[float64]),
+	)
+}
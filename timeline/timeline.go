@@ -0,0 +1,257 @@
+// Package timeline contains a small per-test timeline collector merging mock
+// calls, HTTP requests, log records, and test failure events in order of
+// occurrence to ease the analysis of failing component tests. It is part of
+// the public interface, however, we are still experimenting to optimize the
+// interface and the user experience.
+package timeline
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	gosync "sync"
+	"time"
+
+	"github.com/tkrop/go-testing/gock"
+	"github.com/tkrop/go-testing/mock"
+	"github.com/tkrop/go-testing/test"
+)
+
+// ExportEnv is the environment variable to set up a directory to which the
+// timelines of failed tests are exported in JSON format.
+const ExportEnv = "TEST_TIMELINE"
+
+// Kind is the kind of a timeline event.
+type Kind string
+
+// Kinds of timeline events.
+const (
+	// Mock is the kind of mock call events.
+	Mock Kind = "mock"
+	// HTTP is the kind of HTTP request events.
+	HTTP Kind = "http"
+	// Log is the kind of log record events.
+	Log Kind = "log"
+	// Failure is the kind of test failure events.
+	Failure Kind = "failure"
+)
+
+// Event is a single event of a timeline.
+type Event struct {
+	// Time the time the event occurred.
+	Time time.Time `json:"time"`
+	// Kind the kind of the event.
+	Kind Kind `json:"kind"`
+	// Routine the identifier of the go-routine creating the event.
+	Routine uint64 `json:"routine"`
+	// Message the description of the event.
+	Message string `json:"message"`
+}
+
+// String returns the event in a human readable format.
+func (e Event) String() string {
+	return fmt.Sprintf("%s [%d] %-7s %s",
+		e.Time.Format("15:04:05.000000"), e.Routine, e.Kind, e.Message)
+}
+
+// Timeline is a per-test timeline collector. On unexpected test failure, the
+// timeline is printed to the test log and exported to the directory provided
+// by the `TEST_TIMELINE` environment variable. Expected failures of a test
+// using `test.Failure` are not reported.
+type Timeline struct {
+	t      test.Test
+	mu     gosync.Mutex
+	events []Event
+	export string
+}
+
+// New creates a new timeline collector for the given test that collects the
+// failure events of the test. To include the missing mock and HTTP calls
+// reported when the test finishes, the timeline needs to be created before
+// the mock handler.
+func New(t test.Test) *Timeline {
+	tl := &Timeline{t: t, export: os.Getenv(ExportEnv)}
+	if tester, ok := t.(*test.Tester); ok {
+		tester.Tracer(tl.Tracer(Failure))
+	}
+	if c, ok := t.(test.Cleanuper); ok {
+		c.Cleanup(tl.report)
+	}
+	return tl
+}
+
+// ExportDir sets up the directory to which the timeline is exported in JSON
+// format on test failure. By default, the directory is provided by the
+// `TEST_TIMELINE` environment variable.
+func (tl *Timeline) ExportDir(dir string) *Timeline {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	tl.export = dir
+	return tl
+}
+
+// Collect creates a mock setup function that adds the mock calls and the HTTP
+// requests handled by the HTTP request/response mock controller to the
+// timeline.
+//
+// Only mock calls using the notification functions `Return`, `ReturnWith`,
+// and `Panic` of the mock handler are collected.
+func (tl *Timeline) Collect() mock.SetupFunc {
+	return func(mocks *mock.Mocks) any {
+		mocks.Tracer(tl.Tracer(Mock))
+		mock.Get(mocks, gock.NewGock).Tracer(tl.Tracer(HTTP))
+		return nil
+	}
+}
+
+// Tracer returns a tracer adding the traced entries as events of given kind.
+func (tl *Timeline) Tracer(kind Kind) mock.Tracer {
+	return &tracer{timeline: tl, kind: kind}
+}
+
+// Writer returns a writer adding each written line as log record event, e.g.
+// to be used as output of a logger.
+func (tl *Timeline) Writer() io.Writer {
+	return &writer{timeline: tl}
+}
+
+// Add adds an event of given kind with given message to the timeline.
+func (tl *Timeline) Add(kind Kind, message string) {
+	event := Event{
+		Time: time.Now(), Kind: kind,
+		Routine: routine(), Message: message,
+	}
+
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	tl.events = append(tl.events, event)
+}
+
+// Events returns a copy of the events of the timeline in order of occurrence.
+func (tl *Timeline) Events() []Event {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	return append([]Event{}, tl.events...)
+}
+
+// String returns the timeline in a human readable format.
+func (tl *Timeline) String() string {
+	builder := strings.Builder{}
+	for _, event := range tl.Events() {
+		builder.WriteString(event.String() + "\n")
+	}
+	return builder.String()
+}
+
+// WriteJSON writes the events of the timeline in JSON format to the given
+// writer.
+func (tl *Timeline) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(tl.Events())
+}
+
+// Export exports the timeline in JSON format to a file in the given directory
+// named after the test.
+func (tl *Timeline) Export(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	buffer := &bytes.Buffer{}
+	if err := tl.WriteJSON(buffer); err != nil {
+		return err
+	}
+	path := filepath.Join(dir, fileName(tl.t.Name())+".json")
+	return os.WriteFile(path, buffer.Bytes(), 0o644)
+}
+
+// report prints and exports the timeline, if the test failed unexpectedly.
+func (tl *Timeline) report() {
+	if !tl.unexpected() {
+		return
+	}
+
+	if l, ok := tl.t.(interface{ Logf(string, ...any) }); ok {
+		l.Logf("timeline:\n%s", tl)
+	}
+	tl.mu.Lock()
+	dir := tl.export
+	tl.mu.Unlock()
+	if dir != "" {
+		if err := tl.Export(dir); err != nil {
+			tl.t.Errorf("%v", ErrExport(dir, err))
+		}
+	}
+}
+
+// unexpected returns whether the outcome of the test is unexpected, i.e. the
+// test failed while expected to succeed or succeeded while expected to fail.
+// Tests not providing their expected outcome are expected to succeed.
+func (tl *Timeline) unexpected() bool {
+	f, ok := tl.t.(interface{ Failed() bool })
+	if !ok {
+		return false
+	} else if e, ok := tl.t.(interface{ Expect() test.Expect }); ok {
+		return f.Failed() != (e.Expect() == test.Failure)
+	}
+	return f.Failed()
+}
+
+// tracer is a tracer adding the traced entries to a timeline.
+type tracer struct {
+	timeline *Timeline
+	kind     Kind
+}
+
+// Trace adds the given entry to the timeline.
+func (t *tracer) Trace(entry string) {
+	t.timeline.Add(t.kind, entry)
+}
+
+// writer is a writer adding the written lines to a timeline.
+type writer struct {
+	timeline *Timeline
+}
+
+// Write adds each line of the given data as log record event to the timeline.
+func (w *writer) Write(data []byte) (int, error) {
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimRight(line, "\r"); line != "" {
+			w.timeline.Add(Log, line)
+		}
+	}
+	return len(data), nil
+}
+
+// routine returns the identifier of the current go-routine.
+func routine() uint64 {
+	buffer := make([]byte, 64)
+	buffer = buffer[:runtime.Stack(buffer, false)]
+	field := strings.Fields(strings.TrimPrefix(string(buffer), "goroutine "))
+	if len(field) == 0 {
+		return 0
+	}
+	id, _ := strconv.ParseUint(field[0], 10, 64)
+	return id
+}
+
+// fileNameRegex matches characters not allowed in file names.
+var fileNameRegex = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// fileName creates a file name from the given test name.
+func fileName(name string) string {
+	return fileNameRegex.ReplaceAllString(name, "_")
+}
+
+// ErrExport creates an error reporting a failed timeline export.
+func ErrExport(dir string, err error) error {
+	return fmt.Errorf("timeline export failed [dir=%s]: %w", dir, err)
+}
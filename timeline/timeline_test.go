@@ -0,0 +1,235 @@
+package timeline_test
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tkrop/go-testing/gock"
+	"github.com/tkrop/go-testing/mock"
+	"github.com/tkrop/go-testing/test"
+	"github.com/tkrop/go-testing/timeline"
+)
+
+//go:generate mockgen -package=timeline_test -destination=mock_iface_test.go -source=timeline_test.go  IFace
+
+type IFace interface {
+	CallA(string) error
+}
+
+func CallA(input string, err error) mock.SetupFunc {
+	return func(mocks *mock.Mocks) any {
+		return mock.Get(mocks, NewMockIFace).EXPECT().CallA(input).
+			DoAndReturn(mocks.Return(IFace.CallA, err))
+	}
+}
+
+func GetHTTP(path string, status int) mock.SetupFunc {
+	return func(mocks *mock.Mocks) any {
+		mock.Get(mocks, gock.NewGock).New("http://api.test").Get(path).
+			Reply(status)
+		return nil
+	}
+}
+
+// unit is the component under test calling the mock and the HTTP client and
+// logging the progress.
+func unit(mocks *mock.Mocks, logger *log.Logger, input string) {
+	logger.Printf("start %s", input)
+	_ = mock.Get(mocks, NewMockIFace).CallA(input)
+
+	client := &http.Client{}
+	mock.Get(mocks, gock.NewGock).InterceptClient(client)
+	if resp, err := client.Get("http://api.test/" + input); err == nil {
+		resp.Body.Close()
+	}
+	logger.Printf("done %s", input)
+}
+
+type entry struct {
+	kind    timeline.Kind
+	message string
+}
+
+type TimelineParams struct {
+	setup         mock.SetupFunc
+	call          func(t test.Test)
+	expectFailure bool
+	expectEvents  []entry
+	expectExport  bool
+	expect        test.Expect
+}
+
+var testTimelineParams = map[string]TimelineParams{
+	"success": {
+		setup: mock.Setup(
+			CallA("a", nil),
+			GetHTTP("/a", http.StatusOK),
+		),
+		expectEvents: []entry{
+			{timeline.Log, "start a"},
+			{timeline.Mock, `IFace.CallA("a") => (<nil>)`},
+			{timeline.HTTP, "GET http://api.test/a => 200"},
+			{timeline.Log, "done a"},
+		},
+		expect: test.Success,
+	},
+	"failure": {
+		setup: mock.Setup(
+			CallA("a", nil),
+			GetHTTP("/a", http.StatusOK),
+		),
+		call: func(t test.Test) {
+			t.Errorf("failure")
+		},
+		expectEvents: []entry{
+			{timeline.Log, "start a"},
+			{timeline.Mock, `IFace.CallA("a") => (<nil>)`},
+			{timeline.HTTP, "GET http://api.test/a => 200"},
+			{timeline.Log, "done a"},
+			{timeline.Failure, "error: failure"},
+		},
+		expectExport: true,
+		expect:       test.Failure,
+	},
+	"expected failure": {
+		setup: mock.Setup(
+			CallA("a", nil),
+			GetHTTP("/a", http.StatusOK),
+		),
+		call: func(t test.Test) {
+			t.Errorf("failure")
+		},
+		expectFailure: true,
+		expectEvents: []entry{
+			{timeline.Log, "start a"},
+			{timeline.Mock, `IFace.CallA("a") => (<nil>)`},
+			{timeline.HTTP, "GET http://api.test/a => 200"},
+			{timeline.Log, "done a"},
+			{timeline.Failure, "error: failure"},
+		},
+		expect: test.Success,
+	},
+	"missing calls": {
+		setup: mock.Setup(
+			CallA("a", nil),
+			GetHTTP("/b", http.StatusOK),
+		),
+		expectEvents: []entry{
+			{timeline.Log, "start a"},
+			{timeline.Mock, `IFace.CallA("a") => (<nil>)`},
+			{timeline.HTTP, "GET http://api.test/a => " +
+				"error(gock: cannot match any request)"},
+			{timeline.Log, "done a"},
+			{timeline.Failure, "error: missing call(s) to GET " +
+				"http://api.test/b"},
+			{timeline.Failure, "error: aborting test due to missing call(s)"},
+		},
+		expectExport: true,
+		expect:       test.Failure,
+	},
+}
+
+func TestTimeline(t *testing.T) {
+	test.Map(t, testTimelineParams).
+		Run(func(t test.Test, param TimelineParams) {
+			// Given
+			dir := filepath.Join(tempDir(t), "export")
+			var tl *timeline.Timeline
+
+			// When
+			test.InRun(test.Expect(!param.expectFailure), func(t test.Test) {
+				tl = timeline.New(t).ExportDir(dir)
+				mocks := mock.NewMock(t).Expect(mock.Setup(
+					tl.Collect(), param.setup))
+				logger := log.New(tl.Writer(), "", 0)
+
+				unit(mocks, logger, "a")
+				if param.call != nil {
+					param.call(t)
+				}
+			})(t)
+
+			// Then
+			events := tl.Events()
+			entries := make([]entry, 0, len(events))
+			for index, event := range events {
+				entries = append(entries, entry{event.Kind, event.Message})
+				assert.NotZero(t, event.Routine)
+				if index > 0 {
+					assert.False(t, event.Time.Before(events[index-1].Time))
+				}
+			}
+			assert.Equal(t, param.expectEvents, entries)
+
+			content, err := os.ReadFile(filepath.Join(dir,
+				strings.ReplaceAll(t.Name(), "/", "_")+".json"))
+			if param.expectExport {
+				require.NoError(t, err)
+				exported := []timeline.Event{}
+				require.NoError(t, json.Unmarshal(content, &exported))
+				assert.Equal(t, len(events), len(exported))
+				assert.Equal(t, events[0].Message, exported[0].Message)
+			} else {
+				assert.ErrorIs(t, err, os.ErrNotExist)
+			}
+		})
+}
+
+func TestTimelineString(t *testing.T) {
+	// Given
+	tl := timeline.New(t)
+
+	// When
+	tl.Add(timeline.Mock, "call")
+	_, _ = tl.Writer().Write([]byte("first\r\n\nsecond\n"))
+
+	// Then
+	events := tl.Events()
+	require.Len(t, events, 3)
+	assert.Equal(t, events[0].String()+"\n"+events[1].String()+"\n"+
+		events[2].String()+"\n", tl.String())
+	assert.Contains(t, events[1].String(), " log     first")
+	assert.Equal(t, "second", events[2].Message)
+}
+
+func TestTimelineExportError(t *testing.T) {
+	test.Run(test.Success, func(t test.Test) {
+		// Given
+		file := filepath.Join(tempDir(t), "file")
+		require.NoError(t, os.WriteFile(file, nil, 0o600))
+
+		// When
+		test.InRun(test.Failure, func(t test.Test) {
+			mock.NewMock(t).Expect(mock.Chain(
+				test.Errorf("failure"),
+				test.Errorf("%v", timeline.ErrExport(file, &os.PathError{
+					Op: "mkdir", Path: file, Err: syscall.ENOTDIR,
+				})),
+				test.Errorf("Expected test to succeed but it failed: %s",
+					t.Name()),
+			))
+			test.InRun(test.Success, func(t test.Test) {
+				timeline.New(t).ExportDir(file)
+
+				t.Errorf("failure")
+			})(t)
+		})(t)
+	})(t)
+}
+
+// tempDir creates a temporary directory that is removed after the test.
+func tempDir(t test.Test) string {
+	dir, err := os.MkdirTemp("", "timeline-test-")
+	require.NoError(t, err)
+	t.(*test.Tester).Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}